@@ -12,6 +12,10 @@ import (
 	"github.com/go-kit/kit/log/level"
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/analytics"
+	"github.com/qtumproject/janus/pkg/blockhash"
+	"github.com/qtumproject/janus/pkg/ethtx"
+	"github.com/qtumproject/janus/pkg/gasprice"
+	"github.com/qtumproject/janus/pkg/logindex"
 	"github.com/qtumproject/janus/pkg/notifier"
 	"github.com/qtumproject/janus/pkg/params"
 	"github.com/qtumproject/janus/pkg/qtum"
@@ -34,6 +38,13 @@ var (
 	httpsCert           = app.Flag("https-cert", "https certificate").Default("").String()
 	logFile             = app.Flag("log-file", "write logs to a file").Envar("LOG_FILE").Default("").String()
 	matureBlockHeight   = app.Flag("mature-block-height-override", "override how old a coinbase/coinstake needs to be to be considered mature enough for spending (QTUM uses 2000 blocks after the 32s block fork) - if this value is incorrect transactions can be rejected").Int()
+	coinSelection       = app.Flag("coin-selection", "how UTXOs are picked for transactions signed by Janus, unless a request asks for another strategy with 'coinSelection'").Envar("COIN_SELECTION").Default(qtum.DefaultCoinSelection).Enum(qtum.CoinSelectionMatureFirst, qtum.CoinSelectionLargestFirst, qtum.CoinSelectionBranchAndBound)
+	indexDir            = app.Flag("index-dir", "directory to keep a local index of EVM logs in, used to answer eth_getLogs without qtumd's searchlogs").Envar("INDEX_DIR").Default("").String()
+	blockHashStoreDir   = app.Flag("blockhash-store", "directory to keep an embedded store of Ethereum block hashes in, used instead of the Postgres database to translate them to Qtum block hashes").Envar("BLOCKHASH_STORE").Default("").String()
+	ethTxStoreDir       = app.Flag("eth-tx-store", "directory to keep a record of the signed Ethereum transactions accepted by eth_sendRawTransaction in, so that they can be looked up by their Ethereum hash").Envar("ETH_TX_STORE").Default("").String()
+	ethBlockHashes      = app.Flag("eth-block-hashes", "return the hashes of the equivalent Ethereum headers, kept in the --blockhash-store, as every block hash and accept them as parameters").Envar("ETH_BLOCK_HASHES").Default("false").Bool()
+	gasPriceBlocks      = app.Flag("gas-price-blocks", "number of latest blocks whose EVM transaction gas prices, along with the mempool's, eth_gasPrice suggests a price from (0 always returns the minimum gas price)").Envar("GAS_PRICE_BLOCKS").Default(fmt.Sprint(gasprice.DefaultBlocks)).Int()
+	gasPricePercentile  = app.Flag("gas-price-percentile", "percentile of the sampled gas prices eth_gasPrice suggests, never less than the minimum gas price").Envar("GAS_PRICE_PERCENTILE").Default(fmt.Sprint(gasprice.DefaultPercentile)).Int()
 	healthCheckPercent  = app.Flag("health-check-healthy-request-amount", "configure the minimum request success rate for healthcheck").Envar("HEALTH_CHECK_REQUEST_PERCENT").Default("80").Int()
 
 	sqlHost     = app.Flag("sql-host", "database hostname").Envar("SQL_HOST").Default("127.0.0.1").String()
@@ -135,6 +146,7 @@ func action(pc *kingpin.ParseContext) error {
 		qtum.SetDisableSnippingQtumRpcOutput(*disableSnipping),
 		qtum.SetHideQtumdLogs(*hideQtumdLogs),
 		qtum.SetMatureBlockHeight(matureBlockHeight),
+		qtum.SetCoinSelection(*coinSelection),
 		qtum.SetContext(ctx),
 		qtum.SetSqlHost(*sqlHost),
 		qtum.SetSqlPort(*sqlPort),
@@ -154,13 +166,62 @@ func action(pc *kingpin.ParseContext) error {
 		return errors.Wrap(err, "Failed to setup QTUM chain")
 	}
 
+	var logIndex *logindex.Index
+	if *indexDir != "" {
+		logIndex, err = logindex.New(ctx, qtumClient, *indexDir)
+		if err != nil {
+			return errors.Wrap(err, "Failed to open log index")
+		}
+		logIndex.Start()
+	}
+
+	if *ethBlockHashes && *blockHashStoreDir == "" {
+		return errors.New("--eth-block-hashes requires --blockhash-store")
+	}
+
+	var blockHashStore *blockhash.Store
+	if *blockHashStoreDir != "" {
+		blockHashStore, err = blockhash.NewStore(ctx, transformer.NewBlockHashSource(qtumClient), *blockHashStoreDir, qtumClient.GetDebugLogger())
+		if err != nil {
+			return errors.Wrap(err, "Failed to open block hash store")
+		}
+		blockHashStore.Start()
+	}
+
+	var ethTransactions *ethtx.Store
+	if *ethTxStoreDir != "" {
+		ethTransactions, err = ethtx.NewStore(*ethTxStoreDir)
+		if err != nil {
+			return errors.Wrap(err, "Failed to open Ethereum transaction store")
+		}
+		defer ethTransactions.Close()
+	}
+
+	var gasPriceOracle *gasprice.Oracle
+	if *gasPriceBlocks > 0 {
+		gasPriceOracle, err = gasprice.New(qtumClient, *gasPriceBlocks, *gasPricePercentile)
+		if err != nil {
+			return errors.Wrap(err, "Failed to setup gas price oracle")
+		}
+	}
+
 	agent := notifier.NewAgent(context.Background(), qtumClient, nil)
-	proxies := transformer.DefaultProxies(qtumClient, agent)
+	proxies := transformer.DefaultProxies(qtumClient, agent, logIndex, gasPriceOracle, ethTransactions)
+	transformerOptions := []transformer.Option{
+		transformer.SetDebug(*devMode),
+		transformer.SetLogger(logger),
+	}
+	if ethTransactions != nil {
+		transformerOptions = append(transformerOptions, transformer.SetEthereumTransactions(ethTransactions))
+	}
+	if *ethBlockHashes {
+		agent.SetEthereumBlockHashes(blockHashStore)
+		transformerOptions = append(transformerOptions, transformer.SetEthereumBlockHashes(blockHashStore))
+	}
 	t, err := transformer.New(
 		qtumClient,
 		proxies,
-		transformer.SetDebug(*devMode),
-		transformer.SetLogger(logger),
+		transformerOptions...,
 	)
 	if err != nil {
 		return errors.Wrap(err, "transformer#New")
@@ -181,6 +242,7 @@ func action(pc *kingpin.ParseContext) error {
 		server.SetHttps(httpsKeyFile, httpsCertFile),
 		server.SetQtumAnalytics(qtumRequestAnalytics),
 		server.SetHealthCheckPercent(healthCheckPercent),
+		server.SetBlockHashStore(blockHashStore),
 	)
 	if err != nil {
 		return errors.Wrap(err, "server#New")
@@ -18,6 +18,7 @@ require (
 	github.com/qtumproject/ethereum-block-processor v0.0.1
 	github.com/shopspring/decimal v1.3.1
 	github.com/stretchr/testify v1.7.0
+	go.etcd.io/bbolt v1.3.6
 	gopkg.in/alecthomas/kingpin.v2 v2.2.6
 )
 
@@ -10,6 +10,7 @@ import (
 	"sync"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/go-kit/log"
 	"github.com/qtumproject/ethereum-block-processor/cache"
 	"github.com/qtumproject/ethereum-block-processor/db"
@@ -26,6 +27,7 @@ type BlockHash struct {
 	mutex sync.RWMutex
 
 	qtumDB    *db.QtumDB
+	store     *Store
 	getLogger func() log.Logger
 
 	chainId      int
@@ -61,6 +63,36 @@ func NewBlockHash(ctx context.Context, getLogger func() log.Logger) (*BlockHash,
 	}, nil
 }
 
+// SetStore makes hashes be looked up in an embedded store instead of the Postgres database
+func (bh *BlockHash) SetStore(store *Store) {
+	bh.mutex.Lock()
+	bh.store = store
+	bh.mutex.Unlock()
+}
+
+func (bh *BlockHash) HasStore() bool {
+	bh.mutex.RLock()
+	defer bh.mutex.RUnlock()
+	return bh.store != nil
+}
+
+// GetEthereumBlockHash returns the Ethereum hash of a Qtum block, only the embedded store maps hashes in this direction
+func (bh *BlockHash) GetEthereumBlockHash(qtumBlockHash string) (*string, error) {
+	bh.mutex.RLock()
+	store := bh.store
+	bh.mutex.RUnlock()
+	if store == nil {
+		return nil, ErrDatabaseNotConfigured
+	}
+
+	ethereumBlockHash, ok := store.EthereumHash(common.HexToHash(qtumBlockHash))
+	if !ok {
+		return nil, nil
+	}
+	hash := ethereumBlockHash.Hex()
+	return &hash, nil
+}
+
 func (bh *BlockHash) GetQtumBlockHash(ethereumBlockHash string) (*string, error) {
 	return bh.GetQtumBlockHashContext(nil, ethereumBlockHash)
 }
@@ -69,7 +101,16 @@ func (bh *BlockHash) GetQtumBlockHashContext(ctx context.Context, ethereumBlockH
 	var qtumBlockHash string
 	bh.mutex.RLock()
 	qtumDB := bh.qtumDB
+	store := bh.store
 	bh.mutex.RUnlock()
+	if store != nil {
+		hash, ok := store.QtumHash(common.HexToHash(ethereumBlockHash))
+		if !ok {
+			return nil, nil
+		}
+		qtumBlockHash = hash.Hex()
+		return &qtumBlockHash, nil
+	}
 	if qtumDB == nil {
 		return &qtumBlockHash, ErrDatabaseNotConfigured
 	}
@@ -0,0 +1,84 @@
+package blockhash
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/eth"
+)
+
+// HeaderGasLimit is the gasLimit of every hashed header, QTUM's default block gas limit.
+// qtumd can't tell the gas limit a past block was mined with, and the current one changes with the governance contracts,
+// so a constant keeps the hash of a block the same for every store, whenever it hashed the block
+const HeaderGasLimit uint64 = 40000000
+
+// HeaderHash returns the hash of the Ethereum header equivalent to a block as Janus returns it, which is the keccak256 hash of its RLP encoding.
+// parentHash is the Ethereum hash of the block's parent, so that the hashes of consecutive blocks chain like they do on Ethereum
+func HeaderHash(block *eth.GetBlockByHashResponse, parentHash common.Hash) (common.Hash, error) {
+	header, err := ethereumHeader(block)
+	if err != nil {
+		return common.Hash{}, err
+	}
+	header.ParentHash = parentHash
+	return header.Hash(), nil
+}
+
+func ethereumHeader(block *eth.GetBlockByHashResponse) (*types.Header, error) {
+	number, err := hexutil.DecodeBig(block.Number)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid number")
+	}
+	difficulty, err := hexutil.DecodeBig(block.Difficulty)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid difficulty")
+	}
+	gasLimit, err := hexutil.DecodeUint64(block.GasLimit)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid gasLimit")
+	}
+	gasUsed, err := hexutil.DecodeUint64(block.GasUsed)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid gasUsed")
+	}
+	timestamp, err := hexutil.DecodeUint64(block.Timestamp)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid timestamp")
+	}
+	extra, err := hexutil.Decode(block.ExtraData)
+	if err != nil {
+		return nil, errors.Wrap(err, "invalid extraData")
+	}
+	bloom, err := hexutil.Decode(block.LogsBloom)
+	if err != nil || len(bloom) != types.BloomByteLength {
+		return nil, errors.Errorf("invalid logsBloom %s", block.LogsBloom)
+	}
+	nonce, err := hexutil.Decode(block.Nonce)
+	if err != nil || len(nonce) != len(types.BlockNonce{}) {
+		return nil, errors.Errorf("invalid nonce %s", block.Nonce)
+	}
+
+	header := &types.Header{
+		UncleHash:   common.HexToHash(block.Sha3Uncles),
+		Coinbase:    common.HexToAddress(block.Miner),
+		Root:        common.HexToHash(block.StateRoot),
+		TxHash:      common.HexToHash(block.TransactionsRoot),
+		ReceiptHash: common.HexToHash(block.ReceiptsRoot),
+		Bloom:       types.BytesToBloom(bloom),
+		Difficulty:  difficulty,
+		Number:      number,
+		GasLimit:    gasLimit,
+		GasUsed:     gasUsed,
+		Time:        timestamp,
+		Extra:       extra,
+	}
+	copy(header.Nonce[:], nonce)
+	if block.BaseFeePerGas != "" {
+		// London headers end with the base fee
+		header.BaseFee, err = hexutil.DecodeBig(block.BaseFeePerGas)
+		if err != nil {
+			return nil, errors.Wrap(err, "invalid baseFeePerGas")
+		}
+	}
+	return header, nil
+}
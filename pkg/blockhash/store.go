@@ -0,0 +1,421 @@
+package blockhash
+
+import (
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"os"
+	"path/filepath"
+	"sync"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/go-kit/log"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/eth"
+	bolt "go.etcd.io/bbolt"
+)
+
+const (
+	// number of blocks hashed before they are written to disk while catching up
+	batchSize = 100
+	// how often to poll qtumd for new blocks once caught up
+	followInterval = 5 * time.Second
+	// how far back to look for the fork of a reorg before hashing every block again, qtumd doesn't reorganize deeper than 500 blocks
+	maxReorgDepth = 1000
+
+	dbFileName = "blockhashes.db"
+)
+
+var (
+	// "head" => highest stored block, missing until the first batch is stored
+	metaBucket = []byte("meta")
+	// block height => json encoded storedBlock
+	blocksBucket = []byte("blocks")
+	// Ethereum hash => block height
+	ethereumBucket = []byte("ethereum")
+	// Qtum hash => block height
+	qtumBucket = []byte("qtum")
+
+	headKey = []byte("head")
+
+	buckets = [][]byte{metaBucket, blocksBucket, ethereumBucket, qtumBucket}
+)
+
+// BlockSource provides the blocks the store hashes
+type BlockSource interface {
+	// Tip returns the height of the highest block of the chain
+	Tip(ctx context.Context) (uint64, error)
+	// QtumHash returns the Qtum hash of the block at a height
+	QtumHash(ctx context.Context, height uint64) (common.Hash, error)
+	// Block returns the header of the block at a height as eth_getBlockByNumber does, with Qtum block hashes.
+	// Its gas limit is replaced with HeaderGasLimit.
+	// It fails rather than falling back on a default for any field it can't get, so that a block is always hashed the same way
+	Block(ctx context.Context, height uint64) (*eth.GetBlockByHashResponse, error)
+}
+
+// storedBlock is what the store keeps of a hashed block: both its hashes,
+// and the header fields Janus may not serve the same way later, as they were hashed
+type storedBlock struct {
+	Qtum      common.Hash `json:"qtum"`
+	Ethereum  common.Hash `json:"eth"`
+	Miner     string      `json:"miner"`
+	GasUsed   string      `json:"gasUsed"`
+	LogsBloom string      `json:"logsBloom,omitempty"`
+}
+
+// Store is an embedded, file backed mapping between Qtum block hashes and the hashes of the equivalent Ethereum headers.
+//
+// It follows the chain tip and hashes every new block with HeaderHash, chaining each block to the Ethereum hash of its parent.
+// Blocks are kept in a BoltDB file keyed by height and by either hash, so lookups only read the block they need and nothing is loaded in memory on start.
+// Reorgs are detected by comparing the highest stored block with the node, the orphaned blocks are dropped and hashed again
+type Store struct {
+	ctx    context.Context
+	source BlockSource
+	logger log.Logger
+	db     *bolt.DB
+
+	// held while hashing blocks, so that requests catching up with the chain don't hash the same blocks as follow
+	syncMutex sync.Mutex
+
+	mutex sync.RWMutex
+	// set once the store caught up with the node
+	synced bool
+}
+
+func NewStore(ctx context.Context, source BlockSource, dir string, logger log.Logger) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "couldn't create block hash store directory")
+	}
+	db, err := bolt.Open(filepath.Join(dir, dbFileName), 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open block hash store")
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "couldn't initialize block hash store")
+	}
+
+	return &Store{
+		ctx:    ctx,
+		source: source,
+		logger: logger,
+		db:     db,
+	}, nil
+}
+
+// Start follows the chain in the background until the context is done
+func (s *Store) Start() {
+	go s.follow()
+}
+
+// Head returns the highest stored block
+func (s *Store) Head() (head uint64, stored bool) {
+	s.db.View(func(tx *bolt.Tx) error {
+		head, stored = readHead(tx)
+		return nil
+	})
+	return
+}
+
+// QtumHash returns the Qtum hash of the block with the passed in Ethereum hash
+func (s *Store) QtumHash(ethereumHash common.Hash) (common.Hash, bool) {
+	block, ok := s.lookup(ethereumBucket, ethereumHash)
+	if !ok {
+		return common.Hash{}, false
+	}
+	return block.Qtum, true
+}
+
+// EthereumHash returns the Ethereum hash of the block with the passed in Qtum hash
+func (s *Store) EthereumHash(qtumHash common.Hash) (common.Hash, bool) {
+	block, ok := s.block(qtumHash)
+	if !ok {
+		return common.Hash{}, false
+	}
+	return block.Ethereum, true
+}
+
+// block returns the stored block with the passed in Qtum hash
+func (s *Store) block(qtumHash common.Hash) (*storedBlock, bool) {
+	return s.lookup(qtumBucket, qtumHash)
+}
+
+// lookup returns the block indexed under hash in one of the hash buckets
+func (s *Store) lookup(bucket []byte, hash common.Hash) (block *storedBlock, ok bool) {
+	s.db.View(func(tx *bolt.Tx) error {
+		height := tx.Bucket(bucket).Get(hash.Bytes())
+		if height == nil {
+			return nil
+		}
+		block, ok = readBlock(tx, binary.BigEndian.Uint64(height))
+		return nil
+	})
+	return
+}
+
+// Sync hashes blocks until the store has caught up with the node
+func (s *Store) Sync(ctx context.Context) error {
+	s.syncMutex.Lock()
+	defer s.syncMutex.Unlock()
+	for {
+		caughtUp, err := s.sync(ctx)
+		if err != nil {
+			return err
+		}
+		if caughtUp {
+			return nil
+		}
+	}
+}
+
+// catchUp hashes the blocks mined since the store last synced, it does nothing while the store is still hashing the bulk of the chain
+func (s *Store) catchUp(ctx context.Context) error {
+	s.mutex.RLock()
+	synced := s.synced
+	s.mutex.RUnlock()
+	if !synced {
+		return nil
+	}
+
+	s.syncMutex.Lock()
+	defer s.syncMutex.Unlock()
+	_, err := s.sync(ctx)
+	return err
+}
+
+func (s *Store) follow() {
+	for {
+		s.syncMutex.Lock()
+		caughtUp, err := s.sync(s.ctx)
+		s.syncMutex.Unlock()
+		if err != nil {
+			s.logger.Log("component", "blockhash", "msg", "failed to hash blocks", "err", err)
+		}
+
+		wait := time.Duration(0)
+		if err != nil || caughtUp {
+			wait = followInterval
+		}
+		select {
+		case <-s.ctx.Done():
+			s.close()
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (s *Store) close() error {
+	return s.db.Close()
+}
+
+// sync hashes at most one batch of blocks, returning true once the store has caught up with the node, must be called with syncMutex held
+func (s *Store) sync(ctx context.Context) (bool, error) {
+	if err := s.handleReorg(ctx); err != nil {
+		return false, errors.WithMessage(err, "couldn't check for reorgs")
+	}
+
+	tip, err := s.source.Tip(ctx)
+	if err != nil {
+		return false, err
+	}
+
+	var from uint64
+	parent := &storedBlock{}
+	if head, stored := s.Head(); stored {
+		from = head + 1
+		s.db.View(func(tx *bolt.Tx) error {
+			parent, _ = readBlock(tx, head)
+			return nil
+		})
+		if parent == nil {
+			return false, errors.Errorf("block %d is missing from the store", head)
+		}
+	}
+
+	if from > tip {
+		s.setSynced()
+		return true, nil
+	}
+	to := from + batchSize - 1
+	if to > tip {
+		to = tip
+	}
+
+	blocks := make([]*storedBlock, 0, to-from+1)
+	for height := from; height <= to; height++ {
+		block, err := s.source.Block(ctx, height)
+		if err != nil {
+			return false, errors.WithMessagef(err, "couldn't get block %d", height)
+		}
+		if height != 0 && common.HexToHash(block.ParentHash) != parent.Qtum {
+			// the chain was reorganized while hashing, it's handled on the next sync
+			return false, nil
+		}
+		block.GasLimit = hexutil.EncodeUint64(HeaderGasLimit)
+		ethereumHash, err := HeaderHash(block, parent.Ethereum)
+		if err != nil {
+			return false, errors.WithMessagef(err, "couldn't hash block %d", height)
+		}
+		parent = &storedBlock{
+			Qtum:     common.HexToHash(block.Hash),
+			Ethereum: ethereumHash,
+			Miner:    block.Miner,
+			GasUsed:  block.GasUsed,
+		}
+		if block.LogsBloom != eth.EmptyLogsBloom {
+			parent.LogsBloom = block.LogsBloom
+		}
+		blocks = append(blocks, parent)
+	}
+
+	if err := s.db.Update(func(tx *bolt.Tx) error {
+		return writeBlocks(tx, from, blocks)
+	}); err != nil {
+		return false, err
+	}
+	if to == tip {
+		s.setSynced()
+	}
+	return to == tip, nil
+}
+
+func (s *Store) setSynced() {
+	s.mutex.Lock()
+	s.synced = true
+	s.mutex.Unlock()
+}
+
+// handleReorg compares the stored block hashes with the node, from the highest down,
+// and drops everything above the highest block that is still part of the chain
+func (s *Store) handleReorg(ctx context.Context) error {
+	head, ok := s.Head()
+	if !ok {
+		return nil
+	}
+	tip, err := s.source.Tip(ctx)
+	if err != nil {
+		return err
+	}
+	// the blocks above the tip were orphaned by a reorg to a shorter chain
+	start := head
+	if tip < start {
+		start = tip
+	}
+
+	for height := int64(start); height >= 0 && head-uint64(height) <= maxReorgDepth; height-- {
+		hash, err := s.source.QtumHash(ctx, uint64(height))
+		if err != nil {
+			return err
+		}
+
+		var stored *storedBlock
+		s.db.View(func(tx *bolt.Tx) error {
+			stored, _ = readBlock(tx, uint64(height))
+			return nil
+		})
+		if stored != nil && stored.Qtum == hash {
+			if uint64(height) == head {
+				return nil
+			}
+			s.logger.Log("component", "blockhash", "msg", "reorg detected", "forkHeight", height)
+			return s.db.Update(func(tx *bolt.Tx) error {
+				return revert(tx, uint64(height))
+			})
+		}
+	}
+
+	s.logger.Log("component", "blockhash", "msg", "couldn't find where the chain forked, hashing every block again")
+	return s.db.Update(reset)
+}
+
+// writeBlocks stores the blocks from height from onwards, in order
+func writeBlocks(tx *bolt.Tx, from uint64, blocks []*storedBlock) error {
+	for i, block := range blocks {
+		height := heightKey(from + uint64(i))
+		value, err := json.Marshal(block)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(blocksBucket).Put(height, value); err != nil {
+			return err
+		}
+		if err := tx.Bucket(ethereumBucket).Put(block.Ethereum.Bytes(), height); err != nil {
+			return err
+		}
+		if err := tx.Bucket(qtumBucket).Put(block.Qtum.Bytes(), height); err != nil {
+			return err
+		}
+	}
+	return tx.Bucket(metaBucket).Put(headKey, heightKey(from+uint64(len(blocks))-1))
+}
+
+// revert drops every block above height
+func revert(tx *bolt.Tx, height uint64) error {
+	cursor := tx.Bucket(blocksBucket).Cursor()
+	for k, v := cursor.Seek(heightKey(height + 1)); k != nil; k, v = cursor.Seek(heightKey(height + 1)) {
+		var block storedBlock
+		if err := json.Unmarshal(v, &block); err != nil {
+			return errors.Wrap(err, "couldn't decode block")
+		}
+		if err := tx.Bucket(ethereumBucket).Delete(block.Ethereum.Bytes()); err != nil {
+			return err
+		}
+		if err := tx.Bucket(qtumBucket).Delete(block.Qtum.Bytes()); err != nil {
+			return err
+		}
+		if err := cursor.Delete(); err != nil {
+			return err
+		}
+	}
+	return tx.Bucket(metaBucket).Put(headKey, heightKey(height))
+}
+
+// reset drops everything
+func reset(tx *bolt.Tx) error {
+	for _, bucket := range buckets {
+		if err := tx.DeleteBucket(bucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(bucket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readHead(tx *bolt.Tx) (uint64, bool) {
+	head := tx.Bucket(metaBucket).Get(headKey)
+	if head == nil {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(head), true
+}
+
+func readBlock(tx *bolt.Tx, height uint64) (*storedBlock, bool) {
+	value := tx.Bucket(blocksBucket).Get(heightKey(height))
+	if value == nil {
+		return nil, false
+	}
+	var block storedBlock
+	if err := json.Unmarshal(value, &block); err != nil {
+		return nil, false
+	}
+	return &block, true
+}
+
+func heightKey(height uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, height)
+	return key
+}
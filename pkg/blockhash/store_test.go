@@ -0,0 +1,299 @@
+package blockhash
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/log"
+	"github.com/qtumproject/janus/pkg/eth"
+)
+
+// testChain is a BlockSource serving blocks from memory
+type testChain struct {
+	blocks []*eth.GetBlockByHashResponse
+	// heights of the blocks whose receipts can't be fetched
+	failing map[uint64]bool
+}
+
+func (c *testChain) Tip(ctx context.Context) (uint64, error) {
+	return uint64(len(c.blocks) - 1), nil
+}
+
+func (c *testChain) QtumHash(ctx context.Context, height uint64) (common.Hash, error) {
+	if height >= uint64(len(c.blocks)) {
+		return common.Hash{}, fmt.Errorf("block %d not found", height)
+	}
+	return common.HexToHash(c.blocks[height].Hash), nil
+}
+
+func (c *testChain) Block(ctx context.Context, height uint64) (*eth.GetBlockByHashResponse, error) {
+	if height >= uint64(len(c.blocks)) {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	if c.failing[height] {
+		return nil, fmt.Errorf("couldn't get receipts of block %d", height)
+	}
+	block := *c.blocks[height]
+	return &block, nil
+}
+
+// extend appends count blocks to the chain, fork makes their hashes differ from the blocks of other forks at the same heights
+func (c *testChain) extend(count int, fork byte) {
+	for i := 0; i < count; i++ {
+		height := uint64(len(c.blocks))
+		parentHash := common.Hash{}
+		if height != 0 {
+			parentHash = common.HexToHash(c.blocks[height-1].Hash)
+		}
+		c.blocks = append(c.blocks, testBlock(height, fork, parentHash))
+	}
+}
+
+func testBlock(height uint64, fork byte, parentHash common.Hash) *eth.GetBlockByHashResponse {
+	hash := common.Hash{}
+	hash[0] = fork
+	copy(hash[24:], common.BigToHash(new(big.Int).SetUint64(height + 1)).Bytes()[24:])
+	return &eth.GetBlockByHashResponse{
+		Number:           hexutil.EncodeUint64(height),
+		Hash:             hash.Hex(),
+		ParentHash:       parentHash.Hex(),
+		Nonce:            "0x0000000000000000",
+		Miner:            "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
+		LogsBloom:        eth.EmptyLogsBloom,
+		Timestamp:        hexutil.EncodeUint64(1536551888 + height*32 + uint64(fork)),
+		ExtraData:        "0x0000000000000000000000000000000000000000000000000000000000000000",
+		StateRoot:        "0x3e49216e58f1ad9e6823b5095dc532f0a6cc44943d36ff4a7b1aa474e172d672",
+		TransactionsRoot: "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
+		ReceiptsRoot:     "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
+		Difficulty:       "0x4",
+		GasUsed:          "0xc738",
+		Sha3Uncles:       eth.DefaultSha3Uncles,
+	}
+}
+
+func newTestStore(t *testing.T, chain *testChain, dir string) *Store {
+	store, err := NewStore(context.Background(), chain, dir, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func syncStore(t *testing.T, store *Store) {
+	for i := 0; i < 100; i++ {
+		caughtUp, err := store.sync(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if caughtUp {
+			return
+		}
+	}
+	t.Fatal("store didn't catch up with the chain")
+}
+
+// checkStore checks that every block of the chain is mapped both ways, each being hashed with its parent's Ethereum hash
+// and the constant header gas limit
+func checkStore(t *testing.T, store *Store, chain *testChain) {
+	head, ok := store.Head()
+	if !ok || head != uint64(len(chain.blocks)-1) {
+		t.Fatalf("Expected the store to be at block %d, got %d (%v)", len(chain.blocks)-1, head, ok)
+	}
+
+	parentHash := common.Hash{}
+	for height, block := range chain.blocks {
+		qtumHash := common.HexToHash(block.Hash)
+		if _, ok := store.block(qtumHash); !ok {
+			t.Fatalf("Block %d wasn't stored", height)
+		}
+		hashed := *block
+		hashed.GasLimit = hexutil.EncodeUint64(HeaderGasLimit)
+		want, err := HeaderHash(&hashed, parentHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+		got, ok := store.EthereumHash(qtumHash)
+		if !ok || got != want {
+			t.Errorf("Block %d: expected Ethereum hash %s, got %s (%v)", height, want.Hex(), got.Hex(), ok)
+		}
+		gotQtumHash, ok := store.QtumHash(want)
+		if !ok || gotQtumHash != qtumHash {
+			t.Errorf("Block %d: expected Qtum hash %s, got %s (%v)", height, qtumHash.Hex(), gotQtumHash.Hex(), ok)
+		}
+		parentHash = want
+	}
+}
+
+func TestHeaderHash(t *testing.T) {
+	parentHash := common.HexToHash("0x61cdb2a09ab99abf791d474f20c2ea89bf8de2923a2d42bb49944c8c993cbf04")
+
+	// blocks are hashed as London headers when they have a base fee
+	for _, baseFee := range []string{"", "0x9502f9000"} {
+		block := testBlock(3983, 0, common.HexToHash("0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be"))
+		block.GasLimit = "0x2625a00"
+		block.BaseFeePerGas = baseFee
+
+		got, err := HeaderHash(block, parentHash)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// a client rebuilding the header from the block Janus returns, with the Ethereum hash of its parent, gets the same hash
+		ethereumBlock := *block
+		ethereumBlock.ParentHash = parentHash.Hex()
+		raw, err := json.Marshal(ethereumBlock)
+		if err != nil {
+			t.Fatal(err)
+		}
+		var header types.Header
+		if err := json.Unmarshal(raw, &header); err != nil {
+			t.Fatal(err)
+		}
+		if want := header.Hash(); got != want {
+			t.Errorf("baseFeePerGas %q: expected %s, got %s", baseFee, want.Hex(), got.Hex())
+		}
+	}
+}
+
+func TestStoreFollowsChain(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blockhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chain := &testChain{}
+	chain.extend(batchSize+10, 0)
+	store := newTestStore(t, chain, dir)
+	defer store.close()
+	syncStore(t, store)
+	checkStore(t, store, chain)
+
+	chain.extend(3, 0)
+	syncStore(t, store)
+	checkStore(t, store, chain)
+}
+
+func TestStoreHashesDontDependOnTheGasLimit(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blockhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	otherDir, err := ioutil.TempDir("", "blockhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(otherDir)
+
+	chain := &testChain{}
+	chain.extend(10, 0)
+	store := newTestStore(t, chain, dir)
+	defer store.close()
+	syncStore(t, store)
+
+	// another store hashes the same blocks after the governance contracts changed the gas limit
+	// eth_getBlockByNumber serves, and gets the same hashes
+	changed := &testChain{}
+	for _, block := range chain.blocks {
+		block := *block
+		block.GasLimit = "0x2faf080"
+		changed.blocks = append(changed.blocks, &block)
+	}
+	other := newTestStore(t, changed, otherDir)
+	defer other.close()
+	syncStore(t, other)
+
+	for height, block := range chain.blocks {
+		want, _ := store.EthereumHash(common.HexToHash(block.Hash))
+		if got, ok := other.EthereumHash(common.HexToHash(block.Hash)); !ok || got != want {
+			t.Errorf("Block %d: expected Ethereum hash %s, got %s (%v)", height, want.Hex(), got.Hex(), ok)
+		}
+	}
+}
+
+func TestStoreDoesntHashIncompleteBlocks(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blockhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chain := &testChain{failing: map[uint64]bool{12: true}}
+	chain.extend(20, 0)
+	store := newTestStore(t, chain, dir)
+	defer store.close()
+	if err := store.Sync(context.Background()); err == nil {
+		t.Fatal("Expected an error hashing a block whose receipts can't be fetched")
+	}
+	if _, ok := store.EthereumHash(common.HexToHash(chain.blocks[12].Hash)); ok {
+		t.Error("Expected the block whose receipts can't be fetched not to be hashed")
+	}
+
+	// once the receipts can be fetched again, the block is hashed as usual
+	delete(chain.failing, 12)
+	syncStore(t, store)
+	checkStore(t, store, chain)
+}
+
+func TestStoreHandlesReorg(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blockhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chain := &testChain{}
+	chain.extend(20, 0)
+	store := newTestStore(t, chain, dir)
+	defer store.close()
+	syncStore(t, store)
+
+	orphaned := append([]*eth.GetBlockByHashResponse{}, chain.blocks[15:]...)
+	chain.blocks = chain.blocks[:15]
+	chain.extend(7, 1)
+	syncStore(t, store)
+	checkStore(t, store, chain)
+
+	for _, block := range orphaned {
+		if _, ok := store.EthereumHash(common.HexToHash(block.Hash)); ok {
+			t.Errorf("Expected orphaned block %s to be dropped", block.Hash)
+		}
+	}
+}
+
+func TestStoreReopens(t *testing.T) {
+	dir, err := ioutil.TempDir("", "blockhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	chain := &testChain{}
+	chain.extend(30, 0)
+	store := newTestStore(t, chain, dir)
+	syncStore(t, store)
+	chain.blocks = chain.blocks[:25]
+	chain.extend(2, 1)
+	syncStore(t, store)
+	store.close()
+
+	reopened := newTestStore(t, chain, dir)
+	defer reopened.close()
+	checkStore(t, reopened, chain)
+	caughtUp, err := reopened.sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !caughtUp {
+		t.Error("Expected the reopened store to be caught up")
+	}
+}
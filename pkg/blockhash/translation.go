@@ -0,0 +1,98 @@
+package blockhash
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/qtumproject/janus/pkg/eth"
+)
+
+// Translation translates the block hashes of a single request or response.
+//
+// Blocks mined since the store last synced are hashed on the first Qtum hash that isn't known,
+// so a response about a new block doesn't have to wait for the store to poll the node.
+// Hashes that still can't be translated, and anything that isn't a block hash, are returned unchanged
+type Translation struct {
+	ctx      context.Context
+	store    *Store
+	caughtUp bool
+}
+
+// Translate starts the translation of the block hashes of a request or response
+func (s *Store) Translate(ctx context.Context) *Translation {
+	return &Translation{
+		ctx:   ctx,
+		store: s,
+	}
+}
+
+// EthereumHash returns the Ethereum hash of the block with the passed in Qtum hash
+func (t *Translation) EthereumHash(qtumHash string) string {
+	block, ok := t.block(qtumHash)
+	if !ok {
+		return qtumHash
+	}
+	return block.Ethereum.Hex()
+}
+
+// Block translates the hashes of a block, and replaces the header fields that may be served differently than when it was hashed
+// with the stored ones, so that the Ethereum header rebuilt from the block hashes to its Ethereum hash
+func (t *Translation) Block(block *eth.GetBlockByHashResponse) {
+	if stored, ok := t.block(block.Hash); ok {
+		block.Hash = stored.Ethereum.Hex()
+		block.Miner = stored.Miner
+		block.GasLimit = hexutil.EncodeUint64(HeaderGasLimit)
+		block.GasUsed = stored.GasUsed
+		block.LogsBloom = stored.LogsBloom
+		if block.LogsBloom == "" {
+			block.LogsBloom = eth.EmptyLogsBloom
+		}
+	}
+	block.ParentHash = t.EthereumHash(block.ParentHash)
+}
+
+// block returns the stored block with the passed in Qtum hash, hashing the blocks mined since the store last synced if it isn't known
+func (t *Translation) block(qtumHash string) (*storedBlock, bool) {
+	hash, ok := parseHash(qtumHash)
+	if !ok {
+		return nil, false
+	}
+	block, ok := t.store.block(hash)
+	if !ok && !t.caughtUp {
+		t.caughtUp = true
+		if err := t.store.catchUp(t.ctx); err != nil {
+			t.store.logger.Log("component", "blockhash", "msg", "failed to hash new blocks", "err", err)
+		}
+		block, ok = t.store.block(hash)
+	}
+	return block, ok
+}
+
+// QtumHash returns the Qtum hash of the block with the passed in Ethereum hash
+func (t *Translation) QtumHash(ethereumHash string) string {
+	hash, ok := parseHash(ethereumHash)
+	if !ok {
+		return ethereumHash
+	}
+	qtumHash, ok := t.store.QtumHash(hash)
+	if !ok {
+		return ethereumHash
+	}
+	return qtumHash.Hex()
+}
+
+// parseHash parses a 32 byte hex string, with or without a 0x prefix
+func parseHash(hash string) (common.Hash, bool) {
+	if len(hash) >= 2 && (hash[:2] == "0x" || hash[:2] == "0X") {
+		hash = hash[2:]
+	}
+	if len(hash) != 2*common.HashLength {
+		return common.Hash{}, false
+	}
+	bytes, err := hexutil.Decode("0x" + hash)
+	if err != nil {
+		return common.Hash{}, false
+	}
+	return common.BytesToHash(bytes), true
+}
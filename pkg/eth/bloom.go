@@ -0,0 +1,20 @@
+package eth
+
+import (
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+// LogsBloom returns the 2048 bit bloom filter of the addresses and topics of logs,
+// which is the logsBloom of a receipt, or of a block when given the logs of all its receipts
+func LogsBloom(logs []Log) string {
+	var bloom types.Bloom
+	for _, log := range logs {
+		bloom.Add(common.FromHex(log.Address))
+		for _, topic := range log.Topics {
+			bloom.Add(common.FromHex(topic))
+		}
+	}
+	return hexutil.Encode(bloom.Bytes())
+}
@@ -0,0 +1,33 @@
+package eth
+
+import (
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+)
+
+func TestLogsBloom(t *testing.T) {
+	address := "0xdb46f738bf32cdafb9a4a70eb8b44c76646bcaf0"
+	topics := []string{
+		"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef",
+		"0x000000000000000000000000d3cd7c4b5fb4e2b6a0c3f4e4ae2f4a9f0e3a3e3c",
+	}
+
+	// same as the bloom of a go-ethereum receipt with the same log
+	receipt := &types.Receipt{Logs: []*types.Log{{
+		Address: common.HexToAddress(address),
+		Topics:  []common.Hash{common.HexToHash(topics[0]), common.HexToHash(topics[1])},
+	}}}
+	want := hexutil.Encode(types.CreateBloom(types.Receipts{receipt}).Bytes())
+
+	got := LogsBloom([]Log{{Address: address, Topics: topics}})
+	if got != want {
+		t.Errorf("error\nwant: %s\ngot: %s", want, got)
+	}
+
+	if got := LogsBloom(nil); got != EmptyLogsBloom {
+		t.Errorf("Expected an empty bloom without logs, got %s", got)
+	}
+}
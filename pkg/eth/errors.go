@@ -17,6 +17,12 @@ var InvalidParamsErrorCode = -32602
 // logic error
 var CallbackErrorCode = -32000
 
+// the request needs a feature qtumd doesn't have, EIP-1474's "method not supported"
+var UnsupportedErrorCode = -32004
+
+// execution reverted, with the revert data
+var ExecutionRevertedErrorCode = 3
+
 // shutdown error
 // "server is shutting down"
 var ShutdownErrorCode = -32000
@@ -42,6 +48,10 @@ func NewInvalidParamsError(message string) JSONRPCError {
 	return NewJSONRPCError(InvalidParamsErrorCode, message, nil)
 }
 
+func NewUnsupportedError(message string) JSONRPCError {
+	return NewJSONRPCError(UnsupportedErrorCode, message, nil)
+}
+
 func NewCallbackError(message string) JSONRPCError {
 	return NewJSONRPCError(CallbackErrorCode, message, nil)
 }
@@ -65,6 +75,8 @@ type GenericJSONRPCError struct {
 	code    int
 	message string
 	err     error
+	// additional information about the error, like the data a call reverted with
+	data interface{}
 }
 
 func (err *GenericJSONRPCError) Code() int {
@@ -79,12 +91,18 @@ func (err *GenericJSONRPCError) Error() error {
 	return err.err
 }
 
+func (err *GenericJSONRPCError) Data() interface{} {
+	return err.data
+}
+
 func (err *GenericJSONRPCError) MarshalJSON() ([]byte, error) {
 	return json.Marshal(struct {
-		Code    int    `json:"code"`
-		Message string `json:"message"`
+		Code    int         `json:"code"`
+		Message string      `json:"message"`
+		Data    interface{} `json:"data,omitempty"`
 	}{
 		Code:    err.code,
 		Message: err.message,
+		Data:    err.data,
 	})
 }
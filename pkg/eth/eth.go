@@ -2,9 +2,13 @@ package eth
 
 import (
 	"encoding/json"
+	"strings"
 )
 
 var EmptyLogsBloom = "0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000"
+
+// FullLogsBloom matches every address and topic, it's served when a block's logs are unknown so clients don't skip the block
+var FullLogsBloom = "0x" + strings.Repeat("f", 512)
 var DefaultSha3Uncles = "0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347"
 
 const (
@@ -0,0 +1,79 @@
+package eth
+
+import (
+	"bytes"
+	"fmt"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/accounts/abi"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/crypto"
+)
+
+var (
+	// selector of Error(string), which require and revert with a message revert with
+	revertSelector = crypto.Keccak256([]byte("Error(string)"))[:4]
+	// selector of Panic(uint256), which failed assertions and arithmetic errors revert with
+	panicSelector = crypto.Keccak256([]byte("Panic(uint256)"))[:4]
+
+	// see https://docs.soliditylang.org/en/latest/control-structures.html#panic-via-assert-and-error-via-require
+	panicReasons = map[uint64]string{
+		0x00: "generic panic",
+		0x01: "assert(false)",
+		0x11: "arithmetic underflow or overflow",
+		0x12: "division or modulo by zero",
+		0x21: "enum overflow",
+		0x22: "invalid encoded storage byte array accessed",
+		0x31: "out-of-bounds array access; popping on an empty array",
+		0x32: "out-of-bounds access of an array or bytesN",
+		0x41: "out of memory",
+		0x51: "uninitialized function",
+	}
+)
+
+// UnpackRevertReason decodes the reason of Error(string) and Panic(uint256) revert data.
+// Custom errors can't be decoded without the contract's ABI, ok is false for them
+func UnpackRevertReason(data []byte) (reason string, ok bool) {
+	switch {
+	case len(data) >= 4 && bytes.Equal(data[:4], revertSelector):
+		reason, err := abi.UnpackRevert(data)
+		if err != nil {
+			return "", false
+		}
+		return reason, true
+	case len(data) == 4+32 && bytes.Equal(data[:4], panicSelector):
+		code := new(big.Int).SetBytes(data[4:])
+		if code.IsUint64() {
+			if reason, ok := panicReasons[code.Uint64()]; ok {
+				return reason, true
+			}
+		}
+		return fmt.Sprintf("unknown panic code: %#x", code), true
+	}
+	return "", false
+}
+
+// PackRevertReason returns the Error(string) revert data of a reason
+func PackRevertReason(reason string) []byte {
+	stringType, _ := abi.NewType("string", "", nil)
+	packed, err := (abi.Arguments{{Type: stringType}}).Pack(reason)
+	if err != nil {
+		// strings always pack
+		panic(err)
+	}
+	return append(append([]byte{}, revertSelector...), packed...)
+}
+
+// NewRevertError returns the error geth returns when a call reverts,
+// the revert data is returned as is and the message has the reason it decodes to
+func NewRevertError(data []byte) JSONRPCError {
+	message := "execution reverted"
+	if reason, ok := UnpackRevertReason(data); ok {
+		message += ": " + reason
+	}
+	return &GenericJSONRPCError{
+		code:    ExecutionRevertedErrorCode,
+		message: message,
+		data:    hexutil.Encode(data),
+	}
+}
@@ -0,0 +1,46 @@
+package eth
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestUnpackRevertReason(t *testing.T) {
+	tests := []struct {
+		data   string
+		reason string
+		ok     bool
+	}{
+		// Error("Not enough Ether provided.")
+		{"0x08c379a00000000000000000000000000000000000000000000000000000000000000020000000000000000000000000000000000000000000000000000000000000001a4e6f7420656e6f7567682045746865722070726f76696465642e000000000000", "Not enough Ether provided.", true},
+		{"0x4e487b710000000000000000000000000000000000000000000000000000000000000001", "assert(false)", true},
+		{"0x4e487b7100000000000000000000000000000000000000000000000000000000000000ff", "unknown panic code: 0xff", true},
+		// custom errors need the contract's ABI
+		{"0xcf4791810000000000000000000000000000000000000000000000000000000000000064", "", false},
+		{"0x", "", false},
+	}
+	for _, test := range tests {
+		reason, ok := UnpackRevertReason(common.FromHex(test.data))
+		if reason != test.reason || ok != test.ok {
+			t.Errorf("%s: expected %q %v, got %q %v", test.data, test.reason, test.ok, reason, ok)
+		}
+	}
+
+	if reason, _ := UnpackRevertReason(PackRevertReason("Not enough Ether provided.")); reason != "Not enough Ether provided." {
+		t.Errorf("Expected packed reason to unpack, got %q", reason)
+	}
+}
+
+func TestRevertErrorSerialization(t *testing.T) {
+	data := "0x4e487b710000000000000000000000000000000000000000000000000000000000000012"
+	asJson, err := json.Marshal(NewRevertError(common.FromHex(data)))
+	if err != nil {
+		t.Fatal(err)
+	}
+	want := `{"code":3,"message":"execution reverted: division or modulo by zero","data":"` + data + `"}`
+	if string(asJson) != want {
+		t.Errorf(`"%s" != "%s"`, string(asJson), want)
+	}
+}
@@ -8,6 +8,8 @@ import (
 	"strings"
 
 	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/utils"
 	"github.com/shopspring/decimal"
@@ -30,6 +32,12 @@ type (
 		Value    string  `json:"value"`    // optional
 		Data     string  `json:"data"`     // optional
 		Nonce    string  `json:"nonce"`    // optional
+		// Janus specific, sends everything the sender can spend minus the fees instead of value
+		SendAll bool `json:"sendAll,omitempty"` // optional
+		// Janus specific, strategy picking the UTXOs to spend, see qtum.CoinSelectors
+		CoinSelection string `json:"coinSelection,omitempty"` // optional
+		// EIP-2930 access list, validated but not used since QTUM transactions can't carry one
+		AccessList types.AccessList `json:"accessList,omitempty"` // optional
 	}
 )
 
@@ -61,7 +69,7 @@ func (r *SendTransactionRequest) UnmarshalJSON(data []byte) error {
 // see: https://ethereum.stackexchange.com/questions/8384/transfer-an-amount-between-two-ethereum-accounts-using-json-rpc
 func (t *SendTransactionRequest) IsSendEther() bool {
 	// data must be empty
-	return t.Value != "" && t.To != "" && t.From != "" && t.Data == ""
+	return (t.Value != "" || t.SendAll) && t.To != "" && t.From != "" && t.Data == ""
 }
 
 func (t *SendTransactionRequest) IsCreateContract() bool {
@@ -107,6 +115,22 @@ type CallRequest struct {
 	GasPrice *ETHInt `json:"gasPrice"` // optional
 	Value    string  `json:"value"`    // optional
 	Data     string  `json:"data"`     // optional
+	// EIP-2930 access list, validated but not used since callcontract can't take one
+	AccessList types.AccessList `json:"accessList,omitempty"` // optional
+
+	// block tag, number or EIP-1898 object, the second parameter of the request
+	BlockNumber json.RawMessage `json:"-"` // optional
+	// geth's state override set, the third parameter of the request, qtumd can't run a call against overridden state
+	StateOverrides json.RawMessage `json:"-"` // optional
+}
+
+// HasStateOverrides returns whether the call overrides any state, missing, null and empty overrides override nothing
+func (t *CallRequest) HasStateOverrides() bool {
+	switch strings.TrimSpace(string(t.StateOverrides)) {
+	case "", "null", "{}":
+		return false
+	}
+	return true
 }
 
 func (t *CallRequest) GasHex() string {
@@ -141,10 +165,26 @@ func (t *CallRequest) UnmarshalJSON(data []byte) error {
 	}
 
 	cr := CallRequest(obj)
+	if len(params) > 1 {
+		cr.BlockNumber = params[1]
+	}
+	if len(params) > 2 {
+		cr.StateOverrides = params[2]
+	}
 	*t = cr
 	return nil
 }
 
+// ========== eth_createAccessList ============= //
+
+// CreateAccessListResponse eth_createAccessList
+type CreateAccessListResponse struct {
+	AccessList types.AccessList `json:"accessList"`
+	GasUsed    string           `json:"gasUsed"`
+	// set when the call fails, the access list and gas used are still returned like geth
+	Error string `json:"error,omitempty"`
+}
+
 type (
 	PersonalUnlockAccountResponse bool
 	BlockNumberResponse           string
@@ -408,6 +448,8 @@ type (
 		Logs            []Log  `json:"logs"`                      // Array - Array of log objects, which this transaction generated.
 		LogsBloom       string `json:"logsBloom"`                 // DATA, 256 Bytes - Bloom filter for light clients to quickly retrieve related logs.
 		Status          string `json:"status"`                    // QUANTITY either 1 (success) or 0 (failure)
+		// DATA - the Error(string) revert data of a reverted transaction, like Besu and Hardhat return
+		RevertReason string `json:"revertReason,omitempty"`
 
 		// TODO: researching
 		// ? Do we need this value
@@ -415,15 +457,15 @@ type (
 	}
 
 	Log struct {
-		Removed          string   `json:"removed,omitempty"` // TAG - true when the log was removed, due to a chain reorganization. false if its a valid log.
-		LogIndex         string   `json:"logIndex"`          // QUANTITY - integer of the log index position in the block. null when its pending log.
-		TransactionIndex string   `json:"transactionIndex"`  // QUANTITY - integer of the transactions index position log was created from. null when its pending log.
-		TransactionHash  string   `json:"transactionHash"`   // DATA, 32 Bytes - hash of the transactions this log was created from. null when its pending log.
-		BlockHash        string   `json:"blockHash"`         // DATA, 32 Bytes - hash of the block where this log was in. null when its pending. null when its pending log.
-		BlockNumber      string   `json:"blockNumber"`       // QUANTITY - the block number where this log was in. null when its pending. null when its pending log.
-		Address          string   `json:"address"`           // DATA, 20 Bytes - address from which this log originated.
-		Data             string   `json:"data"`              // DATA - contains one or more 32 Bytes non-indexed arguments of the log.
-		Topics           []string `json:"topics"`            // Array of DATA - Array of 0 to 4 32 Bytes DATA of indexed log arguments.
+		Removed          bool     `json:"removed"`          // TAG - true when the log was removed, due to a chain reorganization. false if its a valid log.
+		LogIndex         string   `json:"logIndex"`         // QUANTITY - integer of the log index position in the block. null when its pending log.
+		TransactionIndex string   `json:"transactionIndex"` // QUANTITY - integer of the transactions index position log was created from. null when its pending log.
+		TransactionHash  string   `json:"transactionHash"`  // DATA, 32 Bytes - hash of the transactions this log was created from. null when its pending log.
+		BlockHash        string   `json:"blockHash"`        // DATA, 32 Bytes - hash of the block where this log was in. null when its pending. null when its pending log.
+		BlockNumber      string   `json:"blockNumber"`      // QUANTITY - the block number where this log was in. null when its pending. null when its pending log.
+		Address          string   `json:"address"`          // DATA, 20 Bytes - address from which this log originated.
+		Data             string   `json:"data"`             // DATA - contains one or more 32 Bytes non-indexed arguments of the log.
+		Topics           []string `json:"topics"`           // Array of DATA - Array of 0 to 4 32 Bytes DATA of indexed log arguments.
 		Type             string   `json:"type,omitempty"`
 	}
 )
@@ -443,6 +485,52 @@ func (r *GetTransactionReceiptRequest) UnmarshalJSON(data []byte) error {
 	return nil
 }
 
+// ========== eth_getBlockReceipts ============= //
+
+// GetBlockReceiptsRequest identifies a block by hash, or by number or tag, given directly or as an EIP-1898 object
+type GetBlockReceiptsRequest struct {
+	BlockNumber json.RawMessage
+	BlockHash   string
+}
+
+type GetBlockReceiptsResponse []*GetTransactionReceiptResponse
+
+func (r *GetBlockReceiptsRequest) UnmarshalJSON(data []byte) error {
+	var params []json.RawMessage
+	if err := json.Unmarshal(data, &params); err != nil {
+		return errors.Wrap(err, "json unmarshalling")
+	}
+	if paramsNum := len(params); paramsNum != 1 {
+		return errors.Errorf("invalid parameters number - %d/1", paramsNum)
+	}
+
+	var param string
+	if err := json.Unmarshal(params[0], &param); err != nil {
+		var blockNumberOrHash BlockNumberOrHash
+		if err := json.Unmarshal(params[0], &blockNumberOrHash); err != nil {
+			return errors.Wrap(err, "invalid block parameter")
+		}
+		if blockNumberOrHash.BlockHash != "" {
+			if blockNumberOrHash.BlockNumber != "" {
+				return errors.New("invalid block parameter: cannot specify both blockHash and blockNumber")
+			}
+			r.BlockHash = blockNumberOrHash.BlockHash
+			return nil
+		}
+		if blockNumberOrHash.BlockNumber == "" {
+			return errors.New("invalid block parameter: blockHash or blockNumber is expected")
+		}
+		param = blockNumberOrHash.BlockNumber
+	} else if len(strings.TrimPrefix(param, "0x")) == 64 {
+		// a block number is never 32 bytes long
+		r.BlockHash = param
+		return nil
+	}
+
+	r.BlockNumber = json.RawMessage(fmt.Sprintf("\"%s\"", param))
+	return nil
+}
+
 // ========== eth_accounts ============= //
 type AccountsResponse []string
 
@@ -450,14 +538,14 @@ type AccountsResponse []string
 type (
 	GetCodeRequest struct {
 		Address     string
-		BlockNumber string
+		BlockNumber json.RawMessage
 	}
 	// the code from the given address.
 	GetCodeResponse string
 )
 
 func (r *GetCodeRequest) UnmarshalJSON(data []byte) error {
-	var params []string
+	var params []json.RawMessage
 	err := json.Unmarshal(data, &params)
 	if err != nil {
 		return errors.Wrap(err, "json unmarshalling")
@@ -467,7 +555,9 @@ func (r *GetCodeRequest) UnmarshalJSON(data []byte) error {
 		return errors.New("params must be set")
 	}
 
-	r.Address = params[0]
+	if err := json.Unmarshal(params[0], &r.Address); err != nil {
+		return errors.Wrap(err, "couldn't unmarshal address")
+	}
 	if len(params) > 1 {
 		r.BlockNumber = params[1]
 	}
@@ -479,6 +569,10 @@ func (r *GetCodeRequest) UnmarshalJSON(data []byte) error {
 // a filter id
 type NewBlockFilterResponse string
 
+// ========== eth_newPendingTransactionFilter ============= //
+// a filter id
+type NewPendingTransactionFilterResponse string
+
 // ========== eth_uninstallFilter ============= //
 // the filter id
 type UninstallFilterRequest string
@@ -532,6 +626,78 @@ type EstimateGasResponse string
 
 type GasPriceResponse *ETHInt
 
+// ========== eth_maxPriorityFeePerGas ============= //
+
+type MaxPriorityFeePerGasResponse string
+
+// ========== eth_feeHistory ============= //
+
+type (
+	// FeeHistoryRequest eth_feeHistory, the block count can be a hex quantity or a number
+	FeeHistoryRequest struct {
+		BlockCount        uint64
+		NewestBlock       json.RawMessage
+		RewardPercentiles []float64
+	}
+
+	FeeHistoryResponse struct {
+		OldestBlock string `json:"oldestBlock"`
+		// base fee of each block of the range and of the next block
+		BaseFeePerGas []string   `json:"baseFeePerGas"`
+		GasUsedRatio  []float64  `json:"gasUsedRatio"`
+		Reward        [][]string `json:"reward,omitempty"`
+	}
+)
+
+func (r *FeeHistoryRequest) UnmarshalJSON(data []byte) error {
+	var params []json.RawMessage
+	if err := json.Unmarshal(data, &params); err != nil {
+		return errors.Wrap(err, "json unmarshalling")
+	}
+	if paramsNum := len(params); paramsNum < 2 || paramsNum > 3 {
+		return errors.Errorf("invalid parameters number - %d/2", paramsNum)
+	}
+
+	var blockCount interface{}
+	if err := json.Unmarshal(params[0], &blockCount); err != nil {
+		return errors.Wrap(err, "invalid block count")
+	}
+	switch blockCount := blockCount.(type) {
+	case string:
+		count, err := hexutil.DecodeUint64(blockCount)
+		if err != nil {
+			return errors.Wrap(err, "invalid block count")
+		}
+		r.BlockCount = count
+	case float64:
+		if blockCount < 0 || blockCount != float64(uint64(blockCount)) {
+			return errors.Errorf("invalid block count %v", blockCount)
+		}
+		r.BlockCount = uint64(blockCount)
+	default:
+		return newErrInvalidParameterType(1, blockCount, "")
+	}
+
+	r.NewestBlock = params[1]
+
+	r.RewardPercentiles = nil
+	if len(params) == 3 && string(params[2]) != "null" {
+		if err := json.Unmarshal(params[2], &r.RewardPercentiles); err != nil {
+			return errors.Wrap(err, "invalid reward percentiles")
+		}
+		for i, percentile := range r.RewardPercentiles {
+			if percentile < 0 || percentile > 100 {
+				return errors.Errorf("invalid reward percentile %v", percentile)
+			}
+			if i > 0 && percentile < r.RewardPercentiles[i-1] {
+				return errors.Errorf("reward percentiles must be in ascending order")
+			}
+		}
+	}
+
+	return nil
+}
+
 // ========== eth_getBlockByNumber ============= //
 
 type (
@@ -618,6 +784,8 @@ type (
 		// Represents sha3 hash value based on uncles slice
 		Sha3Uncles string   `json:"sha3Uncles"`
 		Uncles     []string `json:"uncles"`
+		// EIP-1559 base fee, Qtum's minimum gas price
+		BaseFeePerGas string `json:"baseFeePerGas,omitempty"`
 	}
 )
 
@@ -732,11 +900,49 @@ type (
 		StateRoot        string `json:"stateRoot"`
 		Timestamp        string `json:"timestamp"`
 		TransactionsRoot string `json:"transactionsRoot"`
-		MixHash          string `json:"mixHash"` //! added for go-ethereum client support
-		// BaseFeePerGas    string `json:"baseFeePerGas"` // added for go-ethereum client support
+		MixHash          string `json:"mixHash"`                 //! added for go-ethereum client support
+		BaseFeePerGas    string `json:"baseFeePerGas,omitempty"` // added for go-ethereum client support
 	}
 )
 
+// ========== eth_syncing ============= //
+
+// SyncingResponse is returned by eth_syncing while the node is syncing, false is returned otherwise
+type SyncingResponse struct {
+	StartingBlock string `json:"startingBlock"` // QUANTITY - The block at which the import started
+	CurrentBlock  string `json:"currentBlock"`  // QUANTITY - The current block
+	HighestBlock  string `json:"highestBlock"`  // QUANTITY - The estimated highest block
+}
+
+/*
+	{
+	  "subscription": "0xe2ffeb2703bcf602d42922385829ce96",
+	  "result": {
+	    "syncing": true,
+	    "status": {
+	      "startingBlock": 674427,
+	      "currentBlock": 67400,
+	      "highestBlock": 674432,
+	      "pulledStates": 0,
+	      "knownStates": 0
+	    }
+	  }
+	}
+*/
+type EthSubscriptionSyncingResponse struct {
+	Syncing bool                         `json:"syncing"`
+	Status  EthSubscriptionSyncingStatus `json:"status"`
+}
+
+type EthSubscriptionSyncingStatus struct {
+	StartingBlock uint64 `json:"startingBlock"`
+	CurrentBlock  uint64 `json:"currentBlock"`
+	HighestBlock  uint64 `json:"highestBlock"`
+	// there is no state sync in qtum
+	PulledStates uint64 `json:"pulledStates"`
+	KnownStates  uint64 `json:"knownStates"`
+}
+
 var ErrInvalidAddresses = errors.New("Invalid addresses")
 
 func (s *EthLogSubscriptionParameter) GetAddresses() ([]ETHAddress, error) {
@@ -832,6 +1038,8 @@ func NewEthSubscriptionNewHeadResponse(block *GetBlockByHashResponse) *EthSubscr
 		MixHash:   "0x0000000000000000000000000000000000000000000000000000000000000000", // Added for go-ethereum client support
 		StateRoot: block.StateRoot,                                                      // Added for go-ethereum client support
 		Hash:      block.Hash,
+
+		BaseFeePerGas: block.BaseFeePerGas,
 	}
 }
 
@@ -890,14 +1098,44 @@ func (r *GetBalanceRequest) UnmarshalJSON(data []byte) error {
 
 type GetBalanceResponse string
 
+// ========== EIP-1898 block parameter ============= //
+
+// BlockNumberOrHash is the object form of the block parameter of methods reading state
+type BlockNumberOrHash struct {
+	BlockNumber      string `json:"blockNumber,omitempty"`
+	BlockHash        string `json:"blockHash,omitempty"`
+	RequireCanonical bool   `json:"requireCanonical,omitempty"`
+}
+
 // =======GetTransactionCount ============= //
 type (
 	GetTransactionCountRequest struct {
 		Address string
-		Tag     string
+		// block tag, number or EIP-1898 object
+		Tag json.RawMessage
 	}
 )
 
+func (r *GetTransactionCountRequest) UnmarshalJSON(data []byte) error {
+	var params []json.RawMessage
+	if err := json.Unmarshal(data, &params); err != nil {
+		return errors.Wrap(err, "json unmarshalling")
+	}
+
+	if len(params) == 0 {
+		return errors.New("params must be set")
+	}
+
+	if err := json.Unmarshal(params[0], &r.Address); err != nil {
+		return errors.Wrap(err, "couldn't unmarshal address")
+	}
+	if len(params) > 1 {
+		r.Tag = params[1]
+	}
+
+	return nil
+}
+
 // ========== getstorage ============= //
 type (
 	GetStorageRequest struct {
@@ -1159,3 +1397,124 @@ func (r *Web3Sha3Request) UnmarshalJSON(data []byte) error {
 }
 
 type NetPeerCountResponse string
+
+// ======= debug_traceTransaction ======= //
+
+const (
+	// StructLogger is geth's default tracer, selected by leaving `tracer` empty
+	StructLogger = ""
+	CallTracer   = "callTracer"
+)
+
+type (
+	TraceConfig struct {
+		Tracer       string          `json:"tracer"`
+		TracerConfig json.RawMessage `json:"tracerConfig"`
+		Timeout      string          `json:"timeout"`
+		// geth's state override set, only taken by debug_traceCall
+		StateOverrides json.RawMessage `json:"stateOverrides"`
+	}
+
+	TraceTransactionRequest struct {
+		Hash   string
+		Config TraceConfig
+	}
+
+	// CallFrame is a single call as reported by geth's callTracer
+	CallFrame struct {
+		Type    string      `json:"type"`
+		From    string      `json:"from"`
+		To      string      `json:"to,omitempty"`
+		Value   string      `json:"value,omitempty"`
+		Gas     string      `json:"gas"`
+		GasUsed string      `json:"gasUsed"`
+		Input   string      `json:"input"`
+		Output  string      `json:"output,omitempty"`
+		Error   string      `json:"error,omitempty"`
+		Calls   []CallFrame `json:"calls,omitempty"`
+	}
+
+	StructLog struct {
+		Pc      uint64            `json:"pc"`
+		Op      string            `json:"op"`
+		Gas     uint64            `json:"gas"`
+		GasCost uint64            `json:"gasCost"`
+		Depth   int               `json:"depth"`
+		Stack   []string          `json:"stack,omitempty"`
+		Memory  []string          `json:"memory,omitempty"`
+		Storage map[string]string `json:"storage,omitempty"`
+	}
+
+	// StructLoggerResponse is the output of geth's default tracer
+	StructLoggerResponse struct {
+		Gas         uint64      `json:"gas"`
+		Failed      bool        `json:"failed"`
+		ReturnValue string      `json:"returnValue"`
+		StructLogs  []StructLog `json:"structLogs"`
+	}
+)
+
+func (r *TraceTransactionRequest) UnmarshalJSON(data []byte) error {
+	var params []json.RawMessage
+	if err := json.Unmarshal(data, &params); err != nil {
+		return errors.Wrap(err, "json unmarshalling")
+	}
+
+	if len(params) == 0 {
+		return errors.New("params must be set")
+	}
+
+	if err := json.Unmarshal(params[0], &r.Hash); err != nil {
+		return errors.Wrap(err, "couldn't unmarshal transaction hash")
+	}
+
+	if len(params) > 1 && !isNullParam(params[1]) {
+		if err := json.Unmarshal(params[1], &r.Config); err != nil {
+			return errors.Wrap(err, "couldn't unmarshal trace config")
+		}
+	}
+
+	return nil
+}
+
+// ======= debug_traceCall ======= //
+
+type TraceCallRequest struct {
+	Call        CallRequest
+	BlockNumber json.RawMessage
+	Config      TraceConfig
+}
+
+func (r *TraceCallRequest) UnmarshalJSON(data []byte) error {
+	var params []json.RawMessage
+	if err := json.Unmarshal(data, &params); err != nil {
+		return errors.Wrap(err, "json unmarshalling")
+	}
+
+	if len(params) == 0 {
+		return errors.New("params must be set")
+	}
+
+	// CallRequest.UnmarshalJSON expects the whole params array
+	if err := json.Unmarshal(data, &r.Call); err != nil {
+		return errors.Wrap(err, "couldn't unmarshal call object")
+	}
+
+	if len(params) > 1 {
+		r.BlockNumber = params[1]
+	}
+
+	if len(params) > 2 && !isNullParam(params[2]) {
+		if err := json.Unmarshal(params[2], &r.Config); err != nil {
+			return errors.Wrap(err, "couldn't unmarshal trace config")
+		}
+	}
+	// the third parameter is the trace config, which has the state overrides of debug_traceCall
+	r.Call.StateOverrides = r.Config.StateOverrides
+
+	return nil
+}
+
+func isNullParam(param json.RawMessage) bool {
+	return len(param) == 0 || string(param) == "null"
+}
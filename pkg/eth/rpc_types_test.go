@@ -36,3 +36,111 @@ func TestEthNewPendingTransactionsRequestSerialization(t *testing.T) {
 		t.Fatalf(`"%s" != "%s"\n`, string(asJson), jsonValue)
 	}
 }
+
+func TestGetBlockReceiptsRequestDeserialization(t *testing.T) {
+	hash := "0xbba11e1bacc69ba535d478cf1f2e542da3735a517b0b8eebaf7e6bb25eeb48c5"
+	tests := []struct {
+		jsonValue   string
+		blockNumber string
+		blockHash   string
+	}{
+		{`["latest"]`, `"latest"`, ""},
+		{`["0xf8f"]`, `"0xf8f"`, ""},
+		{`["` + hash + `"]`, "", hash},
+		{`[{"blockHash":"` + hash + `"}]`, "", hash},
+		{`[{"blockNumber":"0xf8f"}]`, `"0xf8f"`, ""},
+	}
+	for _, test := range tests {
+		var request GetBlockReceiptsRequest
+		if err := json.Unmarshal([]byte(test.jsonValue), &request); err != nil {
+			t.Fatal(err)
+		}
+		if string(request.BlockNumber) != test.blockNumber || request.BlockHash != test.blockHash {
+			t.Errorf("%s: expected block number %s and hash %s, got %s and %s", test.jsonValue, test.blockNumber, test.blockHash, request.BlockNumber, request.BlockHash)
+		}
+	}
+}
+
+func TestFeeHistoryRequestDeserialization(t *testing.T) {
+	var request FeeHistoryRequest
+	if err := json.Unmarshal([]byte(`["0x5","latest",[25,75]]`), &request); err != nil {
+		t.Fatal(err)
+	}
+	if request.BlockCount != 5 || string(request.NewestBlock) != `"latest"` || len(request.RewardPercentiles) != 2 {
+		t.Errorf("Unexpected request %+v", request)
+	}
+
+	// the block count can also be a number, and the percentiles left out
+	request = FeeHistoryRequest{}
+	if err := json.Unmarshal([]byte(`[4,"0xf8f"]`), &request); err != nil {
+		t.Fatal(err)
+	}
+	if request.BlockCount != 4 || string(request.NewestBlock) != `"0xf8f"` || request.RewardPercentiles != nil {
+		t.Errorf("Unexpected request %+v", request)
+	}
+
+	for _, invalid := range []string{`["0x5"]`, `["0x5","latest",[75,25]]`, `["0x5","latest",[101]]`, `["0x5","latest",[-1]]`, `[-1,"latest"]`} {
+		if err := json.Unmarshal([]byte(invalid), &FeeHistoryRequest{}); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
+
+func TestAccessListRequestDeserialization(t *testing.T) {
+	accessList := `[{"address":"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960","storageKeys":["0x0000000000000000000000000000000000000000000000000000000000000001"]}]`
+
+	var callRequest CallRequest
+	if err := json.Unmarshal([]byte(`[{"to":"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960","accessList":`+accessList+`},"latest"]`), &callRequest); err != nil {
+		t.Fatal(err)
+	}
+	if len(callRequest.AccessList) != 1 || callRequest.AccessList.StorageKeys() != 1 {
+		t.Errorf("Unexpected access list %+v", callRequest.AccessList)
+	}
+
+	var sendRequest SendTransactionRequest
+	if err := json.Unmarshal([]byte(`[{"to":"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960","accessList":`+accessList+`}]`), &sendRequest); err != nil {
+		t.Fatal(err)
+	}
+	if len(sendRequest.AccessList) != 1 || sendRequest.AccessList.StorageKeys() != 1 {
+		t.Errorf("Unexpected access list %+v", sendRequest.AccessList)
+	}
+
+	invalidAccessLists := []string{
+		// short address
+		`[{"address":"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f9","storageKeys":[]}]`,
+		// short storage key
+		`[{"address":"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960","storageKeys":["0x01"]}]`,
+		// missing storage keys
+		`[{"address":"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"}]`,
+	}
+	for _, invalid := range invalidAccessLists {
+		if err := json.Unmarshal([]byte(`[{"accessList":`+invalid+`}]`), &CallRequest{}); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+		if err := json.Unmarshal([]byte(`[{"accessList":`+invalid+`}]`), &SendTransactionRequest{}); err == nil {
+			t.Errorf("%s: expected an error", invalid)
+		}
+	}
+}
+
+func TestCallRequestStateOverrides(t *testing.T) {
+	// overrides that override nothing are accepted, like geth
+	for _, empty := range []string{``, `,null`, `,{}`} {
+		var request CallRequest
+		if err := json.Unmarshal([]byte(`[{"to":"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"},"latest"`+empty+`]`), &request); err != nil {
+			t.Fatal(err)
+		}
+		if request.HasStateOverrides() {
+			t.Errorf("%q: expected no state overrides", empty)
+		}
+	}
+
+	var request CallRequest
+	params := `[{"to":"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"},"latest",{"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960":{"balance":"0x1"}}]`
+	if err := json.Unmarshal([]byte(params), &request); err != nil {
+		t.Fatal(err)
+	}
+	if !request.HasStateOverrides() {
+		t.Errorf("Expected state overrides in %s", params)
+	}
+}
@@ -0,0 +1,64 @@
+package ethtx
+
+import (
+	"os"
+	"path/filepath"
+	"time"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/utils"
+	bolt "go.etcd.io/bbolt"
+)
+
+const dbFileName = "transactions.db"
+
+// Ethereum transaction hash => Qtum transaction hash
+var transactionsBucket = []byte("transactions")
+
+// Store is an embedded, file backed record of the signed Ethereum transactions accepted by eth_sendRawTransaction,
+// mapping their hashes to the hashes of the Qtum transactions they were translated to
+type Store struct {
+	db *bolt.DB
+}
+
+func NewStore(dir string) (*Store, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "couldn't create Ethereum transaction store directory")
+	}
+	db, err := bolt.Open(filepath.Join(dir, dbFileName), 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open Ethereum transaction store")
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		_, err := tx.CreateBucketIfNotExists(transactionsBucket)
+		return err
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "couldn't initialize Ethereum transaction store")
+	}
+	return &Store{db: db}, nil
+}
+
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// Put records that the Ethereum transaction with the hash was sent as the Qtum transaction txid
+func (s *Store) Put(hash common.Hash, txid string) error {
+	return s.db.Update(func(tx *bolt.Tx) error {
+		return tx.Bucket(transactionsBucket).Put(hash.Bytes(), []byte(utils.RemoveHexPrefix(txid)))
+	})
+}
+
+// QtumTransaction returns the hash of the Qtum transaction an accepted Ethereum transaction was sent as, without 0x prefix
+func (s *Store) QtumTransaction(hash common.Hash) (txid string, ok bool) {
+	s.db.View(func(tx *bolt.Tx) error {
+		if value := tx.Bucket(transactionsBucket).Get(hash.Bytes()); value != nil {
+			txid, ok = string(value), true
+		}
+		return nil
+	})
+	return
+}
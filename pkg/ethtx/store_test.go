@@ -0,0 +1,39 @@
+package ethtx
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+)
+
+func TestStoreReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ethtx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	hash := common.HexToHash("0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be")
+	store, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, ok := store.QtumTransaction(hash); ok {
+		t.Fatal("Expected an unknown transaction not to be found")
+	}
+	if err := store.Put(hash, "0x11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5"); err != nil {
+		t.Fatal(err)
+	}
+	store.Close()
+
+	reopened, err := NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer reopened.Close()
+	if txid, ok := reopened.QtumTransaction(hash); !ok || txid != "11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5" {
+		t.Fatalf("Expected the Qtum transaction to be found after reopening, got %q (found: %v)", txid, ok)
+	}
+}
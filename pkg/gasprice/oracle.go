@@ -0,0 +1,198 @@
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"sync"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+const (
+	DefaultBlocks     = 20
+	DefaultPercentile = 60
+
+	// how long a suggestion is reused while the chain tip doesn't change, the mempool keeps changing in between
+	suggestionTTL = 5 * time.Second
+	// most mempool transactions looked at, each new one costs a getrawtransaction call
+	maxMempoolSamples = 1000
+)
+
+// Oracle suggests a gas price from the gas prices paid by the EVM transactions of the latest blocks and of the mempool.
+//
+// The gas price of a transaction is the one in its OP_CALL or OP_CREATE script, the configured percentile of every sampled
+// price is suggested, and never less than qtumd's minimum gas price.
+// The prices of blocks and of mempool transactions are kept, so only blocks mined and transactions sent since the last
+// suggestion are fetched
+type Oracle struct {
+	qtum       *qtum.Qtum
+	blocks     int64
+	percentile int
+
+	mutex sync.Mutex
+	// block hash => gas prices of the contract transactions of the block, in satoshis
+	blockPrices map[string][]*big.Int
+	// mempool transaction id => its gas price, nil for transactions without contract outputs
+	mempoolPrices map[string]*big.Int
+
+	lastTip        string
+	lastSuggestion *big.Int
+	lastUpdate     time.Time
+}
+
+// New returns an oracle suggesting the percentile of the gas prices of the latest blocks and of the mempool
+func New(qtumClient *qtum.Qtum, blocks int, percentile int) (*Oracle, error) {
+	if blocks < 1 {
+		return nil, errors.Errorf("invalid number of blocks %d", blocks)
+	}
+	if percentile < 0 || percentile > 100 {
+		return nil, errors.Errorf("invalid percentile %d", percentile)
+	}
+	return &Oracle{
+		qtum:          qtumClient,
+		blocks:        int64(blocks),
+		percentile:    percentile,
+		blockPrices:   map[string][]*big.Int{},
+		mempoolPrices: map[string]*big.Int{},
+	}, nil
+}
+
+// SuggestGasPrice returns the suggested gas price in satoshis.
+// Blocks and mempool transactions are fetched without holding the mutex, so a slow sample doesn't block other callers
+// from reusing the last suggestion. Callers sampling concurrently each fetch what isn't cached yet, the last one to finish is kept
+func (o *Oracle) SuggestGasPrice(ctx context.Context) (*big.Int, error) {
+	minimumGasPrice, err := o.qtum.GetGasPrice(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't get minimum gas price")
+	}
+	blockchainInfo, err := o.qtum.GetBlockChainInfo(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't get chain tip")
+	}
+
+	o.mutex.Lock()
+	if o.lastSuggestion != nil && o.lastTip == blockchainInfo.Bestblockhash && time.Since(o.lastUpdate) < suggestionTTL {
+		suggestion := new(big.Int).Set(o.lastSuggestion)
+		o.mutex.Unlock()
+		return suggestion, nil
+	}
+	// the cached prices are replaced rather than updated, so they can be read after unlocking
+	cachedBlockPrices := o.blockPrices
+	cachedMempoolPrices := o.mempoolPrices
+	o.mutex.Unlock()
+
+	prices, blockPrices, err := o.sampleBlocks(ctx, blockchainInfo.Blocks, cachedBlockPrices)
+	if err != nil {
+		return nil, err
+	}
+	mempoolPrices, mempoolCache, err := o.sampleMempool(ctx, cachedMempoolPrices)
+	if err != nil {
+		return nil, err
+	}
+	prices = append(prices, mempoolPrices...)
+
+	suggestion := new(big.Int).Set(minimumGasPrice)
+	if len(prices) > 0 {
+		sort.Slice(prices, func(i, j int) bool {
+			return prices[i].Cmp(prices[j]) < 0
+		})
+		if price := prices[(len(prices)-1)*o.percentile/100]; price.Cmp(suggestion) > 0 {
+			suggestion.Set(price)
+		}
+	}
+
+	o.mutex.Lock()
+	defer o.mutex.Unlock()
+	o.blockPrices = blockPrices
+	o.mempoolPrices = mempoolCache
+	o.lastTip = blockchainInfo.Bestblockhash
+	o.lastSuggestion = suggestion
+	o.lastUpdate = time.Now()
+	return new(big.Int).Set(suggestion), nil
+}
+
+// sampleBlocks returns the gas prices of the contract transactions of the latest blocks up to tip,
+// and the prices of each of those blocks by hash, which replace cached
+func (o *Oracle) sampleBlocks(ctx context.Context, tip int64, cached map[string][]*big.Int) ([]*big.Int, map[string][]*big.Int, error) {
+	prices := []*big.Int{}
+	blockPrices := make(map[string][]*big.Int, o.blocks)
+	for height := tip; height >= 0 && height > tip-o.blocks; height-- {
+		blockHash, err := o.qtum.GetBlockHash(ctx, big.NewInt(height))
+		if err != nil {
+			return nil, nil, errors.WithMessagef(err, "couldn't get hash of block %d", height)
+		}
+
+		txPrices, ok := cached[string(blockHash)]
+		if !ok {
+			block, err := o.qtum.GetBlockVerbose(ctx, string(blockHash))
+			if err != nil {
+				return nil, nil, errors.WithMessagef(err, "couldn't get block %s", blockHash)
+			}
+			txPrices = []*big.Int{}
+			for _, tx := range block.Txs {
+				if price := o.contractGasPrice(&tx.DecodedRawTransactionResponse); price != nil {
+					txPrices = append(txPrices, price)
+				}
+			}
+		}
+		blockPrices[string(blockHash)] = txPrices
+		prices = append(prices, txPrices...)
+	}
+	// blocks that left the window, or were reorged out, aren't sampled again
+	return prices, blockPrices, nil
+}
+
+// sampleMempool returns the gas prices of the contract transactions in the mempool,
+// and the price of each of those transactions by id, which replace cached
+func (o *Oracle) sampleMempool(ctx context.Context, cached map[string]*big.Int) ([]*big.Int, map[string]*big.Int, error) {
+	mempool, err := o.qtum.GetRawMempool(ctx)
+	if err != nil {
+		return nil, nil, errors.WithMessage(err, "couldn't get mempool")
+	}
+	if len(mempool) > maxMempoolSamples {
+		mempool = mempool[:maxMempoolSamples]
+	}
+
+	prices := []*big.Int{}
+	mempoolPrices := make(map[string]*big.Int, len(mempool))
+	for _, txID := range mempool {
+		price, ok := cached[txID]
+		if !ok {
+			rawTx, err := o.qtum.GetRawTransaction(ctx, txID, false)
+			if err != nil {
+				// the transaction might have been mined or evicted since getrawmempool
+				o.qtum.GetDebugLogger().Log("msg", "Failed to get pending transaction", "hash", txID, "err", err)
+				continue
+			}
+			tx := &qtum.DecodedRawTransactionResponse{ID: txID}
+			for _, vout := range rawTx.Vouts {
+				tx.Vouts = append(tx.Vouts, &qtum.DecodedRawTransactionOutV{
+					ScriptPubKey: qtum.DecodedRawTransactionScriptPubKey{Hex: vout.Details.Hex},
+				})
+			}
+			price = o.contractGasPrice(tx)
+		}
+		mempoolPrices[txID] = price
+		if price != nil {
+			prices = append(prices, price)
+		}
+	}
+	return prices, mempoolPrices, nil
+}
+
+// contractGasPrice returns the gas price of the OP_CALL or OP_CREATE output of a transaction, or nil if it has none
+func (o *Oracle) contractGasPrice(tx *qtum.DecodedRawTransactionResponse) *big.Int {
+	info, isContractTx, err := tx.ExtractContractInfo()
+	if err != nil || !isContractTx {
+		return nil
+	}
+	price, err := info.GasPriceInSatoshis()
+	if err != nil {
+		o.qtum.GetDebugLogger().Log("msg", "couldn't parse gas price", "txid", tx.ID, "gasPrice", info.GasPrice, "err", err)
+		return nil
+	}
+	return price
+}
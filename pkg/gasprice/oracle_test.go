@@ -0,0 +1,129 @@
+package gasprice
+
+import (
+	"context"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+// an OP_CALL script with a gas limit of 250000 and a gas price of 40 satoshi
+const callScriptHex = "540390d003012844095ea7b300000000000000000000000025495b3a87d82e9d7a71b341addfc0d7bb3475c7ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff1454fefdb5b31164f66ddb68becd7bdd864cacd65bc2"
+
+func callScript(gasPrice string) string {
+	return strings.Replace(callScriptHex, "0128", "01"+gasPrice, 1)
+}
+
+func blockWithCall(hash string, txid string, gasPrice string) qtum.GetBlockVerboseResponse {
+	block := internal.GetBlockVerboseResponse
+	block.Hash = hash
+	block.Txs = append([]*qtum.BlockTransaction{}, internal.GetBlockVerboseResponse.Txs...)
+	block.Txs = append(block.Txs, &qtum.BlockTransaction{DecodedRawTransactionResponse: qtum.DecodedRawTransactionResponse{
+		ID: txid,
+		Vouts: []*qtum.DecodedRawTransactionOutV{
+			{N: 0, ScriptPubKey: qtum.DecodedRawTransactionScriptPubKey{Hex: callScript(gasPrice)}},
+		},
+	}})
+	return block
+}
+
+// newTestOracle mocks two blocks with calls paying 100 and 40 satoshi, and a call paying 200 satoshi in the mempool
+func newTestOracle(t *testing.T, percentile int) *Oracle {
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tipHash := "d7758774cfdd6bab7774aa891ae035f1dc5a2ff44240784b5e7bdfd43a7a6ec1"
+	parentHash := internal.GetTransactionByHashBlockHash
+	mempoolTxID := "3ef34ec81e32cd6df4ad3d5b3b4f8b2c54d9bc6f1a7b8d0ec1f2a6b1c9d8e7f6"
+	responses := []struct {
+		method   string
+		response interface{}
+	}{
+		{qtum.MethodGetBlockChainInfo, qtum.GetBlockChainInfoResponse{Blocks: 3984, Bestblockhash: tipHash}},
+		{qtum.MethodGetBlockHash, qtum.GetBlockHashResponse(tipHash)},
+		{qtum.MethodGetBlockHash, qtum.GetBlockHashResponse(parentHash)},
+		{qtum.MethodGetBlock, blockWithCall(tipHash, "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", "64")},
+		{qtum.MethodGetBlock, blockWithCall(parentHash, "8fcd819194cce6a8454b2bec334d3448df4f097e9cdc36707bfd569900268951", "28")},
+		{qtum.MethodGetRawMempool, qtum.GetRawMempoolResponse{mempoolTxID}},
+		{qtum.MethodGetRawTransaction, qtum.GetRawTransactionResponse{
+			ID: mempoolTxID,
+			Vouts: []qtum.RawTransactionVout{
+				{Details: qtum.RawTransactionVoutDetails{Hex: callScript("c8")}},
+			},
+		}},
+	}
+	for _, r := range responses {
+		if err := mockedClientDoer.AddResponse(r.method, r.response); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oracle, err := New(qtumClient, 2, percentile)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return oracle
+}
+
+func TestOracleSuggestsPercentile(t *testing.T) {
+	tests := []struct {
+		percentile int
+		want       int64
+	}{
+		{0, 40},
+		{60, 100},
+		{100, 200},
+	}
+	for _, test := range tests {
+		oracle := newTestOracle(t, test.percentile)
+		got, err := oracle.SuggestGasPrice(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if got.Cmp(big.NewInt(test.want)) != 0 {
+			t.Errorf("percentile %d: expected gas price %d, got %s", test.percentile, test.want, got)
+		}
+	}
+}
+
+func TestOracleMinimumGasPrice(t *testing.T) {
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// a call paying 10 satoshi, which qtumd wouldn't accept anymore, and an empty mempool
+	hash := internal.GetTransactionByHashBlockHash
+	responses := []struct {
+		method   string
+		response interface{}
+	}{
+		{qtum.MethodGetBlockChainInfo, qtum.GetBlockChainInfoResponse{Blocks: 0, Bestblockhash: hash}},
+		{qtum.MethodGetBlockHash, qtum.GetBlockHashResponse(hash)},
+		{qtum.MethodGetBlock, blockWithCall(hash, "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", "0a")},
+		{qtum.MethodGetRawMempool, qtum.GetRawMempoolResponse{}},
+	}
+	for _, r := range responses {
+		if err := mockedClientDoer.AddResponse(r.method, r.response); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	oracle, err := New(qtumClient, DefaultBlocks, 100)
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, err := oracle.SuggestGasPrice(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got.Cmp(big.NewInt(40)) != 0 {
+		t.Errorf("Expected the minimum gas price of 40 satoshi, got %s", got)
+	}
+}
@@ -289,13 +289,31 @@ var (
 		V:                "0x25",
 	}
 
+	// the coinbase of the block, translated from the verbose block
+	GetTransactionByHashCoinbaseData = eth.GetTransactionByHashResponse{
+		BlockHash:        GetTransactionByHashBlockHexHash,
+		BlockNumber:      GetTransactionByHashBlockNumberHex,
+		TransactionIndex: "0x0",
+		Hash:             "0x3208dc44733cbfa11654ad5651305428de473ef1e61a1ec07b0c1a5f4843be91",
+		Nonce:            "0x0",
+		Value:            "0x0",
+		Input:            "0x",
+		From:             "0x0000000000000000000000000000000000000000",
+		To:               "0x0000000000000000000000000000000000000000",
+		Gas:              "0x0",
+		GasPrice:         "0x0",
+		R:                "0xf000000000000000000000000000000000000000000000000000000000000000",
+		S:                "0xf000000000000000000000000000000000000000000000000000000000000000",
+		V:                "0x25",
+	}
+
 	GetTransactionByHashResponse = CreateTransactionByHashResponse()
 
 	GetTransactionByHashResponseWithTransactions = eth.GetBlockByHashResponse{
 		Number:           GetTransactionByHashBlockNumberHex,
 		Hash:             GetTransactionByHashBlockHexHash,
 		ParentHash:       "0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be",
-		Miner:            "0x0000000000000000000000000000000000000000",
+		Miner:            "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
 		Size:             "0x26c",
 		Nonce:            "0x0000000000000000",
 		TransactionsRoot: "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
@@ -309,18 +327,20 @@ var (
 		GasUsed:          "0x0",
 		Timestamp:        "0x5b95ebd0",
 		Transactions: []interface{}{
-			GetTransactionByHashResponseData,
+			GetTransactionByHashCoinbaseData,
+			// looked up with getTransactionByHash, which the mocks answer with the same transaction for every hash
 			GetTransactionByHashResponseData,
 		},
-		Sha3Uncles: eth.DefaultSha3Uncles,
-		Uncles:     []string{},
+		Sha3Uncles:    eth.DefaultSha3Uncles,
+		Uncles:        []string{},
+		BaseFeePerGas: "0x5d21dba000",
 	}
 
 	GetTransactionByBlockResponse = eth.GetBlockByNumberResponse{
 		Number:           GetTransactionByHashBlockNumberHex,
 		Hash:             GetTransactionByHashBlockHexHash,
 		ParentHash:       "0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be",
-		Miner:            "0x0000000000000000000000000000000000000000",
+		Miner:            "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
 		Size:             "0x26c",
 		Nonce:            "0x0000000000000000",
 		TransactionsRoot: "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
@@ -335,15 +355,16 @@ var (
 		Timestamp:        "0x5b95ebd0",
 		Transactions: []interface{}{"0x3208dc44733cbfa11654ad5651305428de473ef1e61a1ec07b0c1a5f4843be91",
 			"0x8fcd819194cce6a8454b2bec334d3448df4f097e9cdc36707bfd569900268950"},
-		Sha3Uncles: eth.DefaultSha3Uncles,
-		Uncles:     []string{},
+		Sha3Uncles:    eth.DefaultSha3Uncles,
+		Uncles:        []string{},
+		BaseFeePerGas: "0x5d21dba000",
 	}
 
 	GetTransactionByBlockResponseWithTransactions = eth.GetBlockByNumberResponse{
 		Number:           GetTransactionByHashBlockNumberHex,
 		Hash:             GetTransactionByHashBlockHexHash,
 		ParentHash:       "0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be",
-		Miner:            "0x0000000000000000000000000000000000000000",
+		Miner:            "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
 		Size:             "0x26c",
 		Nonce:            "0x0000000000000000",
 		TransactionsRoot: "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
@@ -360,8 +381,9 @@ var (
 			GetTransactionByHashResponseData,
 			GetTransactionByHashResponseData,
 		},
-		Sha3Uncles: eth.DefaultSha3Uncles,
-		Uncles:     []string{},
+		Sha3Uncles:    eth.DefaultSha3Uncles,
+		Uncles:        []string{},
+		BaseFeePerGas: "0x5d21dba000",
 	}
 
 	GetBlockResponse = qtum.GetBlockResponse{
@@ -391,6 +413,22 @@ var (
 		Nextblockhash: "d7758774cfdd6bab7774aa891ae035f1dc5a2ff44240784b5e7bdfd43a7a6ec1",
 		Signature:     "3045022100a6ab6c2b14b1f73e734f1a61d4d22385748e48836492723a6ab37cdf38525aba022014a51ecb9e51f5a7a851641683541fec6f8f20205d0db49e50b2a4e5daed69d2",
 	}
+
+	GetBlockVerboseResponse = qtum.GetBlockVerboseResponse{
+		GetBlockResponse: GetBlockResponse,
+		Txs: []*qtum.BlockTransaction{
+			// the coinbase, which spends no output
+			{DecodedRawTransactionResponse: qtum.DecodedRawTransactionResponse{
+				ID:   "3208dc44733cbfa11654ad5651305428de473ef1e61a1ec07b0c1a5f4843be91",
+				Vins: []*qtum.DecodedRawTransactionInV{{}},
+			}},
+			// the coinstake, without the output it spends like qtumd versions without getblock verbosity 3 return it
+			{DecodedRawTransactionResponse: qtum.DecodedRawTransactionResponse{
+				ID:   "8fcd819194cce6a8454b2bec334d3448df4f097e9cdc36707bfd569900268950",
+				Vins: []*qtum.DecodedRawTransactionInV{{TxID: "7f5350dc474f2953a3f30282c1afcad2fb61cdcea5bd949c808ecc6f64ce1503"}},
+			}},
+		},
+	}
 )
 
 func CreateTransactionByHashResponse() eth.GetBlockByHashResponse {
@@ -398,7 +436,7 @@ func CreateTransactionByHashResponse() eth.GetBlockByHashResponse {
 		Number:           GetTransactionByHashBlockNumberHex,
 		Hash:             GetTransactionByHashBlockHexHash,
 		ParentHash:       "0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be",
-		Miner:            "0x0000000000000000000000000000000000000000",
+		Miner:            "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
 		Size:             "0x26c",
 		Nonce:            "0x0000000000000000",
 		TransactionsRoot: "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
@@ -413,8 +451,9 @@ func CreateTransactionByHashResponse() eth.GetBlockByHashResponse {
 		Timestamp:        "0x5b95ebd0",
 		Transactions: []interface{}{"0x3208dc44733cbfa11654ad5651305428de473ef1e61a1ec07b0c1a5f4843be91",
 			"0x8fcd819194cce6a8454b2bec334d3448df4f097e9cdc36707bfd569900268950"},
-		Sha3Uncles: eth.DefaultSha3Uncles,
-		Uncles:     []string{},
+		Sha3Uncles:    eth.DefaultSha3Uncles,
+		Uncles:        []string{},
+		BaseFeePerGas: "0x5d21dba000",
 	}
 }
 
@@ -453,6 +492,15 @@ func SetupGetBlockByHashResponses(t *testing.T, mockedClientDoer Doer) {
 	SetupGetBlockByHashResponsesWithVouts(t, []*qtum.DecodedRawTransactionOutV{}, mockedClientDoer)
 }
 
+// SetupGetBlockByHashResponsesWithTransactions mocks a block fetched along with its transactions, the reward transactions of which are still looked up one by one
+func SetupGetBlockByHashResponsesWithTransactions(t *testing.T, mockedClientDoer Doer) {
+	err := mockedClientDoer.AddResponse(qtum.MethodGetBlock, GetBlockVerboseResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+	SetupGetBlockByHashResponses(t, mockedClientDoer)
+}
+
 func SetupGetBlockByHashResponsesWithVouts(t *testing.T, vouts []*qtum.DecodedRawTransactionOutV, mockedClientDoer Doer) {
 	//preparing answer to "getblockhash"
 	getBlockHashResponse := qtum.GetBlockHashResponse(GetTransactionByHashBlockHexHash)
@@ -537,8 +585,8 @@ func SetupGetBlockByHashResponsesWithVouts(t *testing.T, vouts []*qtum.DecodedRa
 		t.Fatal(err)
 	}
 
-	getTransactionReceiptResponse := qtum.GetTransactionReceiptResponse{}
-	err = mockedClientDoer.AddResponse(qtum.MethodGetTransactionReceipt, &getTransactionReceiptResponse)
+	// the transaction has no contract outputs, so no EVM receipts
+	err = mockedClientDoer.AddResponse(qtum.MethodGetTransactionReceipt, []qtum.TransactionReceipt{})
 	if err != nil {
 		t.Fatal(err)
 	}
@@ -0,0 +1,486 @@
+package logindex
+
+import (
+	"bytes"
+	"context"
+	"encoding/binary"
+	"encoding/json"
+	"math/big"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/conversion"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+	bolt "go.etcd.io/bbolt"
+)
+
+var ErrNotIndexed = errors.New("block range is not indexed")
+
+const (
+	// number of blocks requested from searchlogs at a time while catching up
+	batchSize = 1000
+	// how often to poll qtumd for new blocks once caught up
+	followInterval = 5 * time.Second
+
+	dbFileName = "logs.db"
+)
+
+var (
+	// "head" => highest indexed block, missing until the first batch is indexed
+	metaBucket = []byte("meta")
+	// block height => block hash, for every block with logs and the tip of every indexed batch
+	hashesBucket = []byte("hashes")
+	// block height => json encoded receipts with logs in that block
+	receiptsBucket = []byte("receipts")
+	// lower case address, 0, block height => nothing
+	addressesBucket = []byte("addresses")
+	// lower case topic, 0, block height => nothing
+	topicsBucket = []byte("topics")
+
+	headKey = []byte("head")
+
+	buckets = [][]byte{metaBucket, hashesBucket, receiptsBucket, addressesBucket, topicsBucket}
+)
+
+// Index is a persistent index of EVM logs which follows the chain tip and answers searchlogs style queries locally.
+//
+// Logs are fetched from qtumd's searchlogs once and kept in a BoltDB file, keyed by block height, address and topic,
+// so queries only read the blocks that can match and nothing is loaded in memory on start.
+// Block hashes are recorded for every block containing logs and for the tip of every indexed batch,
+// reorgs are detected by comparing those against the node and the orphaned blocks are dropped and re-indexed
+type Index struct {
+	ctx  context.Context
+	qtum *qtum.Qtum
+	db   *bolt.DB
+}
+
+func New(ctx context.Context, qtumClient *qtum.Qtum, dir string) (*Index, error) {
+	if err := os.MkdirAll(dir, 0755); err != nil {
+		return nil, errors.Wrap(err, "couldn't create index directory")
+	}
+	db, err := bolt.Open(filepath.Join(dir, dbFileName), 0644, &bolt.Options{Timeout: time.Second})
+	if err != nil {
+		return nil, errors.Wrap(err, "couldn't open log index")
+	}
+	err = db.Update(func(tx *bolt.Tx) error {
+		for _, bucket := range buckets {
+			if _, err := tx.CreateBucketIfNotExists(bucket); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		db.Close()
+		return nil, errors.Wrap(err, "couldn't initialize log index")
+	}
+
+	return &Index{
+		ctx:  ctx,
+		qtum: qtumClient,
+		db:   db,
+	}, nil
+}
+
+// Start follows the chain in the background until the context is done
+func (idx *Index) Start() {
+	go idx.follow()
+}
+
+// Head returns the highest indexed block
+func (idx *Index) Head() (head uint64, indexed bool) {
+	idx.db.View(func(tx *bolt.Tx) error {
+		head, indexed = readHead(tx)
+		return nil
+	})
+	return
+}
+
+// SearchLogs returns the same receipts as searchlogs filtered by conversion.SearchLogsAndFilterExtraTopics would,
+// or ErrNotIndexed if the requested range isn't fully indexed yet
+func (idx *Index) SearchLogs(req *qtum.SearchLogsRequest) (qtum.SearchLogsResponse, error) {
+	if req.FromBlock == nil || req.ToBlock == nil || req.FromBlock.Sign() < 0 || req.ToBlock.Sign() < 0 {
+		return nil, ErrNotIndexed
+	}
+	from := req.FromBlock.Uint64()
+	to := req.ToBlock.Uint64()
+
+	addresses := make(map[string]bool, len(req.Addresses))
+	for _, address := range req.Addresses {
+		addresses[strings.ToLower(utils.RemoveHexPrefix(address))] = true
+	}
+
+	receipts := qtum.SearchLogsResponse{}
+	err := idx.db.View(func(tx *bolt.Tx) error {
+		if head, indexed := readHead(tx); !indexed || to > head {
+			return ErrNotIndexed
+		}
+
+		blocks := tx.Bucket(receiptsBucket)
+		for _, height := range candidates(tx, from, to, addresses, req.Topics) {
+			var blockReceipts []qtum.TransactionReceipt
+			if err := json.Unmarshal(blocks.Get(heightKey(height)), &blockReceipts); err != nil {
+				return errors.Wrapf(err, "couldn't decode receipts of block %d", height)
+			}
+			for _, receipt := range blockReceipts {
+				var logs []qtum.Log
+				for index, log := range receipt.Log {
+					log.Index = index
+					if len(addresses) != 0 && !addresses[strings.ToLower(log.Address)] {
+						continue
+					}
+					if !conversion.DoFiltersMatch(req.Topics, log.Topics) {
+						continue
+					}
+					logs = append(logs, log)
+				}
+				if len(logs) != 0 {
+					receipt.Log = logs
+					receipts = append(receipts, receipt)
+				}
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return receipts, nil
+}
+
+// candidates returns the sorted heights in [from, to] that can contain logs matching the filter
+func candidates(tx *bolt.Tx, from, to uint64, addresses map[string]bool, topics []qtum.SearchLogsTopic) []uint64 {
+	var sets []map[uint64]struct{}
+	if len(addresses) != 0 {
+		set := map[uint64]struct{}{}
+		for address := range addresses {
+			scanKey(tx.Bucket(addressesBucket), address, from, to, set)
+		}
+		sets = append(sets, set)
+	}
+	for _, topic := range topics {
+		if len(topic) == 0 {
+			continue
+		}
+		set := map[uint64]struct{}{}
+		for _, alternative := range topic {
+			scanKey(tx.Bucket(topicsBucket), strings.ToLower(utils.RemoveHexPrefix(alternative)), from, to, set)
+		}
+		sets = append(sets, set)
+	}
+
+	var heights []uint64
+	if len(sets) == 0 {
+		cursor := tx.Bucket(receiptsBucket).Cursor()
+		for k, _ := cursor.Seek(heightKey(from)); k != nil; k, _ = cursor.Next() {
+			height := binary.BigEndian.Uint64(k)
+			if height > to {
+				break
+			}
+			heights = append(heights, height)
+		}
+		return heights
+	}
+
+	// the smallest set bounds the candidates
+	sort.Slice(sets, func(i, j int) bool { return len(sets[i]) < len(sets[j]) })
+	for height := range sets[0] {
+		matches := true
+		for _, set := range sets[1:] {
+			if _, ok := set[height]; !ok {
+				matches = false
+				break
+			}
+		}
+		if matches {
+			heights = append(heights, height)
+		}
+	}
+	sort.Slice(heights, func(i, j int) bool { return heights[i] < heights[j] })
+	return heights
+}
+
+// scanKey adds the heights in [from, to] indexed under key to set
+func scanKey(bucket *bolt.Bucket, key string, from, to uint64, set map[uint64]struct{}) {
+	prefix := append([]byte(key), 0)
+	cursor := bucket.Cursor()
+	for k, _ := cursor.Seek(append(prefix, heightKey(from)...)); k != nil && bytes.HasPrefix(k, prefix); k, _ = cursor.Next() {
+		height := binary.BigEndian.Uint64(k[len(prefix):])
+		if height > to {
+			break
+		}
+		set[height] = struct{}{}
+	}
+}
+
+func (idx *Index) follow() {
+	logger := idx.qtum.GetDebugLogger()
+	for {
+		caughtUp, err := idx.sync(idx.ctx)
+		if err != nil {
+			logger.Log("component", "logindex", "msg", "failed to index logs", "err", err)
+		}
+
+		wait := time.Duration(0)
+		if err != nil || caughtUp {
+			wait = followInterval
+		}
+
+		select {
+		case <-idx.ctx.Done():
+			idx.close()
+			return
+		case <-time.After(wait):
+		}
+	}
+}
+
+func (idx *Index) close() error {
+	return idx.db.Close()
+}
+
+// sync indexes at most one batch of blocks, returning true once the index has caught up with the node
+func (idx *Index) sync(ctx context.Context) (bool, error) {
+	if err := idx.handleReorg(ctx); err != nil {
+		return false, errors.WithMessage(err, "couldn't check for reorgs")
+	}
+
+	blockCount, err := idx.qtum.GetBlockCount(ctx)
+	if err != nil {
+		return false, err
+	}
+	tip := blockCount.Uint64()
+
+	from, indexed := idx.Head()
+	if indexed {
+		from++
+	}
+	if indexed && from > tip {
+		return true, nil
+	}
+
+	to := from + batchSize - 1
+	if to > tip {
+		to = tip
+	}
+
+	// the batch is only recorded if block to is the same before and after searchlogs,
+	// otherwise a reorg while searching could have mixed logs of orphaned blocks in
+	hash, err := idx.qtum.GetBlockHash(ctx, new(big.Int).SetUint64(to))
+	if err != nil {
+		return false, err
+	}
+	receipts, err := idx.qtum.SearchLogs(ctx, &qtum.SearchLogsRequest{
+		FromBlock: new(big.Int).SetUint64(from),
+		ToBlock:   new(big.Int).SetUint64(to),
+	})
+	if err != nil {
+		return false, err
+	}
+	hashAfter, err := idx.qtum.GetBlockHash(ctx, new(big.Int).SetUint64(to))
+	if err != nil {
+		return false, err
+	}
+	if hashAfter != hash {
+		idx.qtum.GetDebugLogger().Log("component", "logindex", "msg", "block changed while searching logs, retrying", "height", to)
+		return false, nil
+	}
+	// nor if the recorded head was orphaned since handleReorg checked it, the batch would be written on top of it
+	// and the logs of the orphaned head would never be dropped
+	var headHash string
+	if indexed {
+		hash, err := idx.qtum.GetBlockHash(ctx, new(big.Int).SetUint64(from-1))
+		if err != nil {
+			return false, err
+		}
+		headHash = utils.RemoveHexPrefix(string(hash))
+	}
+
+	headChanged := false
+	err = idx.db.Update(func(tx *bolt.Tx) error {
+		// don't record the batch if a reorg was handled concurrently
+		head, indexed := readHead(tx)
+		if (indexed && head+1 != from) || (!indexed && from != 0) {
+			return nil
+		}
+		if indexed && string(tx.Bucket(hashesBucket).Get(heightKey(head))) != headHash {
+			headChanged = true
+			return nil
+		}
+		return writeBatch(tx, to, utils.RemoveHexPrefix(string(hash)), receipts)
+	})
+	if err != nil {
+		return false, err
+	}
+	if headChanged {
+		idx.qtum.GetDebugLogger().Log("component", "logindex", "msg", "indexed head changed while searching logs, checking for reorgs", "height", from-1)
+		return false, nil
+	}
+	return to == tip, nil
+}
+
+// handleReorg compares the recorded block hashes with the node, from the highest down,
+// and drops everything above the highest block that is still part of the chain
+func (idx *Index) handleReorg(ctx context.Context) error {
+	height, recorded, ok := idx.lastHash(nil)
+	for i := 0; ok; i++ {
+		hash, err := idx.qtum.GetBlockHash(ctx, new(big.Int).SetUint64(height))
+		if err != nil && errors.Cause(err) != qtum.ErrInvalidParameter {
+			return err
+		}
+		if err == nil && recorded == utils.RemoveHexPrefix(string(hash)) {
+			if i == 0 {
+				return nil
+			}
+			idx.qtum.GetDebugLogger().Log("component", "logindex", "msg", "reorg detected", "forkHeight", height)
+			return idx.db.Update(func(tx *bolt.Tx) error {
+				return revert(tx, height)
+			})
+		}
+		height, recorded, ok = idx.lastHash(&height)
+	}
+
+	if _, indexed := idx.Head(); !indexed {
+		return nil
+	}
+
+	idx.qtum.GetDebugLogger().Log("component", "logindex", "msg", "no indexed block is part of the chain anymore, reindexing")
+	return idx.db.Update(reset)
+}
+
+// lastHash returns the highest recorded block hash, below a height if it isn't nil
+func (idx *Index) lastHash(below *uint64) (height uint64, hash string, ok bool) {
+	idx.db.View(func(tx *bolt.Tx) error {
+		cursor := tx.Bucket(hashesBucket).Cursor()
+		var k, v []byte
+		if below == nil {
+			k, v = cursor.Last()
+		} else {
+			// the height itself, or the first one after it if it was reverted meanwhile
+			k, v = cursor.Seek(heightKey(*below))
+			if k == nil {
+				k, v = cursor.Last()
+			}
+			for k != nil && binary.BigEndian.Uint64(k) >= *below {
+				k, v = cursor.Prev()
+			}
+		}
+		if k != nil {
+			height, hash, ok = binary.BigEndian.Uint64(k), string(v), true
+		}
+		return nil
+	})
+	return
+}
+
+// writeBatch records the logs found up to block to, whose hash is hash
+func writeBatch(tx *bolt.Tx, to uint64, hash string, receipts []qtum.TransactionReceipt) error {
+	blocks := map[uint64][]qtum.TransactionReceipt{}
+	hashes := tx.Bucket(hashesBucket)
+	addresses := tx.Bucket(addressesBucket)
+	topics := tx.Bucket(topicsBucket)
+	for _, receipt := range receipts {
+		height := receipt.BlockNumber
+		blocks[height] = append(blocks[height], receipt)
+		if err := hashes.Put(heightKey(height), []byte(utils.RemoveHexPrefix(receipt.BlockHash))); err != nil {
+			return err
+		}
+		for _, log := range receipt.Log {
+			if err := addresses.Put(indexKey(log.Address, height), nil); err != nil {
+				return err
+			}
+			for _, topic := range log.Topics {
+				if err := topics.Put(indexKey(topic, height), nil); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	for height, blockReceipts := range blocks {
+		value, err := json.Marshal(blockReceipts)
+		if err != nil {
+			return err
+		}
+		if err := tx.Bucket(receiptsBucket).Put(heightKey(height), value); err != nil {
+			return err
+		}
+	}
+	if err := hashes.Put(heightKey(to), []byte(hash)); err != nil {
+		return err
+	}
+	return tx.Bucket(metaBucket).Put(headKey, heightKey(to))
+}
+
+// revert drops every block above height
+func revert(tx *bolt.Tx, height uint64) error {
+	blocks := tx.Bucket(receiptsBucket)
+	cursor := blocks.Cursor()
+	for k, v := cursor.Seek(heightKey(height + 1)); k != nil; k, v = cursor.Seek(heightKey(height + 1)) {
+		var receipts []qtum.TransactionReceipt
+		if err := json.Unmarshal(v, &receipts); err != nil {
+			return errors.Wrap(err, "couldn't decode receipts")
+		}
+		blockHeight := binary.BigEndian.Uint64(k)
+		for _, receipt := range receipts {
+			for _, log := range receipt.Log {
+				if err := tx.Bucket(addressesBucket).Delete(indexKey(log.Address, blockHeight)); err != nil {
+					return err
+				}
+				for _, topic := range log.Topics {
+					if err := tx.Bucket(topicsBucket).Delete(indexKey(topic, blockHeight)); err != nil {
+						return err
+					}
+				}
+			}
+		}
+		if err := blocks.Delete(k); err != nil {
+			return err
+		}
+	}
+
+	hashes := tx.Bucket(hashesBucket).Cursor()
+	for k, _ := hashes.Seek(heightKey(height + 1)); k != nil; k, _ = hashes.Seek(heightKey(height + 1)) {
+		if err := hashes.Delete(); err != nil {
+			return err
+		}
+	}
+	return tx.Bucket(metaBucket).Put(headKey, heightKey(height))
+}
+
+// reset drops everything
+func reset(tx *bolt.Tx) error {
+	for _, bucket := range buckets {
+		if err := tx.DeleteBucket(bucket); err != nil {
+			return err
+		}
+		if _, err := tx.CreateBucket(bucket); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func readHead(tx *bolt.Tx) (uint64, bool) {
+	head := tx.Bucket(metaBucket).Get(headKey)
+	if head == nil {
+		return 0, false
+	}
+	return binary.BigEndian.Uint64(head), true
+}
+
+func heightKey(height uint64) []byte {
+	key := make([]byte, 8)
+	binary.BigEndian.PutUint64(key, height)
+	return key
+}
+
+func indexKey(value string, height uint64) []byte {
+	key := append([]byte(strings.ToLower(utils.RemoveHexPrefix(value))), 0)
+	return append(key, heightKey(height)...)
+}
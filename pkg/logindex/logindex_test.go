@@ -0,0 +1,256 @@
+package logindex
+
+import (
+	"context"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+const (
+	tokenAddress  = "db46f738bf32cdafb9a4a70eb8b44c76646bcaf0"
+	otherAddress  = "1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"
+	transferTopic = "ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"
+	approvalTopic = "8c5be1e5ebec7d5bd14f71427b1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"
+)
+
+func testReceipt(height uint64, blockHash string, txHash string, logs ...qtum.Log) qtum.TransactionReceipt {
+	return qtum.TransactionReceipt{
+		BlockHash:       blockHash,
+		BlockNumber:     height,
+		TransactionHash: txHash,
+		Log:             logs,
+	}
+}
+
+func newTestIndex(t *testing.T, dir string) (*Index, internal.Doer) {
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	idx, err := New(context.Background(), qtumClient, dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	return idx, mockedClientDoer
+}
+
+func mustAddResponse(t *testing.T, doer internal.Doer, method string, response interface{}) {
+	if err := doer.AddResponse(method, response); err != nil {
+		t.Fatal(err)
+	}
+}
+
+func searchLogs(t *testing.T, idx *Index, from, to int64, addresses []string, topics []qtum.SearchLogsTopic) qtum.SearchLogsResponse {
+	receipts, err := idx.SearchLogs(&qtum.SearchLogsRequest{
+		FromBlock: big.NewInt(from),
+		ToBlock:   big.NewInt(to),
+		Addresses: addresses,
+		Topics:    topics,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	return receipts
+}
+
+func TestIndexSearchLogs(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	idx, doer := newTestIndex(t, dir)
+	defer idx.close()
+
+	if _, err := idx.SearchLogs(&qtum.SearchLogsRequest{FromBlock: big.NewInt(0), ToBlock: big.NewInt(1)}); err != ErrNotIndexed {
+		t.Fatalf("Expected ErrNotIndexed before indexing, got %v", err)
+	}
+
+	mustAddResponse(t, doer, qtum.MethodGetBlockCount, big.NewInt(10))
+	mustAddResponse(t, doer, qtum.MethodSearchLogs, []qtum.TransactionReceipt{
+		testReceipt(3, "03", "a3", qtum.Log{Address: tokenAddress, Topics: []string{transferTopic}}),
+		testReceipt(5, "05", "a5",
+			qtum.Log{Address: otherAddress, Topics: []string{approvalTopic}},
+			qtum.Log{Address: tokenAddress, Topics: []string{approvalTopic, transferTopic}},
+		),
+		testReceipt(8, "08", "a8", qtum.Log{Address: otherAddress, Topics: []string{transferTopic}}),
+	})
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "10")
+
+	caughtUp, err := idx.sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if !caughtUp {
+		t.Fatal("Expected index to be caught up")
+	}
+
+	if receipts := searchLogs(t, idx, 0, 10, nil, nil); len(receipts) != 3 {
+		t.Fatalf("Expected 3 receipts, got %d", len(receipts))
+	}
+
+	receipts := searchLogs(t, idx, 0, 10, []string{"0x" + tokenAddress}, nil)
+	if len(receipts) != 2 || receipts[0].BlockNumber != 3 || receipts[1].BlockNumber != 5 {
+		t.Fatalf("Unexpected receipts when filtering by address: %+v", receipts)
+	}
+	if len(receipts[1].Log) != 1 || receipts[1].Log[0].Index != 1 {
+		t.Fatalf("Expected only the second log of block 5, got %+v", receipts[1].Log)
+	}
+
+	receipts = searchLogs(t, idx, 4, 10, nil, []qtum.SearchLogsTopic{{transferTopic}})
+	if len(receipts) != 1 || receipts[0].BlockNumber != 8 {
+		t.Fatalf("Unexpected receipts when filtering by topic: %+v", receipts)
+	}
+
+	receipts = searchLogs(t, idx, 0, 10, []string{otherAddress}, []qtum.SearchLogsTopic{{approvalTopic, transferTopic}})
+	if len(receipts) != 2 {
+		t.Fatalf("Unexpected receipts when filtering by address and topic alternatives: %+v", receipts)
+	}
+
+	if _, err := idx.SearchLogs(&qtum.SearchLogsRequest{FromBlock: big.NewInt(0), ToBlock: big.NewInt(11)}); err != ErrNotIndexed {
+		t.Fatalf("Expected ErrNotIndexed past the indexed head, got %v", err)
+	}
+}
+
+func TestIndexReorgAndReopen(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	idx, doer := newTestIndex(t, dir)
+
+	mustAddResponse(t, doer, qtum.MethodGetBlockCount, big.NewInt(10))
+	mustAddResponse(t, doer, qtum.MethodSearchLogs, []qtum.TransactionReceipt{
+		testReceipt(5, "05", "a5", qtum.Log{Address: tokenAddress, Topics: []string{transferTopic}}),
+		testReceipt(8, "08", "a8", qtum.Log{Address: tokenAddress, Topics: []string{transferTopic}}),
+	})
+	// block 10 is checked before and after searchlogs
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "10")
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "10")
+	// blocks 6 to 10 are then replaced: the hashes of blocks 10 and 8 changed, block 5 is still part of the chain
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "10b")
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "08b")
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "05")
+
+	if _, err := idx.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if err := idx.handleReorg(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+
+	if head, _ := idx.Head(); head != 5 {
+		t.Fatalf("Expected head to be reverted to 5, got %d", head)
+	}
+	if receipts := searchLogs(t, idx, 0, 5, nil, nil); len(receipts) != 1 {
+		t.Fatalf("Expected 1 receipt after the reorg, got %d", len(receipts))
+	}
+
+	idx.close()
+	reopened, _ := newTestIndex(t, dir)
+	defer reopened.close()
+
+	if head, indexed := reopened.Head(); !indexed || head != 5 {
+		t.Fatalf("Expected reopened index head to be 5, got %d (indexed: %v)", head, indexed)
+	}
+	receipts := searchLogs(t, reopened, 0, 5, []string{tokenAddress}, nil)
+	if len(receipts) != 1 || receipts[0].TransactionHash != "a5" {
+		t.Fatalf("Unexpected receipts after reopening: %+v", receipts)
+	}
+	if _, err := reopened.SearchLogs(&qtum.SearchLogsRequest{FromBlock: big.NewInt(0), ToBlock: big.NewInt(8)}); err != ErrNotIndexed {
+		t.Fatalf("Expected reverted blocks not to be indexed, got %v", err)
+	}
+}
+
+func TestIndexSyncSkipsBatchChangedDuringSearch(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	idx, doer := newTestIndex(t, dir)
+	defer idx.close()
+
+	mustAddResponse(t, doer, qtum.MethodGetBlockCount, big.NewInt(10))
+	mustAddResponse(t, doer, qtum.MethodSearchLogs, []qtum.TransactionReceipt{
+		testReceipt(8, "08", "a8", qtum.Log{Address: tokenAddress, Topics: []string{transferTopic}}),
+	})
+	// block 10 is replaced while searchlogs runs
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "10")
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "10b")
+
+	caughtUp, err := idx.sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caughtUp {
+		t.Fatal("Expected the batch to be retried")
+	}
+	if _, indexed := idx.Head(); indexed {
+		t.Fatal("Expected the batch not to be recorded")
+	}
+}
+
+func TestIndexSyncSkipsBatchOnOrphanedHead(t *testing.T) {
+	dir, err := ioutil.TempDir("", "logindex")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+
+	idx, doer := newTestIndex(t, dir)
+	defer idx.close()
+
+	mustAddResponse(t, doer, qtum.MethodGetBlockCount, big.NewInt(10))
+	mustAddResponse(t, doer, qtum.MethodGetBlockCount, big.NewInt(12))
+	mustAddResponse(t, doer, qtum.MethodSearchLogs, []qtum.TransactionReceipt{
+		testReceipt(8, "08", "a8", qtum.Log{Address: tokenAddress, Topics: []string{transferTopic}}),
+	})
+	mustAddResponse(t, doer, qtum.MethodSearchLogs, []qtum.TransactionReceipt{})
+	// block 10 is checked before and after the first searchlogs
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "10")
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "10")
+	// the second sync finds block 10 in the chain, then block 12 is the same around searchlogs,
+	// but block 10 is replaced meanwhile
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "10")
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "12")
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "12")
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "10b")
+	// the third sync drops the orphaned blocks, block 8 is still part of the chain
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "10b")
+	mustAddResponse(t, doer, qtum.MethodGetBlockHash, "08")
+
+	if _, err := idx.sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	caughtUp, err := idx.sync(context.Background())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if caughtUp {
+		t.Fatal("Expected the batch to be retried")
+	}
+	if head, _ := idx.Head(); head != 10 {
+		t.Fatalf("Expected the batch on top of the orphaned head not to be recorded, got head %d", head)
+	}
+
+	if err := idx.handleReorg(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	if head, _ := idx.Head(); head != 8 {
+		t.Fatalf("Expected head to be reverted to 8, got %d", head)
+	}
+}
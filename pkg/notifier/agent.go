@@ -2,7 +2,6 @@ package notifier
 
 import (
 	"context"
-	"encoding/json"
 	"fmt"
 	"strings"
 	"sync"
@@ -10,9 +9,9 @@ import (
 
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/blockhash"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
-	"github.com/qtumproject/janus/pkg/utils"
 )
 
 var agentConfigNewHeadsKey = "newHeadsInterval"
@@ -47,6 +46,8 @@ func newAgentWithConfiguration(ctx context.Context, qtum *qtum.Qtum, transformer
 		newPendingTxs: newSubscriptionRegistry(),
 		syncing:       newSubscriptionRegistry(),
 	}
+	agent.mempool = newMempoolWatcher(qtum)
+	agent.syncingStatus = newSyncingWatcher(qtum)
 
 	go agent.run()
 	return agent
@@ -86,27 +87,28 @@ func (s *subscriptionRegistry) Count() int {
 	return s.subscriptionCount
 }
 
-func (s *subscriptionRegistry) SendAll(message interface{}) {
+// SendAll sends the messages, in order, to every subscription in the registry
+func (s *subscriptionRegistry) SendAll(messages ...interface{}) {
 	send := func(s *subscriptionInformation) {
 		// send writes to a queue that can block when full if a client has a lot of responses queued up
 		// that could potentially affect other clients so we run this in a goroutine
-		// params := struct {
-		// 	Subscription string      `json:"subscription"`
-		// 	Result       interface{} `json:"result"`
-		// }{
-		// 	Subscription: s.Subscription.id,
-		// 	Result:       message,
-		// }
-		params := eth.EthSubscriptionParams{
-			SubscriptionID: s.Subscription.id,
-			Result:         message,
-		}
-		subscription := &eth.EthSubscription{
-			Version: "2.0",
-			Method:  "eth_subscription",
-			Params:  params,
+		subscriptions := make([]*eth.EthSubscription, 0, len(messages))
+		for _, message := range messages {
+			params := eth.EthSubscriptionParams{
+				SubscriptionID: s.Subscription.id,
+				Result:         message,
+			}
+			subscriptions = append(subscriptions, &eth.EthSubscription{
+				Version: "2.0",
+				Method:  "eth_subscription",
+				Params:  params,
+			})
 		}
-		go s.Send(subscription)
+		go func() {
+			for _, subscription := range subscriptions {
+				s.Send(subscription)
+			}
+		}()
 	}
 	s.forEach(send)
 }
@@ -123,6 +125,10 @@ type Agent struct {
 	logs          *subscriptionRegistry
 	newPendingTxs *subscriptionRegistry
 	syncing       *subscriptionRegistry
+	mempool       *mempoolWatcher
+	syncingStatus *syncingWatcher
+	// when set, logs are sent with Ethereum block hashes
+	blockHashes *blockhash.Store
 }
 
 func (a *Agent) SetTransformer(transformer Transformer) {
@@ -131,6 +137,13 @@ func (a *Agent) SetTransformer(transformer Transformer) {
 	a.mutex.Unlock()
 }
 
+// SetEthereumBlockHashes makes log subscriptions send the Ethereum hashes of blocks kept in store instead of Qtum block hashes
+func (a *Agent) SetEthereumBlockHashes(store *blockhash.Store) {
+	a.mutex.Lock()
+	a.blockHashes = store
+	a.mutex.Unlock()
+}
+
 func (a *Agent) Stop() {
 	a.mutex.Lock()
 	a.lockAllRegistries(false)
@@ -247,6 +260,15 @@ func (a *Agent) NewSubscription(notifier *Notifier, params *eth.EthSubscriptionR
 
 	wrappedContext, cancel := context.WithCancel(notifier.Context())
 
+	a.mutex.RLock()
+	blockHashes := a.blockHashes
+	a.mutex.RUnlock()
+
+	reorgCheckInterval, ok := a.getConfigValue(agentConfigLogsReorgKey, agentConfigLogsReorgInterval).(time.Duration)
+	if !ok {
+		panic(fmt.Sprintf("Unexpected %s type", agentConfigLogsReorgKey))
+	}
+
 	wrappedSubscription := &subscriptionInformation{
 		subscription,
 		params,
@@ -255,6 +277,8 @@ func (a *Agent) NewSubscription(notifier *Notifier, params *eth.EthSubscriptionR
 		cancel,
 		false,
 		a.qtum,
+		blockHashes,
+		reorgCheckInterval,
 	}
 
 	switch strings.ToLower(params.Method) {
@@ -264,8 +288,10 @@ func (a *Agent) NewSubscription(notifier *Notifier, params *eth.EthSubscriptionR
 		addSubscription(wrappedSubscription, a.newHeads)
 	case "newpendingtransactions":
 		addSubscription(wrappedSubscription, a.newPendingTxs)
+		a.startPendingTransactions()
 	case "syncing":
 		addSubscription(wrappedSubscription, a.syncing)
+		a.startSyncing()
 	default:
 		return "", errors.New(fmt.Sprintf("Unknown subscription type %s", params.Method))
 	}
@@ -310,7 +336,7 @@ func (a *Agent) run() {
 		a.running = false
 	}()
 
-	lastBlock := int64(0)
+	heads := newHeadTracker(a.qtum)
 
 	draining := true
 	for draining {
@@ -346,46 +372,17 @@ func (a *Agent) run() {
 		if transformer == nil {
 			a.qtum.GetErrorLogger().Log("msg", "Agent does not have access to eth transformer, cannot process 'newHeads' subscriptions")
 		} else {
-			blockchainInfo, err := a.qtum.GetBlockChainInfo(a.ctx)
+			headers, err := heads.poll(a.ctx, transformer)
 			if err != nil {
-				a.qtum.GetErrorLogger().Log("msg", "Failure getting blockchaininfo", "err", err)
-			} else {
-				latestBlock := blockchainInfo.Blocks
-				if lastBlock == 0 {
-					// prevent sending the current head to the first client connected
-					lastBlock = latestBlock
-					a.qtum.GetDebugLogger().Log("msg", "Got getblockchaininfo response for same block", "block", lastBlock)
-				} else if latestBlock > lastBlock {
-					a.qtum.GetDebugLogger().Log("msg", "New head detected", "block", latestBlock)
-					// get the latest block as an eth_getBlockByHash request
-					params, err := json.Marshal([]interface{}{
-						utils.AddHexPrefix(blockchainInfo.Bestblockhash),
-						false,
-					})
-					if err != nil {
-						panic(fmt.Sprintf("Failed to serialize eth_getBlockByHash request parameters: %s", err))
-					}
-					result, jsonErr := transformer.Transform(&eth.JSONRPCRequest{
-						JSONRPC: "2.0",
-						Method:  "eth_getBlockByHash",
-						Params:  params,
-					}, nil)
-					if jsonErr != nil {
-						a.qtum.GetErrorLogger().Log("msg", "Failed to eth_getBlockByHash", "hash", blockchainInfo.Bestblockhash, "err", jsonErr)
-					} else {
-						getBlockByHashResponse, ok := result.(*eth.GetBlockByHashResponse)
-						if !ok {
-							a.qtum.GetErrorLogger().Log("msg", "Failed to eth_getBlockByHash, unexpected response type", "hash", blockchainInfo.Bestblockhash)
-						} else {
-							lastBlock = latestBlock
-							// notify newHead
-							newHeadRespose := eth.NewEthSubscriptionNewHeadResponse(getBlockByHashResponse)
-							a.newHeads.SendAll(newHeadRespose)
-						}
-					}
-				} else {
-					a.qtum.GetDebugLogger().Log("msg", "Detected same head", "block", latestBlock)
+				a.qtum.GetErrorLogger().Log("msg", "Failed to poll for new heads", "err", err)
+			}
+			// notify newHeads
+			if len(headers) != 0 {
+				messages := make([]interface{}, 0, len(headers))
+				for _, header := range headers {
+					messages = append(messages, header)
 				}
+				a.newHeads.SendAll(messages...)
 			}
 		}
 
@@ -399,3 +396,109 @@ func (a *Agent) run() {
 		}
 	}
 }
+
+// PendingTransactionsCursor starts watching the mempool for a polling filter and returns the cursor to read new pending transactions from
+func (a *Agent) PendingTransactionsCursor() uint64 {
+	cursor := a.mempool.cursor()
+	a.startPendingTransactions()
+	return cursor
+}
+
+// PendingTransactionsSince returns the hashes of the pending EVM transactions seen after the cursor and the cursor to use next
+func (a *Agent) PendingTransactionsSince(cursor uint64) ([]string, uint64) {
+	hashes, next := a.mempool.since(cursor)
+	a.startPendingTransactions()
+	return hashes, next
+}
+
+func (a *Agent) startPendingTransactions() {
+	a.mempool.mutex.Lock()
+	defer a.mempool.mutex.Unlock()
+	if a.mempool.running {
+		return
+	}
+	a.mempool.running = true
+
+	go a.runPendingTransactions()
+}
+
+// runPendingTransactions polls the mempool while there are newPendingTransactions subscriptions or active polling filters
+func (a *Agent) runPendingTransactions() {
+	pendingTransactionsIntervalValue := a.getConfigValue(agentConfigPendingTransactionsKey, agentConfigPendingTransactionsInterval)
+	pendingTransactionsInterval, ok := pendingTransactionsIntervalValue.(time.Duration)
+	if !ok {
+		panic(fmt.Sprintf("Unexpected %s type", agentConfigPendingTransactionsKey))
+	}
+
+	a.qtum.GetDebugLogger().Log("msg", "Agent started mempool processing thread")
+
+	for {
+		if a.mempool.idle(a.newPendingTxs.Count) {
+			a.qtum.GetDebugLogger().Log("msg", "Agent exited mempool processing thread")
+			return
+		}
+
+		hashes, err := a.mempool.poll(a.ctx)
+		if err != nil {
+			a.qtum.GetErrorLogger().Log("msg", "Failure getting rawmempool", "err", err)
+		} else if len(hashes) != 0 {
+			messages := make([]interface{}, 0, len(hashes))
+			for _, hash := range hashes {
+				messages = append(messages, hash)
+			}
+			a.newPendingTxs.SendAll(messages...)
+		}
+
+		select {
+		case <-time.After(pendingTransactionsInterval):
+			// continue
+		case <-a.ctx.Done():
+			a.mempool.stop()
+			return
+		}
+	}
+}
+
+func (a *Agent) startSyncing() {
+	a.syncingStatus.mutex.Lock()
+	defer a.syncingStatus.mutex.Unlock()
+	if a.syncingStatus.running {
+		return
+	}
+	a.syncingStatus.running = true
+
+	go a.runSyncing()
+}
+
+// runSyncing polls the node's sync status while there are syncing subscriptions
+func (a *Agent) runSyncing() {
+	syncingIntervalValue := a.getConfigValue(agentConfigSyncingKey, agentConfigSyncingInterval)
+	syncingInterval, ok := syncingIntervalValue.(time.Duration)
+	if !ok {
+		panic(fmt.Sprintf("Unexpected %s type", agentConfigSyncingKey))
+	}
+
+	a.qtum.GetDebugLogger().Log("msg", "Agent started syncing processing thread")
+
+	for {
+		if a.syncingStatus.idle(a.syncing.Count) {
+			a.qtum.GetDebugLogger().Log("msg", "Agent exited syncing processing thread")
+			return
+		}
+
+		status, err := a.syncingStatus.poll(a.ctx)
+		if err != nil {
+			a.qtum.GetErrorLogger().Log("msg", "Failure getting blockchaininfo", "err", err)
+		} else if status != nil {
+			a.syncing.SendAll(status)
+		}
+
+		select {
+		case <-time.After(syncingInterval):
+			// continue
+		case <-a.ctx.Done():
+			a.syncingStatus.stop()
+			return
+		}
+	}
+}
@@ -1,10 +1,14 @@
 package notifier
 
 import (
+	"bytes"
 	"context"
 	"encoding/json"
 	"fmt"
+	"io/ioutil"
+	"net/http"
 	"os"
+	"strings"
 	"sync"
 	"testing"
 	"time"
@@ -13,6 +17,7 @@ import (
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/internal"
 	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
 )
 
 func TestAgentAddSubscriptionLogs(t *testing.T) {
@@ -20,7 +25,7 @@ func TestAgentAddSubscriptionLogs(t *testing.T) {
 	defer cancel()
 
 	expectedSubscriptionID := "0x08e2af779d38a09e4c11442d9de22413"
-	want := `{"subscription":"` + expectedSubscriptionID + `","result":{"address":"0x0000000000000000000000000000000000000000","blockHash":"0xbba11e1bacc69ba535d478cf1f2e542da3735a517b0b8eebaf7e6bb25eeb48c5","blockNumber":"0xf8f","data":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","logIndex":"0x0","topics":["0xd8d7ecc4800d25fa53ce0372f13a416d98907a7ef3d8d3bdd79cf4fe75529c65"],"transactionHash":"0x11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5","transactionIndex":"0x2"},"params":{"result":null,"subscription":"` + expectedSubscriptionID + `"}}`
+	want := `{"subscription":"` + expectedSubscriptionID + `","result":{"address":"0x0000000000000000000000000000000000000000","blockHash":"0xbba11e1bacc69ba535d478cf1f2e542da3735a517b0b8eebaf7e6bb25eeb48c5","blockNumber":"0xf8f","data":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","logIndex":"0x0","removed":false,"topics":["0xd8d7ecc4800d25fa53ce0372f13a416d98907a7ef3d8d3bdd79cf4fe75529c65"],"transactionHash":"0x11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5","transactionIndex":"0x2"},"params":{"result":null,"subscription":"` + expectedSubscriptionID + `"}}`
 
 	doer := internal.NewDoerMappedMock()
 	topic1 := "d8d7ecc4800d25fa53ce0372f13a416d98907a7ef3d8d3bdd79cf4fe75529c65"
@@ -173,13 +178,225 @@ func TestAgentAddSubscriptionLogs(t *testing.T) {
 	}
 }
 
+func TestAgentSubscriptionLogsReorg(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	doer := internal.NewDoerMappedMock()
+	topic1 := "d8d7ecc4800d25fa53ce0372f13a416d98907a7ef3d8d3bdd79cf4fe75529c65"
+	qtumLog := qtum.Log{
+		Address: internal.QtumTransactionReceipt(nil).ContractAddress,
+		Topics:  []string{topic1},
+		Data:    "0000000000000000000000000000000000000000000000000000000000000001",
+	}
+
+	doer.AddResponse(qtum.MethodWaitForLogs, qtum.WaitForLogsResponse{
+		Entries:   []qtum.WaitForLogsEntry{internal.QtumWaitForLogsEntry(qtumLog)},
+		Count:     1,
+		NextBlock: internal.QtumTransactionReceipt(nil).BlockNumber + 1,
+	})
+
+	// the block the log was first seen in is replaced by one including the same transaction
+	// and a new one
+	orphanedReceipt := internal.QtumTransactionReceipt([]qtum.Log{qtumLog})
+	newBlockHash := "0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be"
+	reincludedReceipt := internal.QtumTransactionReceipt([]qtum.Log{qtumLog})
+	reincludedReceipt.BlockHash = newBlockHash
+	newReceipt := internal.QtumTransactionReceipt([]qtum.Log{qtumLog})
+	newReceipt.BlockHash = newBlockHash
+	newReceipt.TransactionHash = "0x6ee7b0ea9b8e5db7b1bcbd5e53a3ba1e93bd0327ec9cc1d84fcf4e8f72da1ea4"
+	doer.AddResponse(qtum.MethodSearchLogs, qtum.SearchLogsResponse{orphanedReceipt})
+	doer.AddResponse(qtum.MethodSearchLogs, qtum.SearchLogsResponse{reincludedReceipt, newReceipt})
+	doer.AddResponse(qtum.MethodGetBlockHash, qtum.GetBlockHashResponse(newBlockHash[2:]))
+
+	mockedClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agent := NewAgent(ctx, mockedClient, nil)
+
+	notifierContext, cancelNotifierContext := context.WithCancel(ctx)
+
+	sentValuesChannel := make(chan []byte, 10)
+	send := func(v []byte) error {
+		sentValuesChannel <- v
+		return nil
+	}
+
+	notifier := NewNotifier(notifierContext, cancelNotifierContext, send, log.NewLogfmtLogger(os.Stdout))
+
+	id, err := agent.NewSubscription(notifier, &eth.EthSubscriptionRequest{
+		Method: "logs",
+		Params: &eth.EthLogSubscriptionParameter{
+			Address: internal.QtumTransactionReceipt(nil).ContractAddress,
+			Topics: []interface{}{
+				topic1,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer notifier.Unsubscribe(id)
+
+	notifier.ResponseSent()
+
+	type sentLog struct {
+		blockHash       string
+		transactionHash string
+		removed         bool
+	}
+	want := []sentLog{
+		{internal.GetTransactionByHashBlockHexHash, utils.AddHexPrefix(orphanedReceipt.TransactionHash), false},
+		{internal.GetTransactionByHashBlockHexHash, utils.AddHexPrefix(orphanedReceipt.TransactionHash), true},
+		{newBlockHash, utils.AddHexPrefix(orphanedReceipt.TransactionHash), false},
+		{newBlockHash, newReceipt.TransactionHash, false},
+	}
+
+	for i, expected := range want {
+		select {
+		case sent := <-sentValuesChannel:
+			var notification eth.JSONRPCNotification
+			if err := json.Unmarshal(sent, &notification); err != nil {
+				t.Fatal(err)
+			}
+			var subscription struct {
+				Result eth.Log `json:"result"`
+			}
+			if err := json.Unmarshal(notification.Params, &subscription); err != nil {
+				t.Fatal(err)
+			}
+			got := sentLog{subscription.Result.BlockHash, subscription.Result.TransactionHash, subscription.Result.Removed}
+			if got != expected {
+				t.Fatalf("unexpected notification %d\nwant: %+v\ngot: %+v", i, expected, got)
+			}
+		case <-time.After(500 * time.Millisecond):
+			t.Fatalf("Timed out waiting for notification %d", i)
+		}
+	}
+
+	select {
+	case sent := <-sentValuesChannel:
+		t.Fatalf("Logs should only be sent once: %s", string(sent))
+	case <-time.After(300 * time.Millisecond):
+	}
+}
+
+// blockingWaitForLogsDoer blocks every waitforlogs call after the first until it is cancelled,
+// like qtumd does while no new logs are found
+type blockingWaitForLogsDoer struct {
+	internal.Doer
+	mutex sync.Mutex
+	calls int
+}
+
+func (d *blockingWaitForLogsDoer) Do(request *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	if strings.Contains(string(body), `"`+qtum.MethodWaitForLogs+`"`) {
+		d.mutex.Lock()
+		d.calls++
+		calls := d.calls
+		d.mutex.Unlock()
+		if calls > 1 {
+			<-request.Context().Done()
+			return nil, request.Context().Err()
+		}
+	}
+
+	return d.Doer.Do(request)
+}
+
+func TestAgentSubscriptionLogsRemovedWithoutNewLogs(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	doer := &blockingWaitForLogsDoer{Doer: internal.NewDoerMappedMock()}
+	topic1 := "d8d7ecc4800d25fa53ce0372f13a416d98907a7ef3d8d3bdd79cf4fe75529c65"
+	qtumLog := qtum.Log{
+		Address: internal.QtumTransactionReceipt(nil).ContractAddress,
+		Topics:  []string{topic1},
+		Data:    "0000000000000000000000000000000000000000000000000000000000000001",
+	}
+
+	doer.AddResponse(qtum.MethodWaitForLogs, qtum.WaitForLogsResponse{
+		Entries:   []qtum.WaitForLogsEntry{internal.QtumWaitForLogsEntry(qtumLog)},
+		Count:     1,
+		NextBlock: internal.QtumTransactionReceipt(nil).BlockNumber + 1,
+	})
+	orphanedReceipt := internal.QtumTransactionReceipt([]qtum.Log{qtumLog})
+	doer.AddResponse(qtum.MethodSearchLogs, qtum.SearchLogsResponse{orphanedReceipt})
+	// the block is replaced by one without logs, so waitforlogs doesn't return again
+	doer.AddResponse(qtum.MethodGetBlockHash, qtum.GetBlockHashResponse("6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be"))
+
+	mockedClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentTestConfig := make(map[string]interface{})
+	// check for reorgs quicker for unit tests
+	agentTestConfig[agentConfigLogsReorgKey] = 50 * time.Millisecond
+	agent := newAgentWithConfiguration(ctx, mockedClient, nil, agentTestConfig)
+
+	notifierContext, cancelNotifierContext := context.WithCancel(ctx)
+
+	sentValuesChannel := make(chan []byte, 10)
+	send := func(v []byte) error {
+		sentValuesChannel <- v
+		return nil
+	}
+
+	notifier := NewNotifier(notifierContext, cancelNotifierContext, send, log.NewLogfmtLogger(os.Stdout))
+
+	id, err := agent.NewSubscription(notifier, &eth.EthSubscriptionRequest{
+		Method: "logs",
+		Params: &eth.EthLogSubscriptionParameter{
+			Address: internal.QtumTransactionReceipt(nil).ContractAddress,
+			Topics: []interface{}{
+				topic1,
+			},
+		},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer notifier.Unsubscribe(id)
+
+	notifier.ResponseSent()
+
+	for i, removed := range []bool{false, true} {
+		select {
+		case sent := <-sentValuesChannel:
+			var notification eth.JSONRPCNotification
+			if err := json.Unmarshal(sent, &notification); err != nil {
+				t.Fatal(err)
+			}
+			var subscription struct {
+				Result eth.Log `json:"result"`
+			}
+			if err := json.Unmarshal(notification.Params, &subscription); err != nil {
+				t.Fatal(err)
+			}
+			if subscription.Result.Removed != removed {
+				t.Fatalf("unexpected notification %d: %s", i, string(sent))
+			}
+		case <-time.After(500 * time.Millisecond):
+			t.Fatalf("Timed out waiting for notification %d", i)
+		}
+	}
+}
+
 func TestAgentAddSubscriptionNewHeads(t *testing.T) {
 	ctx, cancel := context.WithCancel(context.Background())
 	defer cancel()
 
 	expectedSubscriptionID := "0x08e2af779d38a09e4c11442d9de22413"
 	// want := `{"subscription":"` + expectedSubscriptionID + `","result":{"difficulty":"0x4","extraData":"0x0000000000000000000000000000000000000000000000000000000000000000","gasLimit":"0x2625A00","gasUsed":"0x0","logsBloom":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","miner":"0x0000000000000000000000000000000000000000","nonce":"0x0000000000000000","number":"0xf8f","parentHash":"0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be","receiptRoot":"0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334","sha3Uncles":"0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347","stateRoot":"","timestamp":"0x5b95ebd0","transactionsRoot":"0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334"}}`
-	want := `{"subscription":"` + expectedSubscriptionID + `","result":null,"params":{"result":{"difficulty":"0x4","extraData":"0x0000000000000000000000000000000000000000000000000000000000000000","gasLimit":"0x2625A00","gasUsed":"0x0","hash":"0xbba11e1bacc69ba535d478cf1f2e542da3735a517b0b8eebaf7e6bb25eeb48c5","logsBloom":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","miner":"0x0000000000000000000000000000000000000000","mixHash":"0x0000000000000000000000000000000000000000000000000000000000000000","nonce":"0x0000000000000000","number":"0xf8f","parentHash":"0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be","receiptsRoot":"0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334","sha3Uncles":"0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347","stateRoot":"0x3e49216e58f1ad9e6823b5095dc532f0a6cc44943d36ff4a7b1aa474e172d672","timestamp":"0x5b95ebd0","transactionsRoot":"0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334"},"subscription":"` + expectedSubscriptionID + `"},"jsonrpc":"2.0","method":"eth_subscription"}`
+	want := `{"subscription":"` + expectedSubscriptionID + `","result":null,"params":{"result":{"baseFeePerGas":"0x5d21dba000","difficulty":"0x4","extraData":"0x0000000000000000000000000000000000000000000000000000000000000000","gasLimit":"0x2625A00","gasUsed":"0x0","hash":"0xbba11e1bacc69ba535d478cf1f2e542da3735a517b0b8eebaf7e6bb25eeb48c5","logsBloom":"0x00000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000000","miner":"0x7926223070547d2d15b2ef5e7383e541c338ffe9","mixHash":"0x0000000000000000000000000000000000000000000000000000000000000000","nonce":"0x0000000000000000","number":"0xf8f","parentHash":"0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be","receiptsRoot":"0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334","sha3Uncles":"0x1dcc4de8dec75d7aab85b567b6ccd41ad312451b948a7413f0a142fd40d49347","stateRoot":"0x3e49216e58f1ad9e6823b5095dc532f0a6cc44943d36ff4a7b1aa474e172d672","timestamp":"0x5b95ebd0","transactionsRoot":"0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334"},"subscription":"` + expectedSubscriptionID + `"},"jsonrpc":"2.0","method":"eth_subscription"}`
 
 	doer := internal.NewDoerMappedMock()
 
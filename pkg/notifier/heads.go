@@ -0,0 +1,157 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"math"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+// number of recently announced blocks remembered to find where a new chain forked off
+const headTrackerMaxBlocks = 100
+
+// headTracker polls the chain tip on behalf of every newHeads subscription
+// new blocks, and every block of a new chain on a reorg, are fetched once and sent to all subscribers
+type headTracker struct {
+	qtum *qtum.Qtum
+	// tip as reported by getblockchaininfo
+	tipHeight int64
+	tipHash   string
+	// hashes of recently announced blocks by height
+	announced map[uint64]string
+}
+
+func newHeadTracker(qtum *qtum.Qtum) *headTracker {
+	return &headTracker{
+		qtum:      qtum,
+		announced: make(map[uint64]string),
+	}
+}
+
+// poll returns the headers of the blocks that became part of the chain since the last call, in ascending order
+// the first call only records the current tip so that it isn't sent to the first client connected
+func (h *headTracker) poll(ctx context.Context, transformer Transformer) ([]*eth.EthSubscriptionNewHeadResponse, error) {
+	blockchainInfo, err := h.qtum.GetBlockChainInfo(ctx)
+	if err != nil {
+		return nil, errors.WithMessage(err, "Failure getting blockchaininfo")
+	}
+
+	tipHash := utils.RemoveHexPrefix(blockchainInfo.Bestblockhash)
+	if h.tipHash == "" {
+		// the tip is recorded with the hash the transformer serves, the Ethereum hash with --eth-block-hashes,
+		// for the parent hash of the next block to match it
+		block, err := getBlockByHash(transformer, tipHash)
+		if err != nil {
+			return nil, err
+		}
+		h.tipHeight = blockchainInfo.Blocks
+		h.tipHash = tipHash
+		h.announce(uint64(blockchainInfo.Blocks), block.Hash)
+		h.qtum.GetDebugLogger().Log("msg", "Got getblockchaininfo response for same block", "block", h.tipHeight)
+		return nil, nil
+	}
+
+	if blockchainInfo.Blocks == h.tipHeight && tipHash == h.tipHash {
+		h.qtum.GetDebugLogger().Log("msg", "Detected same head", "block", h.tipHeight)
+		return nil, nil
+	}
+
+	h.qtum.GetDebugLogger().Log("msg", "New head detected", "block", blockchainInfo.Blocks)
+
+	// walk back from the new tip until reaching a block that was already announced, the tip can advance
+	// by several blocks between polls so every block above the last announced one is sent
+	lowest := h.lowestAnnounced()
+	blocks := []*eth.GetBlockByHashResponse{}
+	hash := tipHash
+	for len(blocks) < headTrackerMaxBlocks {
+		block, err := getBlockByHash(transformer, hash)
+		if err != nil {
+			return nil, err
+		}
+		height, err := hexutil.DecodeUint64(block.Number)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid block number %s", block.Number)
+		}
+
+		if h.announced[height] == utils.RemoveHexPrefix(block.Hash) {
+			break
+		}
+		blocks = append(blocks, block)
+
+		// stop below the blocks that are remembered, there is nothing to compare the parent with
+		if height == 0 || height-1 < lowest {
+			break
+		}
+		parent, known := h.announced[height-1]
+		if known && parent == utils.RemoveHexPrefix(block.ParentHash) {
+			break
+		}
+		if known {
+			h.qtum.GetDebugLogger().Log("msg", "Reorg detected, announcing the block's parent", "block", height)
+		}
+		hash = utils.RemoveHexPrefix(block.ParentHash)
+	}
+
+	h.tipHeight = blockchainInfo.Blocks
+	h.tipHash = tipHash
+
+	headers := make([]*eth.EthSubscriptionNewHeadResponse, 0, len(blocks))
+	for i := len(blocks) - 1; i >= 0; i-- {
+		height, _ := hexutil.DecodeUint64(blocks[i].Number)
+		h.announce(height, blocks[i].Hash)
+		headers = append(headers, eth.NewEthSubscriptionNewHeadResponse(blocks[i]))
+	}
+
+	return headers, nil
+}
+
+// announce records the hash of a sent block, forgetting blocks above it as they are no longer part of the chain
+func (h *headTracker) announce(height uint64, hash string) {
+	for announcedHeight := range h.announced {
+		if announcedHeight > height || announcedHeight+headTrackerMaxBlocks <= height {
+			delete(h.announced, announcedHeight)
+		}
+	}
+	h.announced[height] = utils.RemoveHexPrefix(hash)
+}
+
+// lowestAnnounced returns the lowest remembered block height
+func (h *headTracker) lowestAnnounced() uint64 {
+	lowest := uint64(math.MaxUint64)
+	for height := range h.announced {
+		if height < lowest {
+			lowest = height
+		}
+	}
+	return lowest
+}
+
+func getBlockByHash(transformer Transformer, hash string) (*eth.GetBlockByHashResponse, error) {
+	// get the block as an eth_getBlockByHash request
+	params, err := json.Marshal([]interface{}{
+		utils.AddHexPrefix(hash),
+		false,
+	})
+	if err != nil {
+		panic(fmt.Sprintf("Failed to serialize eth_getBlockByHash request parameters: %s", err))
+	}
+	result, jsonErr := transformer.Transform(&eth.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  "eth_getBlockByHash",
+		Params:  params,
+	}, nil)
+	if jsonErr != nil {
+		return nil, errors.Errorf("Failed to eth_getBlockByHash %s: %s", hash, jsonErr.Message())
+	}
+	block, ok := result.(*eth.GetBlockByHashResponse)
+	if !ok || block == nil {
+		return nil, errors.Errorf("Failed to eth_getBlockByHash %s, unexpected response type", hash)
+	}
+	return block, nil
+}
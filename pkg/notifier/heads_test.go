@@ -0,0 +1,183 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+// answers eth_getBlockByHash from a map of blocks by hash
+type blocksTransformer map[string]*eth.GetBlockByHashResponse
+
+func (t blocksTransformer) Transform(req *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
+	var params []interface{}
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		return nil, eth.NewInvalidParamsError(err.Error())
+	}
+	block, ok := t[params[0].(string)]
+	if !ok {
+		return nil, eth.NewCallbackError("unknown block")
+	}
+	return block, nil
+}
+
+func (t blocksTransformer) add(height uint64, hash string, parentHash string) {
+	t["0x"+hash] = &eth.GetBlockByHashResponse{
+		Number:     hexutil.EncodeUint64(height),
+		Hash:       "0x" + hash,
+		ParentHash: "0x" + parentHash,
+	}
+}
+
+func TestHeadTrackerReorg(t *testing.T) {
+	doer := internal.NewDoerMappedMock()
+	tips := []qtum.GetBlockChainInfoResponse{
+		{Blocks: 100, Bestblockhash: "a100"},
+		{Blocks: 101, Bestblockhash: "a101"},
+		{Blocks: 101, Bestblockhash: "a101"},
+		{Blocks: 102, Bestblockhash: "a102"},
+		// blocks 101 and 102 get replaced
+		{Blocks: 102, Bestblockhash: "b102"},
+		{Blocks: 103, Bestblockhash: "b103"},
+	}
+	for _, tip := range tips {
+		if err := doer.AddResponse(qtum.MethodGetBlockChainInfo, tip); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	transformer := blocksTransformer{}
+	transformer.add(100, "a100", "a099")
+	transformer.add(101, "a101", "a100")
+	transformer.add(102, "a102", "a101")
+	transformer.add(101, "b101", "a100")
+	transformer.add(102, "b102", "b101")
+	transformer.add(103, "b103", "b102")
+
+	qtumClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	heads := newHeadTracker(qtumClient)
+
+	want := [][]string{
+		// the current head isn't sent on the first poll
+		{},
+		{"0xa101"},
+		{},
+		{"0xa102"},
+		{"0xb101", "0xb102"},
+		{"0xb103"},
+	}
+
+	for i, wantHashes := range want {
+		headers, err := heads.poll(context.Background(), transformer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(headers) != len(wantHashes) {
+			t.Fatalf("poll %d: expected %d headers, got %d", i, len(wantHashes), len(headers))
+		}
+		for j, header := range headers {
+			if header.Hash != wantHashes[j] {
+				t.Fatalf("poll %d: expected header %d to be %s, got %s", i, j, wantHashes[j], header.Hash)
+			}
+		}
+	}
+}
+
+func TestHeadTrackerSkippedBlocks(t *testing.T) {
+	doer := internal.NewDoerMappedMock()
+	tips := []qtum.GetBlockChainInfoResponse{
+		{Blocks: 100, Bestblockhash: "a100"},
+		// several blocks are mined between polls
+		{Blocks: 103, Bestblockhash: "a103"},
+		// and on a reorg replacing the last announced block
+		{Blocks: 105, Bestblockhash: "b105"},
+	}
+	for _, tip := range tips {
+		if err := doer.AddResponse(qtum.MethodGetBlockChainInfo, tip); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	transformer := blocksTransformer{}
+	transformer.add(100, "a100", "a099")
+	transformer.add(101, "a101", "a100")
+	transformer.add(102, "a102", "a101")
+	transformer.add(103, "a103", "a102")
+	transformer.add(103, "b103", "a102")
+	transformer.add(104, "b104", "b103")
+	transformer.add(105, "b105", "b104")
+
+	qtumClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	heads := newHeadTracker(qtumClient)
+
+	want := [][]string{
+		{},
+		{"0xa101", "0xa102", "0xa103"},
+		{"0xb103", "0xb104", "0xb105"},
+	}
+
+	for i, wantHashes := range want {
+		headers, err := heads.poll(context.Background(), transformer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if len(headers) != len(wantHashes) {
+			t.Fatalf("poll %d: expected %d headers, got %d", i, len(wantHashes), len(headers))
+		}
+		for j, header := range headers {
+			if header.Hash != wantHashes[j] {
+				t.Fatalf("poll %d: expected header %d to be %s, got %s", i, j, wantHashes[j], header.Hash)
+			}
+		}
+	}
+}
+
+func TestHeadTrackerEthereumBlockHashes(t *testing.T) {
+	doer := internal.NewDoerMappedMock()
+	tips := []qtum.GetBlockChainInfoResponse{
+		{Blocks: 100, Bestblockhash: "a100"},
+		{Blocks: 101, Bestblockhash: "a101"},
+	}
+	for _, tip := range tips {
+		if err := doer.AddResponse(qtum.MethodGetBlockChainInfo, tip); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	// with --eth-block-hashes blocks are found by their Qtum hash but served with their Ethereum hash
+	transformer := blocksTransformer{}
+	transformer.add(100, "e100", "e099")
+	transformer.add(101, "e101", "e100")
+	transformer["0xa100"] = transformer["0xe100"]
+	transformer["0xa101"] = transformer["0xe101"]
+
+	qtumClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	heads := newHeadTracker(qtumClient)
+
+	if _, err := heads.poll(context.Background(), transformer); err != nil {
+		t.Fatal(err)
+	}
+	headers, err := heads.poll(context.Background(), transformer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the tip recorded by the first poll isn't mistaken for a reorg and announced again
+	if len(headers) != 1 || headers[0].Hash != "0xe101" {
+		t.Fatalf("Expected only block 0xe101 to be announced, got %+v", headers)
+	}
+}
@@ -0,0 +1,131 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+var agentConfigPendingTransactionsKey = "pendingTransactionsInterval"
+var agentConfigPendingTransactionsInterval = 2 * time.Second
+
+// polling filters that haven't asked for changes in this long stop keeping the mempool watcher running
+const pendingTransactionsFilterTimeout = 5 * time.Minute
+
+// maximum number of pending transaction hashes kept around for polling filters
+const pendingTransactionsMaxHashes = 10000
+
+// mempoolWatcher diffs qtumd's mempool to find new EVM transactions
+// they are sent to newPendingTransactions subscriptions and kept around for polling filters,
+// which read them using a cursor
+type mempoolWatcher struct {
+	qtum    *qtum.Qtum
+	mutex   sync.Mutex
+	running bool
+	// transactions that were in the mempool on the last poll
+	known map[string]bool
+	// hashes of recently seen EVM transactions, hashes[i] being the one at cursor offset+i
+	hashes []string
+	offset uint64
+	// last time a polling filter was created or asked for changes
+	lastFilterPoll time.Time
+}
+
+func newMempoolWatcher(qtum *qtum.Qtum) *mempoolWatcher {
+	return &mempoolWatcher{
+		qtum: qtum,
+	}
+}
+
+// cursor returns the position after the latest pending transaction seen
+func (m *mempoolWatcher) cursor() uint64 {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.lastFilterPoll = time.Now()
+	return m.offset + uint64(len(m.hashes))
+}
+
+// since returns the pending transactions seen after the cursor and the cursor to use next
+func (m *mempoolWatcher) since(cursor uint64) ([]string, uint64) {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.lastFilterPoll = time.Now()
+	if cursor < m.offset {
+		// the filter hasn't asked for changes in a while, older hashes have been dropped
+		cursor = m.offset
+	}
+	next := m.offset + uint64(len(m.hashes))
+	if cursor >= next {
+		return []string{}, next
+	}
+
+	hashes := make([]string, next-cursor)
+	copy(hashes, m.hashes[cursor-m.offset:])
+	return hashes, next
+}
+
+// idle reports whether there are no subscriptions nor polling filters that used the watcher recently
+// in which case it is stopped, checking under the lock prevents racing with a new subscription
+func (m *mempoolWatcher) idle(subscriptions func() int) bool {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	if subscriptions() != 0 || time.Since(m.lastFilterPoll) < pendingTransactionsFilterTimeout {
+		return false
+	}
+	m.running = false
+	m.known = nil
+	return true
+}
+
+func (m *mempoolWatcher) stop() {
+	m.mutex.Lock()
+	defer m.mutex.Unlock()
+
+	m.running = false
+	m.known = nil
+}
+
+// poll returns the hashes of the EVM transactions that entered the mempool since the last poll
+// the first poll only records the current mempool
+func (m *mempoolWatcher) poll(ctx context.Context) ([]string, error) {
+	mempool, err := m.qtum.GetRawMempool(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	current := make(map[string]bool, len(mempool))
+	hashes := []string{}
+	for _, txID := range mempool {
+		current[txID] = true
+		if m.known == nil || m.known[txID] {
+			continue
+		}
+
+		tx, err := m.qtum.GetRawTransaction(ctx, txID, false)
+		if err != nil {
+			// the transaction might have been mined or evicted since getrawmempool
+			m.qtum.GetDebugLogger().Log("msg", "Failed to get pending transaction", "hash", txID, "err", err)
+			continue
+		}
+		if tx.IsContractTransaction() {
+			hashes = append(hashes, utils.AddHexPrefix(txID))
+		}
+	}
+	m.known = current
+
+	m.mutex.Lock()
+	m.hashes = append(m.hashes, hashes...)
+	if dropped := len(m.hashes) - pendingTransactionsMaxHashes; dropped > 0 {
+		m.hashes = append([]string{}, m.hashes[dropped:]...)
+		m.offset += uint64(dropped)
+	}
+	m.mutex.Unlock()
+
+	return hashes, nil
+}
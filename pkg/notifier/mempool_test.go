@@ -0,0 +1,96 @@
+package notifier
+
+import (
+	"context"
+	"encoding/json"
+	"os"
+	"testing"
+	"time"
+
+	"github.com/go-kit/kit/log"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestAgentPendingTransactions(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	contractTxID := "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451"
+	paymentTxID := "11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5"
+
+	doer := internal.NewDoerMappedMock()
+	// the transaction already in the mempool when the watcher starts isn't announced
+	doer.AddResponse(qtum.MethodGetRawMempool, qtum.GetRawMempoolResponse{"0a"})
+	doer.AddResponse(qtum.MethodGetRawMempool, qtum.GetRawMempoolResponse{"0a", contractTxID, paymentTxID})
+	doer.AddResponse(qtum.MethodGetRawTransaction, qtum.GetRawTransactionResponse{
+		ID: contractTxID,
+		Vouts: []qtum.RawTransactionVout{
+			{Details: qtum.RawTransactionVoutDetails{Asm: "4 250000 40 a9059cbb 54fefdb5b31164f66ddb68becd7bdd864cacd65b OP_CALL"}},
+		},
+	})
+	doer.AddResponse(qtum.MethodGetRawTransaction, qtum.GetRawTransactionResponse{
+		ID: paymentTxID,
+		Vouts: []qtum.RawTransactionVout{
+			{Details: qtum.RawTransactionVoutDetails{Asm: "OP_DUP OP_HASH160 7926223070547d2d15b2ef5e7383e541c338ffe9 OP_EQUALVERIFY OP_CHECKSIG"}},
+		},
+	})
+
+	mockedClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	agentTestConfig := make(map[string]interface{})
+	agentTestConfig[agentConfigPendingTransactionsKey] = 50 * time.Millisecond
+	agent := newAgentWithConfiguration(ctx, mockedClient, nil, agentTestConfig)
+
+	// polling filter
+	cursor := agent.PendingTransactionsCursor()
+
+	// websocket subscription
+	notifierContext, cancelNotifierContext := context.WithCancel(ctx)
+	sentValuesChannel := make(chan []byte, 10)
+	send := func(v []byte) error {
+		sentValuesChannel <- v
+		return nil
+	}
+	notifier := NewNotifier(notifierContext, cancelNotifierContext, send, log.NewLogfmtLogger(os.Stdout))
+	id, err := agent.NewSubscription(notifier, &eth.EthSubscriptionRequest{
+		Method: "newPendingTransactions",
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer notifier.Unsubscribe(id)
+	notifier.ResponseSent()
+
+	select {
+	case sent := <-sentValuesChannel:
+		var subscription eth.EthSubscription
+		if err := json.Unmarshal(sent, &subscription); err != nil {
+			t.Fatal(err)
+		}
+		if subscription.Params.SubscriptionID != id || subscription.Params.Result != "0x"+contractTxID {
+			t.Fatalf("Unexpected newPendingTransactions notification: %s", string(sent))
+		}
+	case <-time.After(time.Second):
+		t.Fatal("Timed out waiting for pending transaction")
+	}
+
+	hashes, next := agent.PendingTransactionsSince(cursor)
+	if len(hashes) != 1 || hashes[0] != "0x"+contractTxID {
+		t.Fatalf("Expected only the contract transaction to be pending, got %v", hashes)
+	}
+
+	// the mempool doesn't change anymore
+	time.Sleep(150 * time.Millisecond)
+	if hashes, _ := agent.PendingTransactionsSince(next); len(hashes) != 0 {
+		t.Fatalf("Expected no new pending transactions, got %v", hashes)
+	}
+	select {
+	case sent := <-sentValuesChannel:
+		t.Fatalf("Pending transactions should only be sent once: %s", string(sent))
+	default:
+	}
+}
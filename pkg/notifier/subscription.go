@@ -2,8 +2,6 @@ package notifier
 
 import (
 	"context"
-	"crypto/md5"
-	"encoding/json"
 	"fmt"
 	"math"
 	"math/big"
@@ -11,9 +9,12 @@ import (
 	"sync"
 	"time"
 
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/blockhash"
 	"github.com/qtumproject/janus/pkg/conversion"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
 )
 
 type subscriptionInformation struct {
@@ -24,6 +25,10 @@ type subscriptionInformation struct {
 	cancelFunc context.CancelFunc
 	running    bool
 	qtum       *qtum.Qtum
+	// translates the block hashes of sent logs when set
+	blockHashes *blockhash.Store
+	// how often logs subscriptions check for reorgs in the background
+	reorgCheckInterval time.Duration
 }
 
 func (s *subscriptionInformation) run() {
@@ -94,61 +99,79 @@ func (s *subscriptionInformation) run() {
 
 	rolling := newRollingLimit(limitToXApiCalls)
 
-	// duplicate logs are only to be sent on a reorg
-	// the previous log that was sent on the old chain is sent with a `removed: true`
-	// then the new log is sent
-	// that functionality isn't supported in this implementation yet
-	// however, in order to not send duplicate logs we can do that with a simple hash map
-	// each hash is a 128bit MD5 hash, the hashing algorithim doesn't really matter here
-	// as this is only for preventing duplicate logs being sent over a websocket
-	// there are 8000 bits in a kilobyte, thats enough for 62.5 hashes
-	// 1MB = 1024KB = 1024KB/1KB = 1024 * 62.5 = 64,000 hashes
-	// when proving this as a service, that can add up if tens of thousands are using the service
-	// we want to put an upper limit on ram usage for an ip/connection
-	// we could also put an absolute upper limit on ram usage for this feature
-	// TODO: Deal with RAM usage here when Janus gets large enough
-	// some kind of FIFO hashmap?
-	sentHashes := make(map[string]bool)
+	// logs are only sent again on a reorg, the log that was sent on the old chain is sent
+	// with `removed: true` and then the logs of the new chain are sent
+	// to do that we remember the logs sent from the most recent blocks
+	reorgs := &logsReorgs{
+		emitted: newEmittedLogs(emittedLogsMaxBlocks),
+		wake:    make(chan struct{}, 1),
+	}
+	// waitforlogs only returns once there are new logs, so reorgs are also checked for in the background
+	go s.watchReorgs(reorgs)
 
 	failures := 0
 	for {
+		waitCtx, cancelWait := context.WithCancel(s.ctx)
+		reorgs.mutex.Lock()
+		reorgs.cancelWait = cancelWait
+		reorgs.mutex.Unlock()
+
 		req.FromBlock = nextBlock
 		timeBeforeCall := time.Now()
 		rolling.Push(&timeBeforeCall)
-		resp, err := s.qtum.WaitForLogs(s.ctx, req)
+		resp, err := s.qtum.WaitForLogs(waitCtx, req)
 		timeAfterCall := time.Now()
+		cancelWait()
+
+		reorgs.mutex.Lock()
+		// check for reorgs before sending the new logs, so that removed logs are sent first
+		if err := s.checkReorgs(reorgs); err != nil {
+			s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "msg", "Error checking for reorgs", "err", err)
+		}
+
 		if err == nil {
 			nextBlock = int(resp.NextBlock)
+			fromBlock := resp.NextBlock - 1
+			for _, entry := range resp.Entries {
+				if entry.BlockNumber < fromBlock {
+					fromBlock = entry.BlockNumber
+				}
+			}
+			if reorgs.rescanFrom != nil && *reorgs.rescanFrom < fromBlock {
+				fromBlock = *reorgs.rescanFrom
+			}
+			reorgs.rescanFrom = nil
+
 			reqSearchLogs := qtum.SearchLogsRequest{
-				FromBlock: big.NewInt(int64(resp.NextBlock - 1)),
+				FromBlock: new(big.Int).SetUint64(fromBlock),
 				ToBlock:   big.NewInt(int64(resp.NextBlock - 1)),
 				Addresses: *req.Filter.Addresses,
 				Topics:    *req.Filter.Topics,
 			}
 			receiptsSearchLogs, err := s.qtum.SearchLogs(s.ctx, &reqSearchLogs)
 			if err != nil {
+				reorgs.mutex.Unlock()
 				s.qtum.GetErrorLogger().Log("msg", "Error calling searchLogs", "subscriptionId", s.id, "error", err)
 				return
 			}
 			for _, qtumLog := range receiptsSearchLogs {
-				qtumLogs := qtumLog.Log
+				qtumLogs := make([]qtum.Log, len(qtumLog.Log))
+				for i, log := range qtumLog.Log {
+					log.Index = i
+					qtumLogs[i] = log
+				}
 				logs := conversion.FilterQtumLogs(stringAddresses, qtumTopics, qtumLogs)
 				ethLogs := conversion.ExtractETHLogsFromTransactionReceipt(qtumLog, logs)
 				for _, ethLog := range ethLogs {
-					subscription := &eth.EthSubscription{
-						SubscriptionID: s.Subscription.id,
-						Result:         ethLog,
+					if reorgs.emitted.contains(ethLog) {
+						continue
 					}
-					hash := computeHash(subscription)
-					if _, ok := sentHashes[hash]; !ok {
-						sentHashes[hash] = true
-						s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "msg", "notifying of logs")
-						jsonRpcNotification, err := eth.NewJSONRPCNotification("eth_subscription", subscription)
-						if err != nil {
-							s.qtum.GetErrorLogger().Log("subscriptionId", s.id, "err", err)
-							return
-						}
-						s.Send(jsonRpcNotification)
+					reorgs.emitted.add(qtumLog.BlockNumber, ethLog)
+					s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "msg", "notifying of logs")
+					if err := s.notify(ethLog); err != nil {
+						reorgs.mutex.Unlock()
+						s.qtum.GetErrorLogger().Log("subscriptionId", s.id, "err", err)
+						return
 					}
 				}
 			}
@@ -161,10 +184,20 @@ func (s *subscriptionInformation) run() {
 				failures = 0
 			}
 		} else {
-			// error occurred
-			s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "err", err)
-			failures = failures + 1
+			if waitCtx.Err() != nil && s.ctx.Err() == nil {
+				// cancelled as a reorg was detected in the background
+				s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "msg", "waitforlogs interrupted by a reorg")
+			} else {
+				// error occurred
+				s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "err", err)
+				failures = failures + 1
+			}
+			// wait for the logs of the new chain from the fork onwards
+			if next, ok := nextBlock.(int); ok && reorgs.rescanFrom != nil && uint64(next) > *reorgs.rescanFrom {
+				nextBlock = int(*reorgs.rescanFrom)
+			}
 		}
+		reorgs.mutex.Unlock()
 
 		done := s.ctx.Done()
 
@@ -191,20 +224,139 @@ func (s *subscriptionInformation) run() {
 		select {
 		case <-done:
 			return
+		case <-reorgs.wake:
+			// search the new chain right away
 		case <-time.After(backoffTime):
 			// ok, try again
 		}
 	}
 }
 
-// Compute hash for the json serialization of the passed in argument
-func computeHash(value interface{}) string {
-	b, err := json.Marshal(value)
+var agentConfigLogsReorgKey = "logsReorgInterval"
+
+// how often the blocks logs were sent from are compared with the node in the background
+var agentConfigLogsReorgInterval = 5 * time.Second
+
+// logsReorgs is the state of a logs subscription shared with the goroutine checking for reorgs
+type logsReorgs struct {
+	mutex   sync.Mutex
+	emitted *emittedLogs
+	// lowest block of a reorg whose new logs haven't been searched for yet
+	rescanFrom *uint64
+	// interrupts the waitforlogs call in progress
+	cancelWait context.CancelFunc
+	// interrupts the backoff between waitforlogs calls
+	wake chan struct{}
+}
+
+// watchReorgs sends the logs of orphaned blocks again with `removed: true` as soon as the reorg is detected,
+// and has the subscription search for the logs of the new chain right away
+func (s *subscriptionInformation) watchReorgs(reorgs *logsReorgs) {
+	for {
+		select {
+		case <-s.ctx.Done():
+			return
+		case <-time.After(s.reorgCheckInterval):
+		}
+
+		reorgs.mutex.Lock()
+		rescanFrom := reorgs.rescanFrom
+		err := s.checkReorgs(reorgs)
+		reorged := reorgs.rescanFrom != rescanFrom
+		cancelWait := reorgs.cancelWait
+		reorgs.mutex.Unlock()
+
+		if err != nil {
+			s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "msg", "Error checking for reorgs", "err", err)
+			continue
+		}
+		if reorged {
+			if cancelWait != nil {
+				cancelWait()
+			}
+			select {
+			case reorgs.wake <- struct{}{}:
+			default:
+			}
+		}
+	}
+}
+
+// checkReorgs removes the logs of orphaned blocks and records where to search the new chain from,
+// the mutex must be held
+func (s *subscriptionInformation) checkReorgs(reorgs *logsReorgs) error {
+	forkHeight, reorged, err := s.removeOrphanedLogs(reorgs.emitted)
+	if err != nil || !reorged {
+		return err
+	}
+	if reorgs.rescanFrom == nil || forkHeight < *reorgs.rescanFrom {
+		reorgs.rescanFrom = &forkHeight
+	}
+	return nil
+}
+
+func (s *subscriptionInformation) notify(ethLog eth.Log) error {
+	if s.blockHashes != nil {
+		// emitted logs keep their Qtum block hash to be compared with the node on reorgs
+		ethLog.BlockHash = s.blockHashes.Translate(s.ctx).EthereumHash(ethLog.BlockHash)
+	}
+	subscription := &eth.EthSubscription{
+		SubscriptionID: s.Subscription.id,
+		Result:         ethLog,
+	}
+	jsonRpcNotification, err := eth.NewJSONRPCNotification("eth_subscription", subscription)
 	if err != nil {
-		panic(err)
+		return err
 	}
-	md5Hash := md5.Sum(b)
-	return string(md5Hash[:])
+	s.Send(jsonRpcNotification)
+	return nil
+}
+
+// removeOrphanedLogs compares the blocks logs were sent from with the node, from the highest down,
+// and sends the logs of every block that is no longer part of the chain again with `removed: true`.
+// Returns the lowest orphaned height, so the logs of the new chain can be searched for from there
+func (s *subscriptionInformation) removeOrphanedLogs(emitted *emittedLogs) (uint64, bool, error) {
+	blocks := emitted.blocks
+	orphaned := map[*emittedBlock]bool{}
+	var matchedHeight *uint64
+	for i := len(blocks) - 1; i >= 0; i-- {
+		block := blocks[i]
+		if matchedHeight != nil && block.height < *matchedHeight {
+			// every block below one that is still part of the chain is too
+			break
+		}
+
+		hash, err := s.qtum.GetBlockHash(s.ctx, new(big.Int).SetUint64(block.height))
+		if err != nil && errors.Cause(err) != qtum.ErrInvalidParameter {
+			return 0, false, err
+		}
+
+		// an invalid parameter error means the chain is now shorter than the block height
+		if err == nil && utils.RemoveHexPrefix(string(hash)) == block.hash {
+			height := block.height
+			matchedHeight = &height
+			continue
+		}
+		orphaned[block] = true
+	}
+
+	if len(orphaned) == 0 {
+		return 0, false, nil
+	}
+
+	removed := emitted.remove(orphaned)
+	forkHeight := removed[0].height
+	s.qtum.GetDebugLogger().Log("subscriptionId", s.id, "msg", "reorg detected, notifying of removed logs", "forkHeight", forkHeight)
+	for _, block := range removed {
+		for _, ethLog := range block.logs {
+			ethLog.Removed = true
+			if err := s.notify(ethLog); err != nil {
+				return 0, false, err
+			}
+		}
+	}
+
+	return forkHeight, true, nil
 }
 
 func getBackoff(count int, min time.Duration, max time.Duration) time.Duration {
@@ -261,3 +413,86 @@ func (r *rollingLimit) Oldest() interface{} {
 func (r *rollingLimit) Push(t interface{}) {
 	r.times[r.bump()] = t
 }
+
+// qtum nodes don't reorganize deeper than 500 blocks by default
+const emittedLogsMaxBlocks = 500
+
+type emittedBlock struct {
+	height uint64
+	hash   string
+	logs   []eth.Log
+}
+
+// keeps track of the logs sent from the most recent blocks, ordered by height
+// so that they can be de-duplicated and removed again if their block is orphaned
+type emittedLogs struct {
+	maxBlocks int
+	blocks    []*emittedBlock
+}
+
+func newEmittedLogs(maxBlocks int) *emittedLogs {
+	return &emittedLogs{
+		maxBlocks: maxBlocks,
+	}
+}
+
+func (e *emittedLogs) find(hash string) *emittedBlock {
+	for i := len(e.blocks) - 1; i >= 0; i-- {
+		if e.blocks[i].hash == hash {
+			return e.blocks[i]
+		}
+	}
+	return nil
+}
+
+func (e *emittedLogs) contains(ethLog eth.Log) bool {
+	block := e.find(utils.RemoveHexPrefix(ethLog.BlockHash))
+	if block == nil {
+		return false
+	}
+	for _, sent := range block.logs {
+		if sent.TransactionHash == ethLog.TransactionHash && sent.LogIndex == ethLog.LogIndex {
+			return true
+		}
+	}
+	return false
+}
+
+func (e *emittedLogs) add(height uint64, ethLog eth.Log) {
+	hash := utils.RemoveHexPrefix(ethLog.BlockHash)
+	block := e.find(hash)
+	if block == nil {
+		block = &emittedBlock{
+			height: height,
+			hash:   hash,
+		}
+		// logs are almost always sent in order, so this is usually an append
+		i := len(e.blocks)
+		for i > 0 && e.blocks[i-1].height > height {
+			i--
+		}
+		e.blocks = append(e.blocks, nil)
+		copy(e.blocks[i+1:], e.blocks[i:])
+		e.blocks[i] = block
+
+		if len(e.blocks) > e.maxBlocks {
+			e.blocks = e.blocks[len(e.blocks)-e.maxBlocks:]
+		}
+	}
+	block.logs = append(block.logs, ethLog)
+}
+
+// remove stops tracking the passed in blocks, returning them in ascending height order
+func (e *emittedLogs) remove(blocks map[*emittedBlock]bool) []*emittedBlock {
+	removed := []*emittedBlock{}
+	kept := make([]*emittedBlock, 0, len(e.blocks))
+	for _, block := range e.blocks {
+		if blocks[block] {
+			removed = append(removed, block)
+		} else {
+			kept = append(kept, block)
+		}
+	}
+	e.blocks = kept
+	return removed
+}
@@ -0,0 +1,89 @@
+package notifier
+
+import (
+	"context"
+	"sync"
+	"time"
+
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+var agentConfigSyncingKey = "syncingInterval"
+var agentConfigSyncingInterval = 5 * time.Second
+
+// syncingWatcher polls getblockchaininfo on behalf of every syncing subscription
+type syncingWatcher struct {
+	qtum    *qtum.Qtum
+	mutex   sync.Mutex
+	running bool
+	// last status sent, nil until the first poll
+	status *eth.EthSubscriptionSyncingResponse
+}
+
+func newSyncingWatcher(qtum *qtum.Qtum) *syncingWatcher {
+	return &syncingWatcher{
+		qtum: qtum,
+	}
+}
+
+// poll returns the new sync status if it changed since the last call
+// the first call only returns a status if the node is syncing
+func (s *syncingWatcher) poll(ctx context.Context) (*eth.EthSubscriptionSyncingResponse, error) {
+	blockchainInfo, err := s.qtum.GetBlockChainInfo(ctx)
+	if err != nil {
+		return nil, err
+	}
+
+	status := &eth.EthSubscriptionSyncingResponse{
+		Syncing: blockchainInfo.IsSyncing(),
+		Status: eth.EthSubscriptionSyncingStatus{
+			StartingBlock: uint64(blockchainInfo.Blocks),
+			CurrentBlock:  uint64(blockchainInfo.Blocks),
+			HighestBlock:  uint64(blockchainInfo.Headers),
+		},
+	}
+
+	previous := s.status
+	if previous != nil && previous.Syncing {
+		status.Status.StartingBlock = previous.Status.StartingBlock
+	}
+	s.status = status
+
+	if previous == nil {
+		if status.Syncing {
+			return status, nil
+		}
+		return nil, nil
+	}
+
+	if previous.Syncing != status.Syncing {
+		return status, nil
+	}
+	if status.Syncing && (previous.Status.CurrentBlock != status.Status.CurrentBlock || previous.Status.HighestBlock != status.Status.HighestBlock) {
+		return status, nil
+	}
+	return nil, nil
+}
+
+// idle reports whether there are no subscriptions left, in which case the watcher is stopped
+// checking under the lock prevents racing with a new subscription
+func (s *syncingWatcher) idle(subscriptions func() int) bool {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	if subscriptions() != 0 {
+		return false
+	}
+	s.running = false
+	s.status = nil
+	return true
+}
+
+func (s *syncingWatcher) stop() {
+	s.mutex.Lock()
+	defer s.mutex.Unlock()
+
+	s.running = false
+	s.status = nil
+}
@@ -0,0 +1,53 @@
+package notifier
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestSyncingWatcher(t *testing.T) {
+	doer := internal.NewDoerMappedMock()
+	infos := []qtum.GetBlockChainInfoResponse{
+		{Blocks: 100, Headers: 100, Verificationprogress: 1},
+		{Blocks: 100, Headers: 300, Verificationprogress: 0.5},
+		{Blocks: 100, Headers: 300, Verificationprogress: 0.5},
+		{Blocks: 200, Headers: 300, Verificationprogress: 0.7},
+		{Blocks: 300, Headers: 300, Verificationprogress: 1},
+	}
+	for _, info := range infos {
+		if err := doer.AddResponse(qtum.MethodGetBlockChainInfo, info); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	qtumClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	watcher := newSyncingWatcher(qtumClient)
+
+	want := []*eth.EthSubscriptionSyncingResponse{
+		// not syncing when the first subscription is made
+		nil,
+		{Syncing: true, Status: eth.EthSubscriptionSyncingStatus{StartingBlock: 100, CurrentBlock: 100, HighestBlock: 300}},
+		// no progress
+		nil,
+		{Syncing: true, Status: eth.EthSubscriptionSyncingStatus{StartingBlock: 100, CurrentBlock: 200, HighestBlock: 300}},
+		// the final status still reports where syncing started
+		{Syncing: false, Status: eth.EthSubscriptionSyncingStatus{StartingBlock: 100, CurrentBlock: 300, HighestBlock: 300}},
+	}
+
+	for i, wantStatus := range want {
+		status, err := watcher.poll(context.Background())
+		if err != nil {
+			t.Fatal(err)
+		}
+		if (status == nil) != (wantStatus == nil) || (status != nil && *status != *wantStatus) {
+			t.Fatalf("poll %d: expected %+v, got %+v", i, wantStatus, status)
+		}
+	}
+}
@@ -5,6 +5,8 @@ import (
 
 	"github.com/btcsuite/btcd/chaincfg"
 	"github.com/btcsuite/btcutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/crypto"
 )
 
 type Accounts []*btcutil.WIF
@@ -21,6 +23,17 @@ func (as Accounts) FindByHexAddress(addr string) *btcutil.WIF {
 	return nil
 }
 
+// FindByEthereumAddress finds the key of an Ethereum address, which is derived from the same public key as the Qtum hex address but hashed differently
+func (as Accounts) FindByEthereumAddress(addr common.Address) *btcutil.WIF {
+	for _, a := range as {
+		if crypto.PubkeyToAddress(*a.PrivKey.PubKey().ToECDSA()) == addr {
+			return a
+		}
+	}
+
+	return nil
+}
+
 type Account struct {
 	*btcutil.WIF
 }
@@ -29,6 +29,7 @@ var FLAG_IGNORE_UNKNOWN_TX = "IGNORE_UNKNOWN_TX"
 var FLAG_DISABLE_SNIPPING_LOGS = "DISABLE_SNIPPING_LOGS"
 var FLAG_HIDE_QTUMD_LOGS = "HIDE_QTUMD_LOGS"
 var FLAG_MATURE_BLOCK_HEIGHT_OVERRIDE = "FLAG_MATURE_BLOCK_HEIGHT_OVERRIDE"
+var FLAG_COIN_SELECTION = "COIN_SELECTION"
 
 var maximumRequestTime = 10000
 var maximumBackoff = (2 * time.Second).Milliseconds()
@@ -489,6 +490,16 @@ func SetMatureBlockHeight(height *int) func(*Client) error {
 	}
 }
 
+func SetCoinSelection(coinSelection string) func(*Client) error {
+	return func(c *Client) error {
+		if _, ok := CoinSelectors[coinSelection]; !ok {
+			return errors.Errorf("Unknown coin selection strategy: '%s'", coinSelection)
+		}
+		c.SetFlag(FLAG_COIN_SELECTION, coinSelection)
+		return nil
+	}
+}
+
 func SetContext(ctx context.Context) func(*Client) error {
 	return func(c *Client) error {
 		c.ctx = ctx
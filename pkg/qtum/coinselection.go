@@ -0,0 +1,158 @@
+package qtum
+
+import (
+	"math"
+	"sort"
+)
+
+const (
+	// spends the oldest UTXOs first, keeping recent outputs like gas refunds, that the node may not consider mature yet, for last
+	CoinSelectionMatureFirst = "mature-first"
+	// spends the fewest UTXOs by picking the largest ones first
+	CoinSelectionLargestFirst = "largest-first"
+	// looks for UTXOs adding up to the amount needed so that no change is created, falling back to largest-first
+	CoinSelectionBranchAndBound = "branch-and-bound"
+)
+
+var DefaultCoinSelection = CoinSelectionMatureFirst
+
+var CoinSelectors = map[string]CoinSelector{
+	CoinSelectionMatureFirst:    SelectMatureFirst,
+	CoinSelectionLargestFirst:   SelectLargestFirst,
+	CoinSelectionBranchAndBound: SelectBranchAndBound,
+}
+
+// maximum number of branches explored by SelectBranchAndBound before giving up
+const branchAndBoundMaxTries = 100000
+
+// CoinSelectionTarget is what the selected UTXOs need to pay for, in satoshis
+type CoinSelectionTarget struct {
+	// value of the outputs plus the fees of the transaction without any input nor change
+	Amount int64
+	// fee paid for spending each UTXO, UTXOs worth less than that are never selected
+	InputFee int64
+	// fee paid for a change output
+	ChangeFee int64
+	// change worth less than this costs more to create and spend later than it is worth, it's left to the miner instead
+	CostOfChange int64
+}
+
+// CoinSelector picks the UTXOs to spend among the spendable UTXOs of an address
+type CoinSelector func(utxos []UTXO, target CoinSelectionTarget) ([]UTXO, error)
+
+// value of a UTXO once the fee for spending it is paid
+func (target CoinSelectionTarget) effectiveValue(utxo UTXO) int64 {
+	return utxo.Satoshis.IntPart() - target.InputFee
+}
+
+// SelectMatureFirst selects UTXOs from the lowest block height up
+func SelectMatureFirst(utxos []UTXO, target CoinSelectionTarget) ([]UTXO, error) {
+	sorted := append([]UTXO{}, utxos...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return utxoHeight(sorted[i]) < utxoHeight(sorted[j])
+	})
+	return accumulateUtxos(sorted, target)
+}
+
+// SelectLargestFirst selects UTXOs from the largest down
+func SelectLargestFirst(utxos []UTXO, target CoinSelectionTarget) ([]UTXO, error) {
+	sorted := append([]UTXO{}, utxos...)
+	sort.SliceStable(sorted, func(i, j int) bool {
+		return sorted[i].Satoshis.GreaterThan(sorted[j].Satoshis)
+	})
+	return accumulateUtxos(sorted, target)
+}
+
+// SelectBranchAndBound searches for the UTXOs that pay for the target with the least excess,
+// which is left to the miner as it is lower than the cost of creating change
+func SelectBranchAndBound(utxos []UTXO, target CoinSelectionTarget) ([]UTXO, error) {
+	candidates := []UTXO{}
+	for _, utxo := range utxos {
+		if target.effectiveValue(utxo) > 0 {
+			candidates = append(candidates, utxo)
+		}
+	}
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].Satoshis.GreaterThan(candidates[j].Satoshis)
+	})
+
+	values := make([]int64, len(candidates))
+	// remaining[i] is the value of candidates i and after, to prune branches that can't reach the target
+	remaining := make([]int64, len(candidates)+1)
+	for i := len(candidates) - 1; i >= 0; i-- {
+		values[i] = target.effectiveValue(candidates[i])
+		remaining[i] = remaining[i+1] + values[i]
+	}
+
+	upperBound := target.Amount + target.CostOfChange
+	var best []int
+	bestExcess := int64(math.MaxInt64)
+	selected := []int{}
+	tries := 0
+
+	var search func(i int, total int64)
+	search = func(i int, total int64) {
+		tries++
+		if tries > branchAndBoundMaxTries || total > upperBound {
+			return
+		}
+		if total >= target.Amount {
+			// selecting more would only add to the excess
+			if excess := total - target.Amount; excess < bestExcess {
+				bestExcess = excess
+				best = append([]int{}, selected...)
+			}
+			return
+		}
+		if i == len(candidates) || total+remaining[i] < target.Amount {
+			return
+		}
+
+		selected = append(selected, i)
+		search(i+1, total+values[i])
+		selected = selected[:len(selected)-1]
+		search(i+1, total)
+	}
+	search(0, 0)
+
+	if best == nil {
+		return SelectLargestFirst(utxos, target)
+	}
+
+	result := make([]UTXO, 0, len(best))
+	for _, i := range best {
+		result = append(result, candidates[i])
+	}
+	return result, nil
+}
+
+// accumulateUtxos selects UTXOs in order until there is enough to pay for the target and change,
+// or for the target alone once every UTXO is selected
+func accumulateUtxos(utxos []UTXO, target CoinSelectionTarget) ([]UTXO, error) {
+	selected := []UTXO{}
+	var total int64
+	for _, utxo := range utxos {
+		value := target.effectiveValue(utxo)
+		if value <= 0 {
+			// dust, spending it costs more than it is worth
+			continue
+		}
+		selected = append(selected, utxo)
+		total += value
+		if total >= target.Amount+target.ChangeFee {
+			return selected, nil
+		}
+	}
+
+	if total >= target.Amount && len(selected) > 0 {
+		return selected, nil
+	}
+	return nil, ErrInsufficientFunds
+}
+
+func utxoHeight(utxo UTXO) int64 {
+	if utxo.Height == nil {
+		return math.MaxInt64
+	}
+	return utxo.Height.Int64()
+}
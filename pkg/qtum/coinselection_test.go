@@ -0,0 +1,100 @@
+package qtum
+
+import (
+	"math/big"
+	"testing"
+
+	"github.com/shopspring/decimal"
+)
+
+func testUTXO(txid string, satoshis int64, height int64) UTXO {
+	return UTXO{
+		TXID:     txid,
+		Satoshis: decimal.NewFromInt(satoshis),
+		Height:   big.NewInt(height),
+	}
+}
+
+func selectedTXIDs(utxos []UTXO) []string {
+	txids := []string{}
+	for _, utxo := range utxos {
+		txids = append(txids, utxo.TXID)
+	}
+	return txids
+}
+
+func TestCoinSelection(t *testing.T) {
+	utxos := []UTXO{
+		testUTXO("recent", 5e8, 1990),
+		testUTXO("dust", 100, 10),
+		testUTXO("old", 1e8, 100),
+		testUTXO("older", 3e8, 50),
+		testUTXO("exact", 2e8+1000, 1500),
+	}
+	target := CoinSelectionTarget{
+		Amount:       2e8,
+		InputFee:     1000,
+		ChangeFee:    500,
+		CostOfChange: 2000,
+	}
+
+	tests := []struct {
+		name          string
+		coinSelection string
+		want          []string
+	}{
+		// dust isn't worth spending
+		{"mature first", CoinSelectionMatureFirst, []string{"older"}},
+		{"largest first", CoinSelectionLargestFirst, []string{"recent"}},
+		// the only UTXO that doesn't need change
+		{"branch and bound", CoinSelectionBranchAndBound, []string{"exact"}},
+	}
+
+	for _, test := range tests {
+		t.Run(test.name, func(t *testing.T) {
+			selected, err := CoinSelectors[test.coinSelection](utxos, target)
+			if err != nil {
+				t.Fatal(err)
+			}
+			got := selectedTXIDs(selected)
+			if len(got) != len(test.want) {
+				t.Fatalf("Expected %v to be selected, got %v", test.want, got)
+			}
+			for i := range got {
+				if got[i] != test.want[i] {
+					t.Fatalf("Expected %v to be selected, got %v", test.want, got)
+				}
+			}
+		})
+	}
+}
+
+func TestCoinSelectionBranchAndBoundFallback(t *testing.T) {
+	utxos := []UTXO{
+		testUTXO("a", 1e8, 10),
+		testUTXO("b", 3e8, 20),
+		testUTXO("c", 2e8, 30),
+	}
+
+	// no combination pays exactly for 4.5 QTUM, so change is needed
+	selected, err := SelectBranchAndBound(utxos, CoinSelectionTarget{Amount: 45e7})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := selectedTXIDs(selected); len(got) != 2 || got[0] != "b" || got[1] != "c" {
+		t.Fatalf("Expected to fall back to largest first, got %v", got)
+	}
+
+	// 1 + 3 QTUM is an exact match
+	selected, err = SelectBranchAndBound(utxos, CoinSelectionTarget{Amount: 4e8})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if got := selectedTXIDs(selected); len(got) != 2 || got[0] != "b" || got[1] != "a" {
+		t.Fatalf("Expected an exact match, got %v", got)
+	}
+
+	if _, err := SelectBranchAndBound(utxos, CoinSelectionTarget{Amount: 7e8}); err != ErrInsufficientFunds {
+		t.Fatalf("Expected insufficient funds, got %v", err)
+	}
+}
@@ -44,11 +44,15 @@ const (
 	MethodGetStakingInfo        = "getstakinginfo"
 	MethodGetAddressBalance     = "getaddressbalance"
 	MethodGetAddressUTXOs       = "getaddressutxos"
+	MethodGetAddressDeltas      = "getaddressdeltas"
+	MethodGetAddressMempool     = "getaddressmempool"
+	MethodGetDGPInfo            = "getdgpinfo"
 	MethodCreateWallet          = "createwallet"
 	MethodLoadWallet            = "loadwallet"
 	MethodUnloadWallet          = "unloadwallet"
 	MethodListWallets           = "listwallets"
 	MethodListWalletDir         = "listwalletdir"
+	MethodGetRawMempool         = "getrawmempool"
 )
 
 type JSONRPCRequest struct {
@@ -121,6 +121,22 @@ func (m *Method) GetTransactionReceipt(ctx context.Context, txHash string) (*Get
 	return resp, nil
 }
 
+// GetTransactionReceipts returns every EVM receipt of a transaction, one for each of its contract outputs
+func (m *Method) GetTransactionReceipts(ctx context.Context, txHash string) ([]TransactionReceipt, error) {
+	var resp []TransactionReceipt
+	err := m.RequestWithContext(ctx, MethodGetTransactionReceipt, GetTransactionReceiptRequest(txHash), &resp)
+	if err != nil {
+		if m.IsDebugEnabled() {
+			m.GetDebugLogger().Log("function", "GetTransactionReceipts", "Transaction Hash", txHash, "error", err)
+		}
+		return nil, err
+	}
+	if m.IsDebugEnabled() {
+		m.GetDebugLogger().Log("function", "GetTransactionReceipts", "Transaction Hash", txHash, "result", marshalToString(resp))
+	}
+	return resp, nil
+}
+
 func (m *Method) DecodeRawTransaction(ctx context.Context, hex string) (*DecodedRawTransactionResponse, error) {
 	var resp *DecodedRawTransactionResponse
 	err := m.RequestWithContext(ctx, MethodDecodeRawTransaction, DecodeRawTransactionRequest(hex), &resp)
@@ -194,12 +210,47 @@ func (m *Method) GetGasPrice(ctx context.Context) (*big.Int, error) {
 	return minimumGas, nil
 }
 
-// hard coded 0x1 due to the unique nature of Qtums UTXO system, might
-func (m *Method) GetTransactionCount(ctx context.Context, address string, status string) (*big.Int, error) {
-	// eventually might work this out to see if there's any transactions pending for an address in the mempool
-	// for now just always return 1
-	m.GetDebugLogger().Log("Message", "GetTransactionCount is hardcoded to one")
-	return big.NewInt(0x1), nil
+// GetTransactionCount returns the number of transactions sent by a base58 address, which is used as its nonce.
+// Qtum has no nonce, so the transactions spending the address's outputs up to blockNumber (the latest block if nil) are counted,
+// along with the ones waiting in the mempool if includeMempool is set
+func (m *Method) GetTransactionCount(ctx context.Context, address string, blockNumber *big.Int, includeMempool bool) (*big.Int, error) {
+	req := GetAddressDeltasRequest{
+		Addresses: []string{address},
+	}
+	if blockNumber != nil {
+		if blockNumber.Sign() == 0 {
+			// the genesis block's output can't be spent
+			return big.NewInt(0), nil
+		}
+		req.Start = 1
+		req.End = blockNumber.Int64()
+	}
+	deltas, err := m.GetAddressDeltas(ctx, &req)
+	if err != nil {
+		return nil, err
+	}
+
+	sent := map[string]bool{}
+	for _, txid := range deltas.SentTransactions() {
+		sent[txid] = true
+	}
+
+	if includeMempool {
+		mempool, err := m.GetAddressMempool(ctx, &GetAddressMempoolRequest{Addresses: []string{address}})
+		if err != nil {
+			return nil, err
+		}
+		for _, delta := range mempool {
+			if delta.Satoshis < 0 {
+				sent[delta.TXID] = true
+			}
+		}
+	}
+
+	if m.IsDebugEnabled() {
+		m.GetDebugLogger().Log("function", "GetTransactionCount", "address", address, "count", len(sent))
+	}
+	return big.NewInt(int64(len(sent))), nil
 }
 
 func (m *Method) GetBlockHash(ctx context.Context, b *big.Int) (resp GetBlockHashResponse, err error) {
@@ -243,6 +294,21 @@ func (m *Method) GetBlock(ctx context.Context, hash string) (resp *GetBlockRespo
 	return
 }
 
+// GetBlockVerbose returns a block along with all of its decoded transactions, saving a round trip per transaction.
+// The outputs spent by the transactions are included when qtumd supports getblock verbosity 3
+func (m *Method) GetBlockVerbose(ctx context.Context, hash string) (resp *GetBlockVerboseResponse, err error) {
+	verbosity := 3
+	req := GetBlockRequest{
+		Hash:      hash,
+		Verbosity: &verbosity,
+	}
+	err = m.RequestWithContext(ctx, MethodGetBlock, &req, &resp)
+	if err != nil && m.IsDebugEnabled() {
+		m.GetDebugLogger().Log("function", "GetBlockVerbose", "Hash", hash, "error", err)
+	}
+	return
+}
+
 func (m *Method) Generate(ctx context.Context, blockNum int, maxTries *int) (resp GenerateResponse, err error) {
 	generateToAccount := m.GetFlagString(FLAG_GENERATE_ADDRESS_TO)
 	var qAddress string
@@ -384,6 +450,45 @@ func (m *Method) GetAddressBalance(ctx context.Context, req *GetAddressBalanceRe
 	return
 }
 
+func (m *Method) GetAddressDeltas(ctx context.Context, req *GetAddressDeltasRequest) (resp GetAddressDeltasResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodGetAddressDeltas, req, &resp); err != nil {
+		if m.IsDebugEnabled() {
+			m.GetDebugLogger().Log("function", "GetAddressDeltas", "error", err)
+		}
+		return nil, err
+	}
+	if m.IsDebugEnabled() {
+		m.GetDebugLogger().Log("function", "GetAddressDeltas", "request", marshalToString(req), "msg", "Successfully got address deltas")
+	}
+	return
+}
+
+func (m *Method) GetAddressMempool(ctx context.Context, req *GetAddressMempoolRequest) (resp GetAddressMempoolResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodGetAddressMempool, req, &resp); err != nil {
+		if m.IsDebugEnabled() {
+			m.GetDebugLogger().Log("function", "GetAddressMempool", "error", err)
+		}
+		return nil, err
+	}
+	if m.IsDebugEnabled() {
+		m.GetDebugLogger().Log("function", "GetAddressMempool", "request", marshalToString(req), "msg", "Successfully got address mempool")
+	}
+	return
+}
+
+func (m *Method) GetDGPInfo(ctx context.Context) (resp *GetDGPInfoResponse, err error) {
+	if err := m.RequestWithContext(ctx, MethodGetDGPInfo, nil, &resp); err != nil {
+		if m.IsDebugEnabled() {
+			m.GetDebugLogger().Log("function", "GetDGPInfo", "error", err)
+		}
+		return nil, err
+	}
+	if m.IsDebugEnabled() {
+		m.GetDebugLogger().Log("function", "GetDGPInfo", "msg", "Successfully got dgp info", "result", marshalToString(resp))
+	}
+	return
+}
+
 func (m *Method) SendRawTransaction(ctx context.Context, req *SendRawTransactionRequest) (resp *SendRawTransactionResponse, err error) {
 	if err := m.RequestWithContext(ctx, MethodSendRawTx, req, &resp); err != nil {
 		if m.IsDebugEnabled() {
@@ -500,3 +605,15 @@ func (m *Method) ListWalletDir(ctx context.Context, req *ListWalletDirRequest) (
 	}
 	return
 }
+
+func (m *Method) GetRawMempool(ctx context.Context) (resp GetRawMempoolResponse, err error) {
+	err = m.RequestWithContext(ctx, MethodGetRawMempool, nil, &resp)
+	if m.IsDebugEnabled() {
+		if err != nil {
+			m.GetDebugLogger().Log("function", "GetRawMempool", "error", err)
+		} else {
+			m.GetDebugLogger().Log("function", "GetRawMempool", "result", marshalToString(resp))
+		}
+	}
+	return
+}
@@ -160,6 +160,16 @@ func (c *Qtum) GetMatureBlockHeight() int {
 	return 2000
 }
 
+// GetCoinSelection returns the name of the strategy picking UTXOs for transactions signed by Janus
+func (c *Qtum) GetCoinSelection() string {
+	coinSelection := c.GetFlagString(FLAG_COIN_SELECTION)
+	if coinSelection != nil {
+		return *coinSelection
+	}
+
+	return DefaultCoinSelection
+}
+
 func (c *Qtum) CanGenerate() bool {
 	return c.Chain() == ChainRegTest
 }
@@ -3,6 +3,7 @@ package qtum
 import (
 	"encoding/json"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
 
@@ -150,9 +151,24 @@ type (
 			} `json:"bip9"`
 		} `json:"softforks"`
 		Verificationprogress float64 `json:"verificationprogress"`
+		InitialBlockDownload bool    `json:"initialblockdownload"`
 	}
 )
 
+// number of blocks the node can be behind its headers without being reported as syncing,
+// a synced node receives the header of a new block shortly before the block itself
+const SyncingBlocksThreshold = 5
+
+// IsSyncing reports whether the node is still catching up with the network,
+// either downloading blocks it already has the headers of or still in its initial block download
+func (r *GetBlockChainInfoResponse) IsSyncing() bool {
+	if r.Headers-r.Blocks > SyncingBlocksThreshold {
+		return true
+	}
+	// a node that was offline for a while might not have the latest headers yet
+	return r.InitialBlockDownload && r.Verificationprogress < 0.9999
+}
+
 func (l Log) GetAddress() string {
 	return l.Address
 }
@@ -311,6 +327,8 @@ type (
 		To       string
 		Data     string
 		GasLimit *big.Int
+		// height of the block whose state the contract is called with, the latest block if nil
+		BlockNumber *big.Int
 	}
 
 	/*
@@ -348,10 +366,10 @@ type (
 			GasForDeposit   int    `json:"gasForDeposit"`
 		} `json:"executionResult"`
 		TransactionReceipt struct {
-			StateRoot string        `json:"stateRoot"`
-			GasUsed   int           `json:"gasUsed"`
-			Bloom     string        `json:"bloom"`
-			Log       []interface{} `json:"log"`
+			StateRoot string `json:"stateRoot"`
+			GasUsed   int    `json:"gasUsed"`
+			Bloom     string `json:"bloom"`
+			Log       []Log  `json:"log"`
 		} `json:"transactionReceipt"`
 	}
 )
@@ -362,15 +380,25 @@ func (r *CallContractRequest) MarshalJSON() ([]byte, error) {
 		utils.RemoveHexPrefix(r.Data),
 		r.From,
 	}
-	if r.GasLimit != nil {
+	if r.GasLimit != nil || r.BlockNumber != nil {
 		// optional parameter, null will not work
-		params = append(params, r.GasLimit)
+		gasLimit := r.GasLimit
+		if gasLimit == nil {
+			gasLimit, _ = new(big.Int).SetString(DefaultBlockGasLimit, 16)
+		}
+		params = append(params, gasLimit)
+	}
+	if r.BlockNumber != nil {
+		// the amount sent with the call has to be set to reach the block height
+		params = append(params, 0, r.BlockNumber)
 	}
 	/*
-		1. "address" (string, required) The account address
-		2. "data"    (string, required) The data hex string
-		3. address   (string, optional) The sender address hex string
-		4. gasLimit  (string, optional) The gas limit for executing the contract
+		1. "address"   (string, required) The account address
+		2. "data"      (string, required) The data hex string
+		3. address     (string, optional) The sender address hex string
+		4. gasLimit    (string, optional) The gas limit for executing the contract
+		5. amount      (numeric, optional) The amount in QTUM sent with the call
+		6. blockNumber (numeric, optional) The height of the block the contract is called at
 	*/
 
 	return json.Marshal(params)
@@ -473,6 +501,16 @@ type (
 		ScriptSig   DecodedRawTransactionScriptSig `json:"scriptSig"`
 		Txinwitness []string                       `json:"txinwitness"`
 		Sequence    int64                          `json:"sequence"`
+		// only set by getblock with verbosity 3, for inputs spending an output
+		Prevout *DecodedRawTransactionPrevout `json:"prevout,omitempty"`
+	}
+
+	// DecodedRawTransactionPrevout is the output spent by an input
+	DecodedRawTransactionPrevout struct {
+		Generated    bool                      `json:"generated"`
+		Height       int64                     `json:"height"`
+		Value        decimal.Decimal           `json:"value"`
+		ScriptPubKey RawTransactionVoutDetails `json:"scriptPubKey"`
 	}
 
 	DecodedRawTransactionOutV struct {
@@ -515,6 +553,16 @@ type ContractInfo struct {
 	UserInput string
 }
 
+// GasPriceInSatoshis parses the gas price of the OP_CALL or OP_CREATE script, which is in hex satoshis
+func (info ContractInfo) GasPriceInSatoshis() (*big.Int, error) {
+	// trim leading zeros from gasPrice
+	gasPrice := strings.TrimLeft(info.GasPrice, "0")
+	if len(gasPrice) == 0 {
+		gasPrice = "0"
+	}
+	return utils.DecodeBig(gasPrice)
+}
+
 // TODO: complete
 func (resp *DecodedRawTransactionResponse) ExtractContractInfo() (_ ContractInfo, isContractTx bool, _ error) {
 	// TODO: discuss
@@ -759,7 +807,8 @@ type (
 		ContractAddress string `json:"contractAddress"`
 
 		// May has "None" value, which means, that transaction is not executed
-		Excepted string `json:"excepted"`
+		Excepted        string `json:"excepted"`
+		ExceptedMessage string `json:"exceptedMessage"`
 
 		Log         []Log `json:"log"`
 		OutputIndex int64 `json:"outputIndex"`
@@ -959,6 +1008,16 @@ func (r *GetRawTransactionResponse) IsPending() bool {
 	return r.BlockHash == ""
 }
 
+// IsContractTransaction reports whether any output of the transaction calls or creates a contract
+func (r *GetRawTransactionResponse) IsContractTransaction() bool {
+	for _, vout := range r.Vouts {
+		if strings.HasSuffix(vout.Details.Asm, "OP_CALL") || strings.HasSuffix(vout.Details.Asm, "OP_CREATE") {
+			return true
+		}
+	}
+	return false
+}
+
 func (r *GetRawTransactionResponse) GetMiningFeeInQTUM() float64 {
 	var vinsTotals float64
 	var voutsTotals float64
@@ -1403,6 +1462,10 @@ type (
 	}
 )
 
+func (r *GetBlockResponse) IsGenesisBlock() bool {
+	return r.Height == genesisBlockHeight
+}
+
 func (r *GetBlockRequest) MarshalJSON() ([]byte, error) {
 	verbosity := 1
 	if r.Verbosity != nil {
@@ -1415,6 +1478,69 @@ func (r *GetBlockRequest) MarshalJSON() ([]byte, error) {
 	})
 }
 
+// ========== GetBlock (verbosity 2) ============= //
+type (
+	/*
+		The same object as GetBlockResponse, except that "tx" holds every transaction of the block
+		decoded like decoderawtransaction does, plus its hex. With verbosity 3, each input spending an output
+		also holds the spent output in "prevout", qtumd versions without verbosity 3 answer like verbosity 2:
+		{
+		  "hash": "bba11e1bacc69ba535d478cf1f2e542da3735a517b0b8eebaf7e6bb25eeb48c5",
+		  ...
+		  "tx": [
+		    {
+		      "txid": "3208dc44733cbfa11654ad5651305428de473ef1e61a1ec07b0c1a5f4843be91",
+		      "hash": "3208dc44733cbfa11654ad5651305428de473ef1e61a1ec07b0c1a5f4843be91",
+		      "version": 2,
+		      "size": 99,
+		      "vsize": 99,
+		      "locktime": 0,
+		      "vin": [
+		        {
+		          "txid": "7f5350dc474f2953a3f30282c1afcad2fb61cdcea5bd949c808ecc6f64ce1503",
+		          "vout": 0,
+		          ...
+		          "prevout": {
+		            "generated": false,
+		            "height": 3980,
+		            "value": 2.5,
+		            "scriptPubKey": {"asm": "...", "hex": "...", "address": "qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW", "type": "pubkeyhash"}
+		          }
+		        }
+		      ],
+		      "vout": [...],
+		      "hex": "02000000010000000000000000000000000000000000000000000000000000000000000000ffffffff..."
+		    },
+		    ...
+		  ],
+		  ...
+		}
+	*/
+	GetBlockVerboseResponse struct {
+		GetBlockResponse
+		Txs []*BlockTransaction `json:"tx"`
+	}
+
+	BlockTransaction struct {
+		DecodedRawTransactionResponse
+		Hex string `json:"hex"`
+	}
+)
+
+// IsCoinbase tells whether the input is the one of a coinbase transaction, which spends no output
+func (vin *DecodedRawTransactionInV) IsCoinbase() bool {
+	return vin.TxID == ""
+}
+
+// TxIDs returns the ids of the block's transactions, in the order they are in the block
+func (r *GetBlockVerboseResponse) TxIDs() []string {
+	txids := make([]string, 0, len(r.Txs))
+	for _, tx := range r.Txs {
+		txids = append(txids, tx.ID)
+	}
+	return txids
+}
+
 // ========CreateRawTransaction=========//
 type (
 	/*
@@ -1770,6 +1896,158 @@ func (req *GetAddressBalanceRequest) MarshalJSON() ([]byte, error) {
 	return json.Marshal(params)
 }
 
+// ======== getaddressdeltas ========= //
+type (
+
+	/*
+		Arguments:
+		1. (json object, required)
+			{
+				"addresses": [	(json array) The qtum addresses
+					"address",
+					...
+				],
+				"start": n,		(numeric) The start block height
+				"end": n		(numeric) The end block height
+			}
+		Result:
+		[
+			{
+				"satoshis": n,	(numeric) The difference of satoshis
+				"txid": "id",	(string) The related txid
+				"index": n,		(numeric) The related input or output index
+				"blockindex": n,	(numeric) The related block index
+				"height": n,	(numeric) The block height
+				"address": "str"	(string) The qtum address
+			},
+			...
+		]
+	*/
+	GetAddressDeltasRequest struct {
+		Addresses []string
+		// both ends of the range of block heights are included, the whole chain is searched if End is 0
+		Start int64
+		End   int64
+	}
+
+	GetAddressDeltasResponse []AddressDelta
+
+	AddressDelta struct {
+		Satoshis   int64  `json:"satoshis"`
+		TXID       string `json:"txid"`
+		Index      int64  `json:"index"`
+		BlockIndex int64  `json:"blockindex"`
+		Height     int64  `json:"height"`
+		Address    string `json:"address"`
+	}
+)
+
+func (req *GetAddressDeltasRequest) MarshalJSON() ([]byte, error) {
+	param := map[string]interface{}{
+		"addresses": req.Addresses,
+	}
+	if req.End != 0 {
+		param["start"] = req.Start
+		param["end"] = req.End
+	}
+	return json.Marshal([]interface{}{param})
+}
+
+// SentTransactions returns the ids of the transactions spending the address's outputs, in the order they were mined
+func (deltas GetAddressDeltasResponse) SentTransactions() []string {
+	spends := []AddressDelta{}
+	for _, delta := range deltas {
+		if delta.Satoshis < 0 {
+			spends = append(spends, delta)
+		}
+	}
+	sort.SliceStable(spends, func(i, j int) bool {
+		if spends[i].Height != spends[j].Height {
+			return spends[i].Height < spends[j].Height
+		}
+		return spends[i].BlockIndex < spends[j].BlockIndex
+	})
+
+	txids := []string{}
+	seen := map[string]bool{}
+	for _, spend := range spends {
+		// a transaction spending several outputs has a delta for each of them
+		if !seen[spend.TXID] {
+			seen[spend.TXID] = true
+			txids = append(txids, spend.TXID)
+		}
+	}
+	return txids
+}
+
+// ======== getaddressmempool ========= //
+type (
+
+	/*
+		Arguments:
+		1. (json object, required)
+			{
+				"addresses": [	(json array) The qtum addresses
+					"address",
+					...
+				]
+			}
+		Result:
+		[
+			{
+				"address": "str",	(string) The qtum address
+				"txid": "id",		(string) The related txid
+				"index": n,			(numeric) The related input or output index
+				"satoshis": n,		(numeric) The difference of satoshis
+				"timestamp": n,		(numeric) The time the transaction entered the mempool (seconds)
+				"prevtxid": "id",	(string) The previous txid (if spending)
+				"prevout": n		(numeric) The previous transaction output index (if spending)
+			},
+			...
+		]
+	*/
+	GetAddressMempoolRequest struct {
+		Addresses []string
+	}
+
+	GetAddressMempoolResponse []AddressMempoolDelta
+
+	AddressMempoolDelta struct {
+		Address   string `json:"address"`
+		TXID      string `json:"txid"`
+		Index     int64  `json:"index"`
+		Satoshis  int64  `json:"satoshis"`
+		Timestamp int64  `json:"timestamp"`
+		PrevTXID  string `json:"prevtxid,omitempty"`
+		PrevOut   int64  `json:"prevout,omitempty"`
+	}
+)
+
+func (req *GetAddressMempoolRequest) MarshalJSON() ([]byte, error) {
+	params := []map[string][]string{
+		{"addresses": req.Addresses},
+	}
+	return json.Marshal(params)
+}
+
+// ======== getdgpinfo ========= //
+type (
+
+	/*
+		Result:
+		{
+			"maxblocksize": n,	(numeric) The maximum block size in bytes
+			"mingasprice": n,	(numeric) The minimum gas price in satoshis
+			"blockgaslimit": n	(numeric) The gas limit of a block
+		}
+	*/
+	GetDGPInfoResponse struct {
+		MaxBlockSize  uint64 `json:"maxblocksize"`
+		MinGasPrice   uint64 `json:"mingasprice"`
+		BlockGasLimit uint64 `json:"blockgaslimit"`
+	}
+)
+
 // ======== getpeerinfo ========= //
 type (
 	GetPeerInfoResponse struct {
@@ -2023,3 +2301,9 @@ type (
 		Wallets []ListWalletDirWallet `json:"wallets"`
 	}
 )
+
+// ======== getrawmempool ======== //
+type (
+	// GetRawMempoolResponse is the list of transaction ids in the mempool
+	GetRawMempoolResponse []string
+)
@@ -204,3 +204,46 @@ func TestExtractContractInfo(t *testing.T) {
 		)
 	}
 }
+
+func TestCallContractRequestAtBlockNumber(t *testing.T) {
+	// the gas limit and amount have to be set to pass the block height
+	expected := `["1e6f89d7399081b4f8f8aa1ae2805a5efff2f960","","qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW",40000000,0,100]`
+	request := &CallContractRequest{
+		To:          "0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960",
+		From:        "qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW",
+		BlockNumber: big.NewInt(100),
+	}
+
+	result, err := json.Marshal(request)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if string(result) != expected {
+		t.Errorf(
+			"error\nwant: %s\ngot: %s",
+			expected,
+			string(result),
+		)
+	}
+}
+
+func TestGetBlockChainInfoIsSyncing(t *testing.T) {
+	tests := []struct {
+		name    string
+		info    GetBlockChainInfoResponse
+		syncing bool
+	}{
+		{"synced", GetBlockChainInfoResponse{Blocks: 1000, Headers: 1000, Verificationprogress: 0.99999}, false},
+		{"header of the next block received", GetBlockChainInfoResponse{Blocks: 1000, Headers: 1001, Verificationprogress: 0.99999}, false},
+		{"behind the headers", GetBlockChainInfoResponse{Blocks: 1000, Headers: 1000 + SyncingBlocksThreshold + 1, Verificationprogress: 0.99}, true},
+		{"initial block download", GetBlockChainInfoResponse{Blocks: 1000, Headers: 1000, InitialBlockDownload: true, Verificationprogress: 0.5}, true},
+		{"idle regtest", GetBlockChainInfoResponse{Blocks: 1000, Headers: 1000, InitialBlockDownload: true, Verificationprogress: 1}, false},
+	}
+
+	for _, test := range tests {
+		if syncing := test.info.IsSyncing(); syncing != test.syncing {
+			t.Errorf("%s: expected syncing to be %v, got %v", test.name, test.syncing, syncing)
+		}
+	}
+}
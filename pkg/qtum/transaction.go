@@ -0,0 +1,295 @@
+package qtum
+
+import (
+	"bytes"
+	"encoding/hex"
+	"math/big"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/chaincfg/chainhash"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/btcsuite/btcutil/base58"
+	"github.com/pkg/errors"
+)
+
+// DefaultFeeRate is qtumd's default minimum relay fee, in satoshis per 1000 bytes
+var DefaultFeeRate = int64(400000)
+
+var ErrInsufficientFunds = errors.New("Insufficient UTXO value attempted to be sent")
+
+const (
+	// version of the VM executing contract outputs
+	contractVMVersion = 4
+
+	opCreate = 0xc1
+	opCall   = 0xc2
+)
+
+// sizes in bytes used to estimate the fee of a transaction before it is signed
+const (
+	// version, lock time and the input and output counts
+	txOverheadSize = 10
+	// outpoint, sequence and a signature script holding a signature and a compressed public key
+	p2pkhInputSize = 148
+	// a signature script holding a signature and an uncompressed public key is 32 bytes larger
+	uncompressedPubKeyExtraSize = 32
+	// outpoint, sequence and a signature script only holding a signature
+	p2pkInputSize = 114
+	// value, script length and a P2PKH script
+	p2pkhOutputSize = 34
+)
+
+// LocalTransactionRequest describes a transaction to build and sign with one of the keys passed with --accounts
+type LocalTransactionRequest struct {
+	Key *btcutil.WIF
+	// spendable outputs of the key's address
+	UTXOs []UTXO
+	// picks the UTXOs to spend, DefaultCoinSelection if nil
+	CoinSelection CoinSelector
+	// outputs created by the transaction, change is sent back to the key's address
+	Outputs []*wire.TxOut
+	// satoshis paid for the gas of contract outputs (gas limit * gas price), on top of the fee for the transaction's size
+	GasFee int64
+	// satoshis per 1000 bytes
+	FeeRate int64
+	// spend every UTXO and send what's left after the fees with the first output instead of creating change
+	SendAll bool
+}
+
+// BuildLocalTransaction selects enough UTXOs to pay for the outputs and fees, and signs the transaction's inputs
+func BuildLocalTransaction(req *LocalTransactionRequest) (*wire.MsgTx, error) {
+	pubKey := req.Key.SerializePubKey()
+	p2pkhScript := PayToPubKeyHashScript(btcutil.Hash160(pubKey))
+	p2pkScript := payToPubKeyScript(pubKey)
+	inputSize := int64(p2pkhInputSize)
+	if !req.Key.CompressPubKey {
+		inputSize += uncompressedPubKeyExtraSize
+	}
+
+	tx := wire.NewMsgTx(2)
+	size := int64(txOverheadSize)
+	var outputsValue int64
+	for _, output := range req.Outputs {
+		tx.AddTxOut(wire.NewTxOut(output.Value, output.PkScript))
+		size += int64(output.SerializeSize())
+		outputsValue += output.Value
+	}
+
+	fee := func(size int64) int64 {
+		return size*req.FeeRate/1000 + req.GasFee
+	}
+
+	// only the UTXOs the key can spend with a single signature are candidates
+	candidates := []UTXO{}
+	for _, utxo := range req.UTXOs {
+		if utxo.Script == hex.EncodeToString(p2pkhScript) || utxo.Script == hex.EncodeToString(p2pkScript) {
+			candidates = append(candidates, utxo)
+		}
+	}
+
+	selected := candidates
+	if !req.SendAll {
+		coinSelection := req.CoinSelection
+		if coinSelection == nil {
+			coinSelection = CoinSelectors[DefaultCoinSelection]
+		}
+		changeFee := p2pkhOutputSize * req.FeeRate / 1000
+		var err error
+		selected, err = coinSelection(candidates, CoinSelectionTarget{
+			Amount:       outputsValue + fee(size),
+			InputFee:     inputSize * req.FeeRate / 1000,
+			ChangeFee:    changeFee,
+			CostOfChange: changeFee + inputSize*req.FeeRate/1000,
+		})
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	// scripts of the selected UTXOs, needed to sign their inputs
+	prevScripts := [][]byte{}
+	var inputsValue int64
+	for _, utxo := range selected {
+		script, err := hex.DecodeString(utxo.Script)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid script for UTXO %s:%d", utxo.TXID, utxo.OutputIndex)
+		}
+		if bytes.Equal(script, p2pkScript) {
+			size += p2pkInputSize
+		} else {
+			size += inputSize
+		}
+
+		hash, err := chainhash.NewHashFromStr(utxo.TXID)
+		if err != nil {
+			return nil, errors.Wrapf(err, "Invalid UTXO transaction id %s", utxo.TXID)
+		}
+		tx.AddTxIn(wire.NewTxIn(wire.NewOutPoint(hash, uint32(utxo.OutputIndex)), nil, nil))
+		prevScripts = append(prevScripts, script)
+		inputsValue += utxo.Satoshis.IntPart()
+	}
+
+	// outputs that would cost more to spend than they are worth are left to the miner
+	dust := (p2pkhOutputSize + inputSize) * req.FeeRate / 1000
+
+	if req.SendAll {
+		if len(tx.TxOut) == 0 {
+			return nil, errors.New("Sending all coins needs an output to send them with")
+		}
+		tx.TxOut[0].Value += inputsValue - outputsValue - fee(size)
+		if tx.TxOut[0].Value <= dust {
+			return nil, ErrInsufficientFunds
+		}
+	} else {
+		if inputsValue < outputsValue+fee(size) {
+			return nil, ErrInsufficientFunds
+		}
+
+		change := inputsValue - outputsValue - fee(size+p2pkhOutputSize)
+		if change > dust {
+			tx.AddTxOut(wire.NewTxOut(change, p2pkhScript))
+		}
+	}
+
+	for i, script := range prevScripts {
+		var signatureScript []byte
+		var err error
+		if bytes.Equal(script, p2pkhScript) {
+			signatureScript, err = txscript.SignatureScript(tx, i, script, txscript.SigHashAll, req.Key.PrivKey, req.Key.CompressPubKey)
+		} else {
+			signatureScript, err = payToPubKeySignatureScript(tx, i, script, req.Key.PrivKey)
+		}
+		if err != nil {
+			return nil, errors.Wrapf(err, "Failed to sign input %d", i)
+		}
+		tx.TxIn[i].SignatureScript = signatureScript
+	}
+
+	return tx, nil
+}
+
+// EstimateFee returns the fee for the size of a transaction spending P2PKH outputs of compressed public keys, before it is signed
+func EstimateFee(inputs int, outputs []*wire.TxOut, feeRate int64) int64 {
+	size := int64(txOverheadSize + inputs*p2pkhInputSize)
+	for _, output := range outputs {
+		size += int64(output.SerializeSize())
+	}
+	return size * feeRate / 1000
+}
+
+// SerializeTransaction returns the hex encoding of a transaction, as accepted by sendrawtransaction
+func SerializeTransaction(tx *wire.MsgTx) (string, error) {
+	var buf bytes.Buffer
+	if err := tx.Serialize(&buf); err != nil {
+		return "", err
+	}
+	return hex.EncodeToString(buf.Bytes()), nil
+}
+
+func payToPubKeySignatureScript(tx *wire.MsgTx, idx int, script []byte, key *btcec.PrivateKey) ([]byte, error) {
+	signature, err := txscript.RawTxInSignature(tx, idx, script, txscript.SigHashAll, key)
+	if err != nil {
+		return nil, err
+	}
+	return pushData(nil, signature), nil
+}
+
+func payToPubKeyScript(pubKey []byte) []byte {
+	script := pushData(nil, pubKey)
+	return append(script, txscript.OP_CHECKSIG)
+}
+
+// PayToPubKeyHashScript returns the script of an output paying to a public key hash
+func PayToPubKeyHashScript(pubKeyHash []byte) []byte {
+	script := []byte{txscript.OP_DUP, txscript.OP_HASH160}
+	script = pushData(script, pubKeyHash)
+	return append(script, txscript.OP_EQUALVERIFY, txscript.OP_CHECKSIG)
+}
+
+// PayToAddressScript returns the script of an output paying to a base58 P2PKH or P2SH address
+func PayToAddressScript(address string) ([]byte, error) {
+	hash, version, err := base58.CheckDecode(address)
+	if err != nil {
+		return nil, errors.Wrapf(err, "Invalid address %s", address)
+	}
+	if len(hash) != 20 {
+		return nil, errors.Errorf("Invalid address %s", address)
+	}
+
+	switch version {
+	case qtumMainNetParams.PubKeyHashAddrID, qtumTestNetParams.PubKeyHashAddrID:
+		return PayToPubKeyHashScript(hash), nil
+	case qtumMainNetParams.ScriptHashAddrID, qtumTestNetParams.ScriptHashAddrID:
+		script := []byte{txscript.OP_HASH160}
+		script = pushData(script, hash)
+		return append(script, txscript.OP_EQUAL), nil
+	default:
+		return nil, errors.Errorf("Unsupported address version %d for %s", version, address)
+	}
+}
+
+// ContractCreateScript returns the script of an output deploying a contract: 4 <gas limit> <gas price> <bytecode> OP_CREATE
+func ContractCreateScript(gasLimit *big.Int, gasPrice int64, bytecode []byte) []byte {
+	script := contractScriptPrefix(gasLimit, gasPrice, bytecode)
+	return append(script, opCreate)
+}
+
+// ContractCallScript returns the script of an output calling a contract: 4 <gas limit> <gas price> <data> <contract address> OP_CALL
+func ContractCallScript(gasLimit *big.Int, gasPrice int64, data []byte, contract []byte) []byte {
+	script := contractScriptPrefix(gasLimit, gasPrice, data)
+	script = pushData(script, contract)
+	return append(script, opCall)
+}
+
+func contractScriptPrefix(gasLimit *big.Int, gasPrice int64, data []byte) []byte {
+	// qtumd pushes every number as data, even the ones that have a dedicated opcode like the VM version
+	script := pushData(nil, scriptNum(contractVMVersion))
+	script = pushData(script, scriptNum(gasLimit.Int64()))
+	script = pushData(script, scriptNum(gasPrice))
+	return pushData(script, data)
+}
+
+// scriptNum encodes a number the way qtumd's CScriptNum does: little endian, with the sign in the most significant bit
+func scriptNum(n int64) []byte {
+	if n == 0 {
+		return []byte{}
+	}
+
+	negative := n < 0
+	if negative {
+		n = -n
+	}
+	result := []byte{}
+	for n > 0 {
+		result = append(result, byte(n&0xff))
+		n >>= 8
+	}
+	if result[len(result)-1]&0x80 != 0 {
+		extraByte := byte(0x00)
+		if negative {
+			extraByte = 0x80
+		}
+		result = append(result, extraByte)
+	} else if negative {
+		result[len(result)-1] |= 0x80
+	}
+	return result
+}
+
+// pushData appends the smallest push operation of data to script, without turning small numbers into opcodes
+func pushData(script []byte, data []byte) []byte {
+	length := len(data)
+	switch {
+	case length < txscript.OP_PUSHDATA1:
+		script = append(script, byte(length))
+	case length <= 0xff:
+		script = append(script, txscript.OP_PUSHDATA1, byte(length))
+	case length <= 0xffff:
+		script = append(script, txscript.OP_PUSHDATA2, byte(length), byte(length>>8))
+	default:
+		script = append(script, txscript.OP_PUSHDATA4, byte(length), byte(length>>8), byte(length>>16), byte(length>>24))
+	}
+	return append(script, data...)
+}
@@ -0,0 +1,145 @@
+package qtum
+
+import (
+	"encoding/hex"
+	"math/big"
+	"strings"
+	"testing"
+
+	"github.com/btcsuite/btcd/btcec"
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/shopspring/decimal"
+)
+
+func newTestKey(t *testing.T) *btcutil.WIF {
+	privKey, _ := btcec.PrivKeyFromBytes(btcec.S256(), []byte("janus local transaction test key"))
+	wif, err := btcutil.NewWIF(privKey, &qtumTestNetParams, true)
+	if err != nil {
+		t.Fatal(err)
+	}
+	return wif
+}
+
+func TestContractCallScript(t *testing.T) {
+	contract, _ := hex.DecodeString("54fefdb5b31164f66ddb68becd7bdd864cacd65b")
+	data, _ := hex.DecodeString("a9059cbb")
+
+	script := ContractCallScript(big.NewInt(250000), 40, data, contract)
+
+	want := "01040390d003012804a9059cbb1454fefdb5b31164f66ddb68becd7bdd864cacd65bc2"
+	if got := hex.EncodeToString(script); got != want {
+		t.Fatalf("Unexpected OP_CALL script\nwant: %s\ngot:  %s", want, got)
+	}
+
+	asm, err := txscript.DisasmString(script)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the disassembler doesn't know about OP_CALL
+	info, err := ParseCallASM(append(strings.Fields(asm)[:5], "OP_CALL"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	if info.GasLimit != "3d090" || info.To != "54fefdb5b31164f66ddb68becd7bdd864cacd65b" || info.CallData != "a9059cbb" {
+		t.Fatalf("Unexpected parsed OP_CALL script: %+v", info)
+	}
+}
+
+func TestBuildLocalTransaction(t *testing.T) {
+	key := newTestKey(t)
+	pubKeyHash := btcutil.Hash160(key.SerializePubKey())
+	prevScript := PayToPubKeyHashScript(pubKeyHash)
+
+	utxos := []UTXO{
+		// not spendable with the key
+		{TXID: "11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5", OutputIndex: 0, Script: "a914" + hex.EncodeToString(pubKeyHash) + "87", Satoshis: decimal.NewFromInt(1e8)},
+		{TXID: "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", OutputIndex: 1, Script: hex.EncodeToString(prevScript), Satoshis: decimal.NewFromInt(1e8)},
+		{TXID: "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", OutputIndex: 2, Script: hex.EncodeToString(prevScript), Satoshis: decimal.NewFromInt(1e8)},
+		// not needed
+		{TXID: "7d7d8b9c7a4d1e5f3f3b1f1c3e4a4b2d1e9f8a7b6c5d4e3f2a1b0c9d8e7f6a5b", OutputIndex: 0, Script: hex.EncodeToString(prevScript), Satoshis: decimal.NewFromInt(1e8)},
+	}
+	contract, _ := hex.DecodeString("54fefdb5b31164f66ddb68becd7bdd864cacd65b")
+	outputs := []*wire.TxOut{
+		wire.NewTxOut(15e7, ContractCallScript(big.NewInt(250000), 40, []byte{0xa9, 0x05, 0x9c, 0xbb}, contract)),
+	}
+
+	tx, err := BuildLocalTransaction(&LocalTransactionRequest{
+		Key:     key,
+		UTXOs:   utxos,
+		Outputs: outputs,
+		GasFee:  250000 * 40,
+		FeeRate: DefaultFeeRate,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tx.TxIn) != 2 || tx.TxIn[0].PreviousOutPoint.Index != 1 || tx.TxIn[1].PreviousOutPoint.Index != 2 {
+		t.Fatalf("Expected the two P2PKH UTXOs to be spent, got %+v", tx.TxIn)
+	}
+	if len(tx.TxOut) != 2 {
+		t.Fatalf("Expected a change output, got %d outputs", len(tx.TxOut))
+	}
+
+	fee := int64(2e8) - tx.TxOut[0].Value - tx.TxOut[1].Value
+	sizeFee := int64(tx.SerializeSize()) * DefaultFeeRate / 1000
+	if fee < 250000*40+sizeFee || fee > 250000*40+sizeFee+DefaultFeeRate/10 {
+		t.Fatalf("Unexpected fee %d for a transaction of %d bytes", fee, tx.SerializeSize())
+	}
+
+	for i := range tx.TxIn {
+		engine, err := txscript.NewEngine(prevScript, tx, i, txscript.StandardVerifyFlags, nil, nil, 1e8)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := engine.Execute(); err != nil {
+			t.Fatalf("Input %d signature doesn't verify: %s", i, err)
+		}
+	}
+
+	if _, err := BuildLocalTransaction(&LocalTransactionRequest{
+		Key:     key,
+		UTXOs:   utxos[:2],
+		Outputs: outputs,
+		GasFee:  250000 * 40,
+		FeeRate: DefaultFeeRate,
+	}); err != ErrInsufficientFunds {
+		t.Fatalf("Expected insufficient funds, got %v", err)
+	}
+}
+
+func TestBuildLocalTransactionSendAll(t *testing.T) {
+	key := newTestKey(t)
+	prevScript := PayToPubKeyHashScript(btcutil.Hash160(key.SerializePubKey()))
+
+	utxos := []UTXO{
+		{TXID: "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", OutputIndex: 1, Script: hex.EncodeToString(prevScript), Satoshis: decimal.NewFromInt(1e8)},
+		{TXID: "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", OutputIndex: 2, Script: hex.EncodeToString(prevScript), Satoshis: decimal.NewFromInt(5e7)},
+	}
+	receiver, _ := hex.DecodeString("7926223070547d2d15b2ef5e7383e541c338ffe9")
+	output := wire.NewTxOut(0, PayToPubKeyHashScript(receiver))
+
+	tx, err := BuildLocalTransaction(&LocalTransactionRequest{
+		Key:     key,
+		UTXOs:   utxos,
+		Outputs: []*wire.TxOut{output},
+		FeeRate: DefaultFeeRate,
+		SendAll: true,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tx.TxIn) != 2 || len(tx.TxOut) != 1 {
+		t.Fatalf("Expected every UTXO to be sent to a single output, got %d inputs and %d outputs", len(tx.TxIn), len(tx.TxOut))
+	}
+	fee := int64(15e7) - tx.TxOut[0].Value
+	if estimated := EstimateFee(2, []*wire.TxOut{output}, DefaultFeeRate); fee != estimated {
+		t.Fatalf("Expected the fee to be %d, got %d", estimated, fee)
+	}
+	if sizeFee := int64(tx.SerializeSize()) * DefaultFeeRate / 1000; fee < sizeFee {
+		t.Fatalf("Fee %d is too low for a transaction of %d bytes", fee, tx.SerializeSize())
+	}
+}
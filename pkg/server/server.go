@@ -180,7 +180,9 @@ func (s *Server) Start() error {
 		e.Close()
 	}(s.qtumRPCClient.GetContext(), e)
 
-	if s.qtumRPCClient.DbConfig.String() == "" {
+	if s.blockHash.HasStore() {
+		level.Info(s.logger).Log("msg", "Using the embedded block hash store")
+	} else if s.qtumRPCClient.DbConfig.String() == "" {
 		level.Warn(s.logger).Log("msg", "Database not configured - won't be able to respond to Ethereum block hash requests")
 	} else {
 		chainIdChan := make(chan int, 1)
@@ -265,6 +267,16 @@ func SetHealthCheckPercent(percent *int) Option {
 	}
 }
 
+// SetBlockHashStore makes Ethereum block hashes be looked up in an embedded store instead of the Postgres database
+func SetBlockHashStore(store *blockhash.Store) Option {
+	return func(p *Server) error {
+		if store != nil {
+			p.blockHash.SetStore(store)
+		}
+		return nil
+	}
+}
+
 func batchRequestsMiddleware(h echo.HandlerFunc) echo.HandlerFunc {
 	return func(c echo.Context) error {
 		myctx := c.Get("myctx")
@@ -0,0 +1,69 @@
+package transformer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/qtumproject/janus/pkg/blockhash"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+// blockHashSource implements blockhash.BlockSource, building block headers the same way eth_getBlockByHash does
+type blockHashSource struct {
+	*qtum.Qtum
+}
+
+// NewBlockHashSource returns the source of the blocks hashed by a blockhash.Store
+func NewBlockHashSource(qtumClient *qtum.Qtum) blockhash.BlockSource {
+	return &blockHashSource{qtumClient}
+}
+
+func (s *blockHashSource) Tip(ctx context.Context) (uint64, error) {
+	blockCount, err := s.GetBlockCount(ctx)
+	if err != nil {
+		return 0, err
+	}
+	return blockCount.Uint64(), nil
+}
+
+func (s *blockHashSource) QtumHash(ctx context.Context, height uint64) (common.Hash, error) {
+	hash, err := s.GetBlockHash(ctx, new(big.Int).SetUint64(height))
+	if err != nil {
+		return common.Hash{}, err
+	}
+	return common.HexToHash(string(hash)), nil
+}
+
+// Block builds the header of a block from getblock verbosity 3 alone, plus the receipts of its contract transactions.
+// Unlike eth_getBlockByHash, it fails when the receipts or the miner can't be fetched instead of falling back on defaults
+func (s *blockHashSource) Block(ctx context.Context, height uint64) (*eth.GetBlockByHashResponse, error) {
+	hash, err := s.GetBlockHash(ctx, new(big.Int).SetUint64(height))
+	if err != nil {
+		return nil, err
+	}
+	block, err := s.GetBlockVerbose(ctx, string(hash))
+	if err != nil {
+		return nil, err
+	}
+
+	receipts, err := getBlockReceipts(ctx, s.Qtum, block)
+	if err != nil {
+		return nil, err
+	}
+	details := blockDetails{
+		miner:     utils.AddHexPrefix(qtum.ZeroAddress),
+		gasUsed:   hexutil.EncodeUint64(blockGasUsed(receipts)),
+		logsBloom: blockLogsBloom(receipts),
+	}
+	if !block.IsGenesisBlock() {
+		details.miner, err = getBlockMiner(ctx, s.Qtum, block)
+		if err != nil {
+			return nil, err
+		}
+	}
+	return blockHeaderResponse(&block.GetBlockResponse, details), nil
+}
@@ -0,0 +1,52 @@
+package transformer
+
+import (
+	"context"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestBlockHashSourceFailsInsteadOfFallingBack(t *testing.T) {
+	block := internal.GetBlockVerboseResponse
+	block.Txs = append([]*qtum.BlockTransaction{}, internal.GetBlockVerboseResponse.Txs...)
+	block.Txs = append(block.Txs, callTransaction("d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", "28"))
+
+	tests := []struct {
+		name     string
+		receipts interface{}
+	}{
+		{"receipts", eth.NewCallbackError("-logevents is not enabled")},
+		// the coinstake is fetched without prevouts, so the staker is looked up with getrawtransaction, which isn't mocked
+		{"miner", []qtum.TransactionReceipt{}},
+	}
+	for _, test := range tests {
+		mockedClientDoer := internal.NewDoerMappedMock()
+		qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+		if err != nil {
+			t.Fatal(err)
+		}
+		if err := mockedClientDoer.AddResponse(qtum.MethodGetBlockHash, qtum.GetBlockHashResponse(internal.GetTransactionByHashBlockHash)); err != nil {
+			t.Fatal(err)
+		}
+		if err := mockedClientDoer.AddResponse(qtum.MethodGetBlock, block); err != nil {
+			t.Fatal(err)
+		}
+		if jsonErr, ok := test.receipts.(eth.JSONRPCError); ok {
+			err = mockedClientDoer.AddError(qtum.MethodGetTransactionReceipt, jsonErr)
+		} else {
+			err = mockedClientDoer.AddResponse(qtum.MethodGetTransactionReceipt, test.receipts)
+		}
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// eth_getBlockByHash would serve a full bloom or the zero address, which mustn't be hashed
+		got, err := NewBlockHashSource(qtumClient).Block(context.Background(), 3983)
+		if err == nil {
+			t.Errorf("%s: expected an error when the block's %s can't be fetched, got %+v", test.name, test.name, got)
+		}
+	}
+}
@@ -0,0 +1,146 @@
+package transformer
+
+import (
+	"encoding/json"
+	"strings"
+
+	"github.com/qtumproject/janus/pkg/blockhash"
+	"github.com/qtumproject/janus/pkg/eth"
+)
+
+// positional parameters that can be block hashes, by method
+var blockHashParams = map[string]int{
+	"eth_getBlockByHash":                    0,
+	"eth_getBlockReceipts":                  0,
+	"eth_getTransactionByBlockHashAndIndex": 0,
+	"eth_getUncleByBlockHashAndIndex":       0,
+	"eth_getUncleCountByBlockHash":          0,
+}
+
+// translateRequestBlockHashes replaces the Ethereum block hashes a client sent with the Qtum hashes the proxies work with.
+// Besides the positional block hashes of blockHashParams, the blockHash of every object parameter is translated, which covers log filters and EIP-1898 block parameters
+func translateRequestBlockHashes(translation *blockhash.Translation, req *eth.JSONRPCRequest) *eth.JSONRPCRequest {
+	var params []json.RawMessage
+	if err := json.Unmarshal(req.Params, &params); err != nil {
+		// let the proxy report invalid parameters
+		return req
+	}
+
+	hashParam, hasHashParam := blockHashParams[req.Method]
+	translated := false
+	for i, param := range params {
+		if hasHashParam && i == hashParam {
+			if hash, ok := translateBlockHashParam(translation, param); ok {
+				params[i] = hash
+				translated = true
+			}
+			continue
+		}
+
+		var object map[string]json.RawMessage
+		if err := json.Unmarshal(param, &object); err != nil {
+			continue
+		}
+		objectTranslated := false
+		for key, value := range object {
+			if !strings.EqualFold(key, "blockHash") {
+				continue
+			}
+			if hash, ok := translateBlockHashParam(translation, value); ok {
+				object[key] = hash
+				objectTranslated = true
+			}
+		}
+		if objectTranslated {
+			raw, err := json.Marshal(object)
+			if err != nil {
+				continue
+			}
+			params[i] = raw
+			translated = true
+		}
+	}
+
+	if !translated {
+		return req
+	}
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return req
+	}
+	translatedReq := *req
+	translatedReq.Params = raw
+	return &translatedReq
+}
+
+func translateBlockHashParam(translation *blockhash.Translation, param json.RawMessage) (json.RawMessage, bool) {
+	var hash string
+	if err := json.Unmarshal(param, &hash); err != nil {
+		return nil, false
+	}
+	qtumHash := translation.QtumHash(hash)
+	if qtumHash == hash {
+		return nil, false
+	}
+	raw, err := json.Marshal(qtumHash)
+	if err != nil {
+		return nil, false
+	}
+	return raw, true
+}
+
+// translateResultBlockHashes replaces the Qtum block hashes of a proxy's result with Ethereum hashes.
+// Results returned by pointer are translated in place
+func translateResultBlockHashes(translation *blockhash.Translation, result interface{}) interface{} {
+	switch result := result.(type) {
+	case *eth.GetBlockByHashResponse:
+		if result == nil {
+			return result
+		}
+		translation.Block(result)
+		for i, tx := range result.Transactions {
+			result.Transactions[i] = translateResultBlockHashes(translation, tx)
+		}
+	case eth.GetTransactionByHashResponse:
+		result.BlockHash = translation.EthereumHash(result.BlockHash)
+		return result
+	case *eth.GetTransactionByHashResponse:
+		if result == nil {
+			return result
+		}
+		result.BlockHash = translation.EthereumHash(result.BlockHash)
+	case *eth.GetTransactionReceiptResponse:
+		if result == nil {
+			return result
+		}
+		result.BlockHash = translation.EthereumHash(result.BlockHash)
+		translateLogBlockHashes(translation, result.Logs)
+	case eth.GetBlockReceiptsResponse:
+		for _, receipt := range result {
+			translateResultBlockHashes(translation, receipt)
+		}
+	case *eth.GetLogsResponse:
+		if result == nil {
+			return result
+		}
+		translateLogBlockHashes(translation, *result)
+	case eth.GetFilterChangesResponse:
+		for i, change := range result {
+			switch change := change.(type) {
+			case string:
+				// the hashes of new blocks, or of new pending transactions which aren't translated
+				result[i] = translation.EthereumHash(change)
+			case eth.Log:
+				change.BlockHash = translation.EthereumHash(change.BlockHash)
+				result[i] = change
+			}
+		}
+	}
+	return result
+}
+
+func translateLogBlockHashes(translation *blockhash.Translation, logs []eth.Log) {
+	for i := range logs {
+		logs[i].BlockHash = translation.EthereumHash(logs[i].BlockHash)
+	}
+}
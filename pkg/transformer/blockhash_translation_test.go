@@ -0,0 +1,232 @@
+package transformer
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"io/ioutil"
+	"math/big"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/go-kit/log"
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/blockhash"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+)
+
+// testBlockSource is a blockhash.BlockSource serving blocks from memory
+type testBlockSource struct {
+	blocks []*eth.GetBlockByHashResponse
+}
+
+func (s *testBlockSource) Tip(ctx context.Context) (uint64, error) {
+	return uint64(len(s.blocks) - 1), nil
+}
+
+func (s *testBlockSource) QtumHash(ctx context.Context, height uint64) (common.Hash, error) {
+	if height >= uint64(len(s.blocks)) {
+		return common.Hash{}, fmt.Errorf("block %d not found", height)
+	}
+	return common.HexToHash(s.blocks[height].Hash), nil
+}
+
+func (s *testBlockSource) Block(ctx context.Context, height uint64) (*eth.GetBlockByHashResponse, error) {
+	if height >= uint64(len(s.blocks)) {
+		return nil, fmt.Errorf("block %d not found", height)
+	}
+	block := *s.blocks[height]
+	return &block, nil
+}
+
+func (s *testBlockSource) extend(count int) {
+	for i := 0; i < count; i++ {
+		height := uint64(len(s.blocks))
+		parentHash := common.Hash{}
+		if height != 0 {
+			parentHash = common.HexToHash(s.blocks[height-1].Hash)
+		}
+		s.blocks = append(s.blocks, &eth.GetBlockByHashResponse{
+			Number:           hexutil.EncodeUint64(height),
+			Hash:             common.BigToHash(new(big.Int).SetUint64(height + 1000)).Hex(),
+			ParentHash:       parentHash.Hex(),
+			Nonce:            "0x0000000000000000",
+			Miner:            "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
+			LogsBloom:        eth.EmptyLogsBloom,
+			Timestamp:        hexutil.EncodeUint64(1536551888 + height*32),
+			ExtraData:        "0x0000000000000000000000000000000000000000000000000000000000000000",
+			StateRoot:        "0x3e49216e58f1ad9e6823b5095dc532f0a6cc44943d36ff4a7b1aa474e172d672",
+			TransactionsRoot: "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
+			ReceiptsRoot:     "0x0b5f03dc9d456c63c587cc554b70c1232449be43d1df62bc25a493b04de90334",
+			Difficulty:       "0x4",
+			GasLimit:         "0x2625a00",
+			GasUsed:          "0x0",
+			Sha3Uncles:       eth.DefaultSha3Uncles,
+		})
+	}
+}
+
+// testBlockHashProxy records the parameters it's called with and returns a fixed result
+type testBlockHashProxy struct {
+	method string
+	params json.RawMessage
+	result interface{}
+}
+
+func (p *testBlockHashProxy) Method() string {
+	return p.method
+}
+
+func (p *testBlockHashProxy) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
+	p.params = rawreq.Params
+	return p.result, nil
+}
+
+func newTestBlockHashStore(t *testing.T, source *testBlockSource) *blockhash.Store {
+	dir, err := ioutil.TempDir("", "blockhash")
+	if err != nil {
+		t.Fatal(err)
+	}
+	t.Cleanup(func() { os.RemoveAll(dir) })
+
+	store, err := blockhash.NewStore(context.Background(), source, dir, log.NewNopLogger())
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := store.Sync(context.Background()); err != nil {
+		t.Fatal(err)
+	}
+	return store
+}
+
+func ethereumBlockHash(t *testing.T, store *blockhash.Store, qtumHash string) string {
+	hash, ok := store.EthereumHash(common.HexToHash(qtumHash))
+	if !ok {
+		t.Fatalf("Block %s wasn't hashed", qtumHash)
+	}
+	return hash.Hex()
+}
+
+func transformWithEthereumBlockHashes(t *testing.T, store *blockhash.Store, proxy *testBlockHashProxy, params string) interface{} {
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	transformer, err := New(qtumClient, []ETHProxy{proxy}, SetEthereumBlockHashes(store))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	result, jsonErr := transformer.Transform(&eth.JSONRPCRequest{
+		JSONRPC: "2.0",
+		Method:  proxy.method,
+		Params:  json.RawMessage(params),
+	}, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	return result
+}
+
+func TestEthereumBlockHashesTranslateParams(t *testing.T) {
+	source := &testBlockSource{}
+	source.extend(5)
+	store := newTestBlockHashStore(t, source)
+	qtumHash := source.blocks[3].Hash
+	ethereumHash := ethereumBlockHash(t, store, qtumHash)
+	unknownHash := common.HexToHash("0x1234").Hex()
+
+	tests := []struct {
+		method string
+		params string
+		want   string
+	}{
+		{"eth_getBlockByHash", `["` + ethereumHash + `",false]`, `["` + qtumHash + `",false]`},
+		{"eth_getBalance", `["0x7926223070547d2d15b2ef5e7383e541c338ffe9",{"blockHash":"` + ethereumHash + `"}]`, `["0x7926223070547d2d15b2ef5e7383e541c338ffe9",{"blockHash":"` + qtumHash + `"}]`},
+		{"eth_getBlockByHash", `["` + unknownHash + `",false]`, `["` + unknownHash + `",false]`},
+		{"eth_getTransactionReceipt", `["` + ethereumHash + `"]`, `["` + ethereumHash + `"]`},
+	}
+
+	for _, test := range tests {
+		proxy := &testBlockHashProxy{method: test.method}
+		transformWithEthereumBlockHashes(t, store, proxy, test.params)
+		if string(proxy.params) != test.want {
+			t.Errorf("%s: expected params %s, got %s", test.method, test.want, proxy.params)
+		}
+	}
+}
+
+func TestEthereumBlockHashesTranslateResults(t *testing.T) {
+	source := &testBlockSource{}
+	source.extend(5)
+	store := newTestBlockHashStore(t, source)
+
+	block := *source.blocks[4]
+	block.Transactions = []interface{}{
+		eth.GetTransactionByHashResponse{BlockHash: block.Hash, Hash: "0x11"},
+	}
+	result := transformWithEthereumBlockHashes(t, store, &testBlockHashProxy{method: "eth_getBlockByNumber", result: &block}, `["0x4",true]`)
+	got := result.(*eth.GetBlockByHashResponse)
+	if want := ethereumBlockHash(t, store, source.blocks[4].Hash); got.Hash != want {
+		t.Errorf("Expected block hash %s, got %s", want, got.Hash)
+	}
+	if want := ethereumBlockHash(t, store, source.blocks[3].Hash); got.ParentHash != want {
+		t.Errorf("Expected parent hash %s, got %s", want, got.ParentHash)
+	}
+	if tx := got.Transactions[0].(eth.GetTransactionByHashResponse); tx.BlockHash != got.Hash {
+		t.Errorf("Expected transaction block hash %s, got %s", got.Hash, tx.BlockHash)
+	}
+
+	// blocks mined since the store last synced are hashed when they are first returned
+	source.extend(1)
+	receipt := &eth.GetTransactionReceiptResponse{
+		BlockHash: source.blocks[5].Hash,
+		Logs:      []eth.Log{{BlockHash: source.blocks[5].Hash}},
+	}
+	result = transformWithEthereumBlockHashes(t, store, &testBlockHashProxy{method: "eth_getTransactionReceipt", result: receipt}, `["0x11"]`)
+	gotReceipt := result.(*eth.GetTransactionReceiptResponse)
+	want := ethereumBlockHash(t, store, source.blocks[5].Hash)
+	if gotReceipt.BlockHash != want || gotReceipt.Logs[0].BlockHash != want {
+		t.Errorf("Expected receipt and log block hash %s, got %s and %s", want, gotReceipt.BlockHash, gotReceipt.Logs[0].BlockHash)
+	}
+}
+
+func TestEthereumBlockHashesServeHashedHeaders(t *testing.T) {
+	source := &testBlockSource{}
+	source.extend(5)
+	store := newTestBlockHashStore(t, source)
+
+	// the governance contracts raised the gas limit since the block was hashed, and its receipts can't be fetched anymore
+	block := *source.blocks[4]
+	block.GasLimit = "0x2faf080"
+	block.LogsBloom = eth.FullLogsBloom
+	result := transformWithEthereumBlockHashes(t, store, &testBlockHashProxy{method: "eth_getBlockByNumber", result: &block}, `["0x4",false]`)
+
+	// a client rebuilding the header from the block it was served gets the hash it was served
+	raw, err := json.Marshal(result)
+	if err != nil {
+		t.Fatal(err)
+	}
+	var served eth.GetBlockByHashResponse
+	if err := json.Unmarshal(raw, &served); err != nil {
+		t.Fatal(err)
+	}
+	var header types.Header
+	if err := json.Unmarshal(raw, &header); err != nil {
+		t.Fatal(err)
+	}
+	if got := header.Hash().Hex(); got != served.Hash {
+		t.Errorf("Expected the served header to hash to %s, got %s", served.Hash, got)
+	}
+	if want := ethereumBlockHash(t, store, source.blocks[4].Hash); served.Hash != want {
+		t.Errorf("Expected block hash %s, got %s", want, served.Hash)
+	}
+	if want := hexutil.EncodeUint64(blockhash.HeaderGasLimit); served.GasLimit != want {
+		t.Errorf("Expected the gas limit the block was hashed with %s, got %s", want, served.GasLimit)
+	}
+}
@@ -0,0 +1,74 @@
+package transformer
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+// ProxyDebugTraceCall implements ETHProxy
+type ProxyDebugTraceCall struct {
+	*ProxyETHCall
+}
+
+func (p *ProxyDebugTraceCall) Method() string {
+	return "debug_traceCall"
+}
+
+func (p *ProxyDebugTraceCall) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
+	var req eth.TraceCallRequest
+	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
+		// TODO: Correct error code?
+		return nil, eth.NewInvalidParamsError(err.Error())
+	}
+	if jsonErr := validateTraceConfig(&req.Config); jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	return p.request(c.Request().Context(), &req)
+}
+
+func (p *ProxyDebugTraceCall) request(ctx context.Context, req *eth.TraceCallRequest) (interface{}, eth.JSONRPCError) {
+	qtumreq, jsonErr := p.ToRequest(&req.Call)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+	qtumreq.BlockNumber, jsonErr = getStateBlockNumber(ctx, p.Qtum, req.BlockNumber)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	qtumresp, err := p.CallContract(ctx, qtumreq)
+	if err != nil {
+		return nil, eth.NewCallbackError(err.Error())
+	}
+
+	frame := eth.CallFrame{
+		Type:    "CALL",
+		From:    req.Call.From,
+		To:      req.Call.To,
+		Value:   req.Call.Value,
+		Gas:     req.Call.GasHex(),
+		GasUsed: hexutil.EncodeUint64(uint64(qtumresp.ExecutionResult.GasUsed)),
+		Input:   utils.AddHexPrefix(req.Call.Data),
+		Output:  utils.AddHexPrefix(qtumresp.ExecutionResult.Output),
+		Error:   traceError(qtumresp.ExecutionResult.Excepted),
+	}
+	if frame.From == "" {
+		frame.From = utils.AddHexPrefix(qtum.ZeroAddress)
+	}
+	if frame.Value == "" {
+		frame.Value = "0x0"
+	}
+	if frame.Gas == "" {
+		// callcontract defaults to the block gas limit
+		gas, _ := utils.DecodeBig(qtum.DefaultBlockGasLimit)
+		frame.Gas = hexutil.EncodeBig(gas)
+	}
+
+	return formatTrace(&req.Config, &frame), nil
+}
@@ -0,0 +1,124 @@
+package transformer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestDebugTraceCallStructLogger(t *testing.T) {
+	request := eth.CallRequest{
+		From: "0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960",
+		To:   "0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960",
+		Data: "0x6d4ce63c",
+	}
+	requestRaw, err := json.Marshal(&request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestParamsArray := []json.RawMessage{requestRaw, []byte(`"latest"`)}
+	requestRPC, err := internal.PrepareEthRPCRequest(1, requestParamsArray)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//preparing responses
+	fromHexAddressResponse := qtum.FromHexAddressResponse("0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960")
+	err = mockedClientDoer.AddResponse(qtum.MethodFromHexAddress, fromHexAddressResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callContractResponse := qtum.CallContractResponse{Address: "1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"}
+	callContractResponse.ExecutionResult.GasUsed = 21678
+	callContractResponse.ExecutionResult.Excepted = "None"
+	callContractResponse.ExecutionResult.Output = "0000000000000000000000000000000000000000000000000000000000000001"
+	err = mockedClientDoer.AddResponse(qtum.MethodCallContract, callContractResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//preparing proxy & executing request
+	proxyEth := ProxyDebugTraceCall{&ProxyETHCall{qtumClient}}
+	got, jsonErr := proxyEth.Request(requestRPC, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	want := eth.StructLoggerResponse{
+		Gas:         21678,
+		Failed:      false,
+		ReturnValue: "0000000000000000000000000000000000000000000000000000000000000001",
+		StructLogs:  []eth.StructLog{},
+	}
+
+	internal.CheckTestResultEthRequestCall(request, &want, got, t, false)
+}
+
+func TestDebugTraceCallCallTracerReverted(t *testing.T) {
+	request := eth.CallRequest{
+		From: "0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960",
+		To:   "0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960",
+		Data: "0x6d4ce63c",
+	}
+	requestRaw, err := json.Marshal(&request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestParamsArray := []json.RawMessage{requestRaw, []byte(`"latest"`), []byte(`{"tracer":"callTracer"}`)}
+	requestRPC, err := internal.PrepareEthRPCRequest(1, requestParamsArray)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//preparing responses
+	fromHexAddressResponse := qtum.FromHexAddressResponse("0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960")
+	err = mockedClientDoer.AddResponse(qtum.MethodFromHexAddress, fromHexAddressResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callContractResponse := qtum.CallContractResponse{Address: "1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"}
+	callContractResponse.ExecutionResult.GasUsed = 21678
+	callContractResponse.ExecutionResult.Excepted = "Revert"
+	err = mockedClientDoer.AddResponse(qtum.MethodCallContract, callContractResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//preparing proxy & executing request
+	proxyEth := ProxyDebugTraceCall{&ProxyETHCall{qtumClient}}
+	got, jsonErr := proxyEth.Request(requestRPC, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	want := eth.CallFrame{
+		Type:    "CALL",
+		From:    "0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960",
+		To:      "0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960",
+		Value:   "0x0",
+		Gas:     "0x2625a00",
+		GasUsed: "0x54ae",
+		Input:   "0x6d4ce63c",
+		Output:  "0x",
+		Error:   "execution reverted",
+	}
+
+	internal.CheckTestResultEthRequestCall(request, &want, got, t, false)
+}
@@ -0,0 +1,175 @@
+package transformer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+// ProxyDebugTraceTransaction implements ETHProxy
+//
+// qtumd doesn't expose opcode level tracing, so the trace is rebuilt from the transaction's receipt
+// and the output is recovered by replaying the call with callcontract against the state of the block before it
+type ProxyDebugTraceTransaction struct {
+	*qtum.Qtum
+}
+
+func (p *ProxyDebugTraceTransaction) Method() string {
+	return "debug_traceTransaction"
+}
+
+func (p *ProxyDebugTraceTransaction) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
+	var req eth.TraceTransactionRequest
+	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
+		// TODO: Correct error code?
+		return nil, eth.NewInvalidParamsError(err.Error())
+	}
+	if req.Hash == "" {
+		// TODO: Correct error code?
+		return nil, eth.NewInvalidParamsError("empty transaction hash")
+	}
+	if jsonErr := validateTraceConfig(&req.Config); jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	return p.request(c.Request().Context(), &req)
+}
+
+func (p *ProxyDebugTraceTransaction) request(ctx context.Context, req *eth.TraceTransactionRequest) (interface{}, eth.JSONRPCError) {
+	txHash := utils.RemoveHexPrefix(req.Hash)
+
+	ethTx, jsonErr := getTransactionByHash(ctx, p.Qtum, txHash)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+	if ethTx == nil || ethTx.BlockHash == "" {
+		return nil, eth.NewCallbackError("transaction " + req.Hash + " not found")
+	}
+
+	qtumReq := qtum.GetTransactionReceiptRequest(txHash)
+	receipt, jsonErr := (&ProxyETHGetTransactionReceipt{p.Qtum}).request(ctx, &qtumReq)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	frame := eth.CallFrame{
+		Type:  "CALL",
+		From:  ethTx.From,
+		To:    ethTx.To,
+		Value: ethTx.Value,
+		Gas:   ethTx.Gas,
+		// Transfers to non contract addresses don't generate a receipt
+		GasUsed: NonContractVMGasLimit,
+		Input:   ethTx.Input,
+	}
+	if frame.Input == "" {
+		frame.Input = "0x"
+	}
+
+	if receipt != nil {
+		frame.GasUsed = receipt.GasUsed
+		if receipt.Status == STATUS_FAILURE {
+			frame.Error = p.receiptError(ctx, txHash)
+		}
+
+		if receipt.ContractAddress != "" {
+			frame.Type = "CREATE"
+			frame.To = receipt.ContractAddress
+		} else if receipt.To != "" {
+			frame.Output = p.replayCallOutput(ctx, ethTx)
+		}
+	}
+
+	return formatTrace(&req.Config, &frame), nil
+}
+
+// receiptError reports the exception of a failed transaction like eth_call does, so out of gas isn't reported as a revert
+func (p *ProxyDebugTraceTransaction) receiptError(ctx context.Context, txHash string) string {
+	qtumReceipt, err := p.GetTransactionReceipt(ctx, txHash)
+	if err != nil {
+		p.GetDebugLogger().Log("msg", "couldn't get the exception of a failed transaction", "hash", txHash, "err", err)
+		return ErrExecutionReverted.Error()
+	}
+	// the receipt doesn't have the revert data, only the revert reason
+	return executionError(qtumReceipt.Excepted, "", qtumReceipt.ExceptedMessage).Message()
+}
+
+// replayCallOutput re-executes a mined call to recover its return data, which qtumd doesn't store in receipts.
+// The call runs against the state of the block before the transaction's, without the transactions mined
+// before it in its block, so it is a best effort and an empty output is returned on failure
+func (p *ProxyDebugTraceTransaction) replayCallOutput(ctx context.Context, ethTx *eth.GetTransactionByHashResponse) string {
+	callReq := &eth.CallRequest{
+		From: ethTx.From,
+		To:   ethTx.To,
+		Data: ethTx.Input,
+	}
+	if gas, err := utils.DecodeBig(ethTx.Gas); err == nil && gas.Sign() > 0 {
+		callReq.Gas = &eth.ETHInt{Int: gas}
+	}
+
+	qtumReq, jsonErr := (&ProxyETHCall{p.Qtum}).ToRequest(callReq)
+	if jsonErr != nil {
+		p.GetDebugLogger().Log("msg", "couldn't build replay request", "hash", ethTx.Hash, "err", jsonErr.Message())
+		return ""
+	}
+	blockNumber, err := utils.DecodeBig(ethTx.BlockNumber)
+	if err != nil {
+		p.GetDebugLogger().Log("msg", "couldn't decode the block number of the replayed transaction", "hash", ethTx.Hash, "err", err)
+		return ""
+	}
+	if blockNumber.Sign() > 0 {
+		qtumReq.BlockNumber = blockNumber.Sub(blockNumber, big.NewInt(1))
+	}
+
+	qtumResp, err := p.CallContract(ctx, qtumReq)
+	if err != nil {
+		p.GetDebugLogger().Log("msg", "couldn't replay transaction", "hash", ethTx.Hash, "err", err)
+		return ""
+	}
+
+	return utils.AddHexPrefix(qtumResp.ExecutionResult.Output)
+}
+
+func validateTraceConfig(config *eth.TraceConfig) eth.JSONRPCError {
+	switch config.Tracer {
+	case eth.StructLogger, eth.CallTracer:
+		return nil
+	default:
+		return eth.NewInvalidParamsError("unsupported tracer: " + config.Tracer)
+	}
+}
+
+// formatTrace renders a call frame with the tracer requested in config.
+// The struct logger can only report the outcome of the call since qtumd doesn't give access to the executed opcodes
+func formatTrace(config *eth.TraceConfig, frame *eth.CallFrame) interface{} {
+	if config.Tracer == eth.CallTracer {
+		return frame
+	}
+
+	gasUsed, _ := hexutil.DecodeUint64(frame.GasUsed)
+	return &eth.StructLoggerResponse{
+		Gas:         gasUsed,
+		Failed:      frame.Error != "",
+		ReturnValue: utils.RemoveHexPrefix(frame.Output),
+		StructLogs:  []eth.StructLog{},
+	}
+}
+
+// traceError converts the excepted field of a qtumd execution result to a geth style error
+func traceError(excepted string) string {
+	switch excepted {
+	case "", "None":
+		return ""
+	case "Revert":
+		return ErrExecutionReverted.Error()
+	case "OutOfGas":
+		return "out of gas"
+	default:
+		return excepted
+	}
+}
@@ -0,0 +1,232 @@
+package transformer
+
+import (
+	"bytes"
+	"encoding/json"
+	"io/ioutil"
+	"net/http"
+	"sync"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/shopspring/decimal"
+)
+
+func TestDebugTraceTransactionCallTracer(t *testing.T) {
+	//preparing request
+	requestParams := []json.RawMessage{
+		[]byte(`"0xd20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451"`),
+		[]byte(`{"tracer":"callTracer"}`),
+	}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// Using data from https://qtum.info/tx/d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451
+	internal.SetupGetBlockByHashResponsesWithVouts(
+		t,
+		[]*qtum.DecodedRawTransactionOutV{
+			{
+				Value: decimal.Zero,
+				N:     0,
+				ScriptPubKey: qtum.DecodedRawTransactionScriptPubKey{
+					Hex:       "540390d003012844095ea7b300000000000000000000000025495b3a87d82e9d7a71b341addfc0d7bb3475c7ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff1454fefdb5b31164f66ddb68becd7bdd864cacd65bc2",
+					Addresses: []string{},
+				},
+			},
+		},
+		mockedClientDoer,
+	)
+
+	// replace the empty receipt from the shared setup
+	delete(mockedClientDoer.Responses, qtum.MethodGetTransactionReceipt)
+	receipt := internal.QtumTransactionReceipt(nil)
+	receipt.To = "54fefdb5b31164f66ddb68becd7bdd864cacd65b"
+	receipt.ContractAddress = ""
+	receipt.GasUsed = 25548
+	receipt.Excepted = "None"
+	err = mockedClientDoer.AddResponse(qtum.MethodGetTransactionReceipt, []qtum.TransactionReceipt{receipt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mockedClientDoer.AddResponse(qtum.MethodFromHexAddress, qtum.FromHexAddressResponse("qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callContractResponse := qtum.CallContractResponse{}
+	callContractResponse.ExecutionResult.GasUsed = 25548
+	callContractResponse.ExecutionResult.Excepted = "None"
+	callContractResponse.ExecutionResult.Output = "0000000000000000000000000000000000000000000000000000000000000001"
+	err = mockedClientDoer.AddResponse(qtum.MethodCallContract, callContractResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//preparing proxy & executing request
+	proxyEth := ProxyDebugTraceTransaction{qtumClient}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	want := eth.CallFrame{
+		Type:    "CALL",
+		From:    "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
+		To:      "0x54fefdb5b31164f66ddb68becd7bdd864cacd65b",
+		Value:   "0x0",
+		Gas:     "0x3d090",
+		GasUsed: "0x63cc",
+		Input:   "0x095ea7b300000000000000000000000025495b3a87d82e9d7a71b341addfc0d7bb3475c7ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff",
+		Output:  "0x0000000000000000000000000000000000000000000000000000000000000001",
+	}
+
+	internal.CheckTestResultEthRequestRPC(*request, &want, got, t, false)
+}
+
+func TestDebugTraceTransactionOutOfGas(t *testing.T) {
+	requestParams := []json.RawMessage{
+		[]byte(`"0xd20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451"`),
+		[]byte(`{"tracer":"callTracer"}`),
+	}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	doer := &callContractRecordingDoer{Doer: mockedClientDoer}
+	qtumClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	internal.SetupGetBlockByHashResponsesWithVouts(
+		t,
+		[]*qtum.DecodedRawTransactionOutV{
+			{
+				Value: decimal.Zero,
+				N:     0,
+				ScriptPubKey: qtum.DecodedRawTransactionScriptPubKey{
+					Hex:       "540390d003012844095ea7b300000000000000000000000025495b3a87d82e9d7a71b341addfc0d7bb3475c7ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff1454fefdb5b31164f66ddb68becd7bdd864cacd65bc2",
+					Addresses: []string{},
+				},
+			},
+		},
+		doer,
+	)
+
+	delete(mockedClientDoer.Responses, qtum.MethodGetTransactionReceipt)
+	receipt := internal.QtumTransactionReceipt(nil)
+	receipt.To = "54fefdb5b31164f66ddb68becd7bdd864cacd65b"
+	receipt.ContractAddress = ""
+	receipt.GasUsed = 250000
+	receipt.Excepted = "OutOfGas"
+	err = doer.AddResponse(qtum.MethodGetTransactionReceipt, []qtum.TransactionReceipt{receipt})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = doer.AddResponse(qtum.MethodFromHexAddress, qtum.FromHexAddressResponse("qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callContractResponse := qtum.CallContractResponse{}
+	callContractResponse.ExecutionResult.GasUsed = 250000
+	callContractResponse.ExecutionResult.Excepted = "OutOfGas"
+	err = doer.AddResponse(qtum.MethodCallContract, callContractResponse)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyDebugTraceTransaction{qtumClient}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	frame, ok := got.(*eth.CallFrame)
+	if !ok {
+		t.Fatalf("Expected a call frame, got %T", got)
+	}
+	if frame.Error != "out of gas" {
+		t.Errorf("Expected the out of gas to be reported as it is, got %q", frame.Error)
+	}
+
+	// the call is replayed against the state before the transaction's block
+	if len(doer.params) != 1 {
+		t.Fatalf("Expected one callcontract call, got %d", len(doer.params))
+	}
+	params := doer.params[0]
+	if len(params) != 6 || string(params[5]) != "3982" {
+		t.Errorf("Expected the call to be replayed at height 3982, got the params %s", params)
+	}
+}
+
+// callContractRecordingDoer records the params of every callcontract call
+type callContractRecordingDoer struct {
+	internal.Doer
+	mutex  sync.Mutex
+	params [][]json.RawMessage
+}
+
+func (d *callContractRecordingDoer) Do(request *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var rpcRequest struct {
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(body, &rpcRequest); err != nil {
+		return nil, err
+	}
+	if rpcRequest.Method == qtum.MethodCallContract {
+		d.mutex.Lock()
+		d.params = append(d.params, rpcRequest.Params)
+		d.mutex.Unlock()
+	}
+
+	return d.Doer.Do(request)
+}
+
+func TestDebugTraceTransactionUnsupportedTracer(t *testing.T) {
+	requestParams := []json.RawMessage{
+		[]byte(`"0xd20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451"`),
+		[]byte(`{"tracer":"prestateTracer"}`),
+	}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyDebugTraceTransaction{qtumClient}
+	_, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr == nil {
+		t.Fatal("Expected an error for an unsupported tracer")
+	}
+
+	want := eth.NewInvalidParamsError("unsupported tracer: prestateTracer")
+
+	internal.CheckTestResultEthRequestRPC(*request, want, jsonErr, t, false)
+}
@@ -2,6 +2,7 @@ package transformer
 
 import (
 	"context"
+	"encoding/hex"
 	"math/big"
 
 	"github.com/labstack/echo"
@@ -35,6 +36,10 @@ func (p *ProxyETHCall) request(ctx context.Context, ethreq *eth.CallRequest) (in
 	if jsonErr != nil {
 		return nil, jsonErr
 	}
+	qtumreq.BlockNumber, jsonErr = getStateBlockNumber(ctx, p.Qtum, ethreq.BlockNumber)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
 	if qtumreq.GasLimit != nil && qtumreq.GasLimit.Cmp(big.NewInt(40000000)) > 0 {
 		qtumresp := eth.CallResponse("0x")
 		p.Qtum.GetLogger().Log("msg", "Caller gas above allowance, capping", "requested", qtumreq.GasLimit.Int64(), "cap", "40,000,000")
@@ -52,10 +57,19 @@ func (p *ProxyETHCall) request(ctx context.Context, ethreq *eth.CallRequest) (in
 	}
 
 	// qtum res -> eth res
-	return p.ToResponse(qtumresp), nil
+	ethresp, jsonErr := p.ToResponse(qtumresp)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+	return ethresp, nil
 }
 
 func (p *ProxyETHCall) ToRequest(ethreq *eth.CallRequest) (*qtum.CallContractRequest, eth.JSONRPCError) {
+	// failing is better than returning the result of a call that ignored the overrides
+	if ethreq.HasStateOverrides() {
+		return nil, eth.NewUnsupportedError("state overrides aren't supported, qtumd can only run calls against the chain's state")
+	}
+
 	from := ethreq.From
 	var err error
 	if utils.IsEthHexAddress(from) {
@@ -82,17 +96,30 @@ func (p *ProxyETHCall) ToRequest(ethreq *eth.CallRequest) (*qtum.CallContractReq
 	}, nil
 }
 
-func (p *ProxyETHCall) ToResponse(qresp *qtum.CallContractResponse) interface{} {
-	if qresp.ExecutionResult.Output == "" {
-		return eth.NewJSONRPCError(
-			-32000,
-			"Revert: executionResult output is empty",
-			nil,
-		)
+func (p *ProxyETHCall) ToResponse(qresp *qtum.CallContractResponse) (*eth.CallResponse, eth.JSONRPCError) {
+	if qresp.ExecutionResult.Excepted != "" && qresp.ExecutionResult.Excepted != "None" {
+		return nil, executionError(qresp.ExecutionResult.Excepted, qresp.ExecutionResult.Output, qresp.ExecutionResult.ExceptedMessage)
 	}
 
 	data := utils.AddHexPrefix(qresp.ExecutionResult.Output)
 	qtumresp := eth.CallResponse(data)
-	return &qtumresp
+	return &qtumresp, nil
+}
+
+// executionError converts the exception of a qtumd execution to the error geth returns.
+// Reverts return their revert data with code 3, qtumd's exceptedMessage is packed as Error(string) when there is no output,
+// other exceptions like running out of gas return their reason
+func executionError(excepted string, output string, exceptedMessage string) eth.JSONRPCError {
+	if excepted != "Revert" {
+		return eth.NewCallbackError(traceError(excepted))
+	}
 
+	data, err := hex.DecodeString(utils.RemoveHexPrefix(output))
+	if err != nil {
+		data = nil
+	}
+	if len(data) == 0 && exceptedMessage != "" {
+		data = eth.PackRevertReason(exceptedMessage)
+	}
+	return eth.NewRevertError(data)
 }
@@ -1,10 +1,12 @@
 package transformer
 
 import (
+	"encoding/hex"
 	"encoding/json"
 	"testing"
 	"time"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/internal"
 	"github.com/qtumproject/janus/pkg/qtum"
@@ -46,10 +48,10 @@ func TestEthCallRequest(t *testing.T) {
 			Output:     "0000000000000000000000000000000000000000000000000000000000000001",
 		},
 		TransactionReceipt: struct {
-			StateRoot string        `json:"stateRoot"`
-			GasUsed   int           `json:"gasUsed"`
-			Bloom     string        `json:"bloom"`
-			Log       []interface{} `json:"log"`
+			StateRoot string     `json:"stateRoot"`
+			GasUsed   int        `json:"gasUsed"`
+			Bloom     string     `json:"bloom"`
+			Log       []qtum.Log `json:"log"`
 		}{
 			StateRoot: "d44fc5ad43bae52f01ff7eb4a7bba904ee52aea6c41f337aa29754e57c73fba6",
 			GasUsed:   21678,
@@ -119,10 +121,10 @@ func TestRetry(t *testing.T) {
 			Output:     "0000000000000000000000000000000000000000000000000000000000000001",
 		},
 		TransactionReceipt: struct {
-			StateRoot string        `json:"stateRoot"`
-			GasUsed   int           `json:"gasUsed"`
-			Bloom     string        `json:"bloom"`
-			Log       []interface{} `json:"log"`
+			StateRoot string     `json:"stateRoot"`
+			GasUsed   int        `json:"gasUsed"`
+			Bloom     string     `json:"bloom"`
+			Log       []qtum.Log `json:"log"`
 		}{
 			StateRoot: "d44fc5ad43bae52f01ff7eb4a7bba904ee52aea6c41f337aa29754e57c73fba6",
 			GasUsed:   21678,
@@ -211,3 +213,92 @@ func TestEthCallRequestOnUnknownContract(t *testing.T) {
 
 	internal.CheckTestResultEthRequestCall(request, &want, got, t, false)
 }
+
+func TestEthCallToResponseErrors(t *testing.T) {
+	panicData := "4e487b710000000000000000000000000000000000000000000000000000000000000011"
+	// InsufficientBalance(uint256), a custom error
+	customErrorData := "cf4791810000000000000000000000000000000000000000000000000000000000000064"
+	reasonData := hex.EncodeToString(eth.PackRevertReason("Ownable: caller is not the owner"))
+
+	tests := []struct {
+		excepted        string
+		output          string
+		exceptedMessage string
+		want            eth.JSONRPCError
+		wantMessage     string
+	}{
+		{"Revert", reasonData, "", eth.NewRevertError(eth.PackRevertReason("Ownable: caller is not the owner")), "execution reverted: Ownable: caller is not the owner"},
+		{"Revert", panicData, "", eth.NewRevertError(common.FromHex(panicData)), "execution reverted: arithmetic underflow or overflow"},
+		{"Revert", customErrorData, "", eth.NewRevertError(common.FromHex(customErrorData)), "execution reverted"},
+		// qtumd's decoded reason is used when the revert data is missing
+		{"Revert", "", "Ownable: caller is not the owner", eth.NewRevertError(eth.PackRevertReason("Ownable: caller is not the owner")), "execution reverted: Ownable: caller is not the owner"},
+		{"OutOfGas", "", "", eth.NewCallbackError("out of gas"), "out of gas"},
+	}
+
+	proxyEth := ProxyETHCall{}
+	for _, test := range tests {
+		var qtumresp qtum.CallContractResponse
+		qtumresp.ExecutionResult.Excepted = test.excepted
+		qtumresp.ExecutionResult.Output = test.output
+		qtumresp.ExecutionResult.ExceptedMessage = test.exceptedMessage
+
+		got, jsonErr := proxyEth.ToResponse(&qtumresp)
+		if got != nil || jsonErr == nil {
+			t.Fatalf("%s %s: expected an error, got %v", test.excepted, test.output, got)
+		}
+		internal.CheckTestResultDefault(test.want, jsonErr, t, false)
+		if jsonErr.Message() != test.wantMessage {
+			t.Errorf("%s %s: expected message %q, got %q", test.excepted, test.output, test.wantMessage, jsonErr.Message())
+		}
+	}
+
+	// calls that return nothing, like calls to addresses without code, succeed
+	var qtumresp qtum.CallContractResponse
+	qtumresp.ExecutionResult.Excepted = "None"
+	got, jsonErr := proxyEth.ToResponse(&qtumresp)
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if *got != "0x" {
+		t.Errorf("Expected 0x, got %s", *got)
+	}
+}
+
+func TestEthCallRequestStateOverrides(t *testing.T) {
+	request := eth.CallRequest{To: "0xdb46f738bf32cdafb9a4a70eb8b44c76646bcaf0", Data: "0x06fdde03"}
+	requestRaw, err := json.Marshal(&request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	overrides := `{"0xdb46f738bf32cdafb9a4a70eb8b44c76646bcaf0":{"balance":"0x64"}}`
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	ethCall := &ProxyETHCall{qtumClient}
+
+	tests := []struct {
+		proxy  ETHProxy
+		params []json.RawMessage
+	}{
+		{ethCall, []json.RawMessage{requestRaw, []byte(`"latest"`), []byte(overrides)}},
+		{&ProxyETHEstimateGas{ProxyETHCall: ethCall}, []json.RawMessage{requestRaw, []byte(`"latest"`), []byte(overrides)}},
+		{&ProxyETHCreateAccessList{ProxyETHCall: ethCall}, []json.RawMessage{requestRaw, []byte(`"latest"`), []byte(overrides)}},
+		// debug_traceCall takes them in its trace config
+		{&ProxyDebugTraceCall{ethCall}, []json.RawMessage{requestRaw, []byte(`"latest"`), []byte(`{"stateOverrides":` + overrides + `}`)}},
+	}
+	for _, test := range tests {
+		requestRPC, err := internal.PrepareEthRPCRequest(1, test.params)
+		if err != nil {
+			t.Fatal(err)
+		}
+
+		// the call fails before reaching qtumd, instead of returning the result of a call that ignored the overrides
+		_, jsonErr := test.proxy.Request(requestRPC, internal.NewEchoContext())
+		if jsonErr == nil || jsonErr.Code() != eth.UnsupportedErrorCode {
+			t.Errorf("%s: expected an unsupported error, got %v", test.proxy.Method(), jsonErr)
+		}
+	}
+}
@@ -0,0 +1,107 @@
+package transformer
+
+import (
+	"context"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+// ProxyETHCreateAccessList implements ETHProxy
+type ProxyETHCreateAccessList struct {
+	*ProxyETHCall
+}
+
+func (p *ProxyETHCreateAccessList) Method() string {
+	return "eth_createAccessList"
+}
+
+func (p *ProxyETHCreateAccessList) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
+	var req eth.CallRequest
+	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
+		// TODO: Correct error code?
+		return nil, eth.NewInvalidParamsError(err.Error())
+	}
+
+	return p.request(c.Request().Context(), &req)
+}
+
+func (p *ProxyETHCreateAccessList) request(ctx context.Context, ethreq *eth.CallRequest) (*eth.CreateAccessListResponse, eth.JSONRPCError) {
+	qtumreq, jsonErr := p.ToRequest(ethreq)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+	qtumreq.BlockNumber, jsonErr = getStateBlockNumber(ctx, p.Qtum, ethreq.BlockNumber)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	qtumresp, err := p.CallContract(ctx, qtumreq)
+	if err != nil {
+		return nil, eth.NewCallbackError(err.Error())
+	}
+
+	resp := &eth.CreateAccessListResponse{
+		AccessList: accessList(ethreq, qtumresp),
+		GasUsed:    hexutil.EncodeUint64(uint64(qtumresp.ExecutionResult.GasUsed)),
+	}
+	if excepted := qtumresp.ExecutionResult.Excepted; excepted != "" && excepted != "None" {
+		resp.Error = executionError(excepted, qtumresp.ExecutionResult.Output, qtumresp.ExecutionResult.ExceptedMessage).Message()
+	}
+	return resp, nil
+}
+
+// accessList returns the access list of the request with the contracts that emitted logs during the call added.
+//
+// callcontract doesn't report the storage slots a call reads or writes, or the contracts it calls without them logging,
+// so those can't be added. Like geth, the sender and the called contract are left out since they are always warm
+func accessList(ethreq *eth.CallRequest, qtumresp *qtum.CallContractResponse) types.AccessList {
+	excluded := map[common.Address]bool{}
+	if utils.IsEthHexAddress(ethreq.From) {
+		excluded[common.HexToAddress(ethreq.From)] = true
+	}
+	if ethreq.To != "" {
+		excluded[common.HexToAddress(ethreq.To)] = true
+	}
+
+	list := types.AccessList{}
+	indexes := map[common.Address]int{}
+	add := func(address common.Address, storageKeys []common.Hash) {
+		if excluded[address] {
+			return
+		}
+		index, ok := indexes[address]
+		if !ok {
+			index = len(list)
+			indexes[address] = index
+			list = append(list, types.AccessTuple{Address: address, StorageKeys: []common.Hash{}})
+		}
+		for _, key := range storageKeys {
+			if !containsHash(list[index].StorageKeys, key) {
+				list[index].StorageKeys = append(list[index].StorageKeys, key)
+			}
+		}
+	}
+
+	for _, tuple := range ethreq.AccessList {
+		add(tuple.Address, tuple.StorageKeys)
+	}
+	for _, log := range qtumresp.TransactionReceipt.Log {
+		add(common.HexToAddress(log.Address), nil)
+	}
+	return list
+}
+
+func containsHash(hashes []common.Hash, hash common.Hash) bool {
+	for _, h := range hashes {
+		if h == hash {
+			return true
+		}
+	}
+	return false
+}
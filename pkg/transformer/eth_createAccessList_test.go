@@ -0,0 +1,118 @@
+package transformer
+
+import (
+	"encoding/hex"
+	"encoding/json"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestCreateAccessListRequest(t *testing.T) {
+	storageKey := common.HexToHash("0x01")
+	request := eth.CallRequest{
+		From: "0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960",
+		To:   "0xdb46f738bf32cdafb9a4a70eb8b44c76646bcaf0",
+		Data: "0x0",
+		AccessList: types.AccessList{
+			{Address: common.HexToAddress("0x7926223070547d2d15b2ef5e7383e541c338ffe9"), StorageKeys: []common.Hash{storageKey}},
+		},
+	}
+	requestRaw, err := json.Marshal(&request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestRPC, err := internal.PrepareEthRPCRequest(1, []json.RawMessage{requestRaw})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the called contract and a token it transfers with both log
+	callContractResponse := qtum.CallContractResponse{}
+	callContractResponse.ExecutionResult.GasUsed = 51234
+	callContractResponse.ExecutionResult.Excepted = "None"
+	callContractResponse.TransactionReceipt.Log = []qtum.Log{
+		{Address: "db46f738bf32cdafb9a4a70eb8b44c76646bcaf0"},
+		{Address: "f1b2c3d4e5f60718293a4b5c6d7e8f9012345678"},
+	}
+	responses := []struct {
+		method   string
+		response interface{}
+	}{
+		{qtum.MethodFromHexAddress, qtum.FromHexAddressResponse("qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW")},
+		{qtum.MethodCallContract, callContractResponse},
+	}
+	for _, r := range responses {
+		if err := mockedClientDoer.AddResponse(r.method, r.response); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	proxyEth := ProxyETHCreateAccessList{&ProxyETHCall{qtumClient}}
+	got, jsonErr := proxyEth.Request(requestRPC, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	want := &eth.CreateAccessListResponse{
+		AccessList: types.AccessList{
+			{Address: common.HexToAddress("0x7926223070547d2d15b2ef5e7383e541c338ffe9"), StorageKeys: []common.Hash{storageKey}},
+			{Address: common.HexToAddress("0xf1b2c3d4e5f60718293a4b5c6d7e8f9012345678"), StorageKeys: []common.Hash{}},
+		},
+		GasUsed: "0xc822",
+	}
+	internal.CheckTestResultEthRequestCall(request, want, got, t, false)
+}
+
+func TestCreateAccessListRequestReverted(t *testing.T) {
+	request := eth.CallRequest{
+		To:   "0xdb46f738bf32cdafb9a4a70eb8b44c76646bcaf0",
+		Data: "0x0",
+	}
+	requestRaw, err := json.Marshal(&request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestRPC, err := internal.PrepareEthRPCRequest(1, []json.RawMessage{requestRaw})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callContractResponse := qtum.CallContractResponse{}
+	callContractResponse.ExecutionResult.GasUsed = 23000
+	callContractResponse.ExecutionResult.Excepted = "Revert"
+	callContractResponse.ExecutionResult.Output = hex.EncodeToString(eth.PackRevertReason("not allowed"))
+	if err := mockedClientDoer.AddResponse(qtum.MethodCallContract, callContractResponse); err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHCreateAccessList{&ProxyETHCall{qtumClient}}
+	got, jsonErr := proxyEth.Request(requestRPC, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	// the failure is returned with the result, not as an error, like geth
+	want := &eth.CreateAccessListResponse{
+		AccessList: types.AccessList{},
+		GasUsed:    "0x59d8",
+		Error:      "execution reverted: not allowed",
+	}
+	internal.CheckTestResultEthRequestCall(request, want, got, t, false)
+}
@@ -1,6 +1,9 @@
 package transformer
 
 import (
+	"context"
+	"math/big"
+
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
@@ -12,9 +15,13 @@ import (
 var NonContractVMGasLimit = "0x55f0"
 var ErrExecutionReverted = errors.New("execution reverted")
 
-// 10% isn't enough in some cases, neither is 15%, 20% works
+// the gas used with the gas limit of the block is tried with this buffer first, which is usually enough,
+// the binary search then only has to narrow down between the gas used and the buffered gas
 var GAS_BUFFER = 1.20
 
+// the binary search stops once the estimate is within this ratio of the minimal gas limit, like geth
+var estimateGasErrorRatio = 0.015
+
 // ProxyETHEstimateGas implements ETHProxy
 type ProxyETHEstimateGas struct {
 	*ProxyETHCall
@@ -36,35 +43,91 @@ func (p *ProxyETHEstimateGas) Request(rawreq *eth.JSONRPCRequest, c echo.Context
 		return &response, nil
 	}
 
-	// when supplying this parameter to callcontract to estimate gas in the qtum api
-	// if there isn't enough gas specified here, the result will be an exception
-	// Excepted = "OutOfGasIntrinsic"
-	// Gas = "the supplied value"
-	// this is different from geth's behavior
-	// which will return a used gas value that is higher than the incoming gas parameter
-	// so we set this to nil so that callcontract will return the actual gas estimate
+	return p.request(c.Request().Context(), &ethreq)
+}
+
+func (p *ProxyETHEstimateGas) request(ctx context.Context, ethreq *eth.CallRequest) (*eth.EstimateGasResponse, eth.JSONRPCError) {
+	// the gas of the request caps the estimate, like the block gas limit
+	hi, err := hexutil.DecodeUint64(getBlockGasLimit(ctx, p.Qtum))
+	if err != nil {
+		return nil, eth.NewCallbackError(err.Error())
+	}
+	if ethreq.Gas != nil && ethreq.Gas.Int != nil && ethreq.Gas.IsUint64() && ethreq.Gas.Uint64() < hi {
+		hi = ethreq.Gas.Uint64()
+	}
+
+	// callcontract fails with Excepted = "OutOfGasIntrinsic" instead of returning the gas it needs when the gas limit is too low,
+	// so every call is made with an explicit gas limit and the gas limit is searched for
 	ethreq.Gas = nil
 
 	// eth req -> qtum req
-	qtumreq, jsonErr := p.ToRequest(&ethreq)
+	qtumreq, jsonErr := p.ToRequest(ethreq)
 	if jsonErr != nil {
 		return nil, jsonErr
 	}
 
 	// qtum [code: -5] Incorrect address occurs here
-	qtumresp, err := p.CallContract(c.Request().Context(), qtumreq)
+	qtumresp, err := p.callWithGas(ctx, qtumreq, hi)
 	if err != nil {
 		return nil, eth.NewCallbackError(err.Error())
 	}
+	if qtumresp.ExecutionResult.Excepted != "None" {
+		// the call fails with all the gas it can get, it isn't going to succeed with less
+		return nil, executionError(qtumresp.ExecutionResult.Excepted, qtumresp.ExecutionResult.Output, qtumresp.ExecutionResult.ExceptedMessage)
+	}
 
-	return p.toResp(qtumresp)
-}
+	// gasUsed is after refunds, and a call forwards only 63/64 of the gas left to the calls it makes,
+	// so the call can need more gas than it uses, but never less
+	lo := uint64(qtumresp.ExecutionResult.GasUsed) - 1
+	if qtumresp.ExecutionResult.GasUsed == 0 {
+		lo = 0
+	}
 
-func (p *ProxyETHEstimateGas) toResp(qtumresp *qtum.CallContractResponse) (*eth.EstimateGasResponse, eth.JSONRPCError) {
-	if qtumresp.ExecutionResult.Excepted != "None" {
-		return nil, eth.NewCallbackError(ErrExecutionReverted.Error())
+	if buffered := uint64(float64(qtumresp.ExecutionResult.GasUsed) * GAS_BUFFER); buffered > lo && buffered < hi {
+		ok, err := p.succeedsWithGas(ctx, qtumreq, buffered)
+		if err != nil {
+			return nil, eth.NewCallbackError(err.Error())
+		}
+		if ok {
+			hi = buffered
+		} else {
+			lo = buffered
+		}
+	}
+
+	for lo+1 < hi {
+		if float64(hi-lo)/float64(hi) < estimateGasErrorRatio {
+			break
+		}
+		mid := lo + (hi-lo)/2
+		ok, err := p.succeedsWithGas(ctx, qtumreq, mid)
+		if err != nil {
+			return nil, eth.NewCallbackError(err.Error())
+		}
+		if ok {
+			hi = mid
+		} else {
+			lo = mid
+		}
 	}
-	gas := eth.EstimateGasResponse(hexutil.EncodeUint64(uint64(float64(qtumresp.ExecutionResult.GasUsed) * GAS_BUFFER)))
+
+	gas := eth.EstimateGasResponse(hexutil.EncodeUint64(hi))
 	p.GetDebugLogger().Log(p.Method(), gas)
 	return &gas, nil
 }
+
+func (p *ProxyETHEstimateGas) callWithGas(ctx context.Context, qtumreq *qtum.CallContractRequest, gas uint64) (*qtum.CallContractResponse, error) {
+	req := *qtumreq
+	req.GasLimit = new(big.Int).SetUint64(gas)
+	return p.CallContract(ctx, &req)
+}
+
+// succeedsWithGas returns whether the call succeeds with a gas limit, any exception counts as running out of gas
+// since calls can revert when they are forwarded too little gas
+func (p *ProxyETHEstimateGas) succeedsWithGas(ctx context.Context, qtumreq *qtum.CallContractRequest, gas uint64) (bool, error) {
+	qtumresp, err := p.callWithGas(ctx, qtumreq, gas)
+	if err != nil {
+		return false, err
+	}
+	return qtumresp.ExecutionResult.Excepted == "None", nil
+}
@@ -1,7 +1,9 @@
 package transformer
 
 import (
+	"encoding/hex"
 	"encoding/json"
+	"math/big"
 	"testing"
 
 	"github.com/qtumproject/janus/pkg/eth"
@@ -38,6 +40,10 @@ func TestEstimateGasRequest(t *testing.T) {
 	if err != nil {
 		t.Fatal(err)
 	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetDGPInfo, qtum.GetDGPInfoResponse{BlockGasLimit: 40000000})
+	if err != nil {
+		t.Fatal(err)
+	}
 
 	callContractResponse := qtum.CallContractResponse{
 		Address: "1e6f89d7399081b4f8f8aa1ae2805a5efff2f960",
@@ -69,8 +75,66 @@ func TestEstimateGasRequest(t *testing.T) {
 		t.Fatal(jsonErr)
 	}
 
-	want := eth.EstimateGasResponse("0x659d")
+	// the call succeeds with any gas limit above the gas it uses, the search stops within 1.5% of it
+	want := eth.EstimateGasResponse("0x55bc")
+
+	internal.CheckTestResultEthRequestCall(request, &want, got, t, false)
+}
+
+func TestEstimateGasRequestBinarySearch(t *testing.T) {
+	request := eth.CallRequest{
+		From: "0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960",
+		To:   "0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960",
+		Gas:  &eth.ETHInt{Int: big.NewInt(100000)},
+		Data: "0x0",
+	}
+	requestRaw, err := json.Marshal(&request)
+	if err != nil {
+		t.Fatal(err)
+	}
+	requestRPC, err := internal.PrepareEthRPCRequest(1, []json.RawMessage{requestRaw})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the call uses 50000 gas after a refund, and runs out of gas with the 20% buffer
+	succeeded := qtum.CallContractResponse{}
+	succeeded.ExecutionResult.GasUsed = 50000
+	succeeded.ExecutionResult.Excepted = "None"
+	outOfGas := qtum.CallContractResponse{}
+	outOfGas.ExecutionResult.GasUsed = 60000
+	outOfGas.ExecutionResult.Excepted = "OutOfGas"
+	responses := []struct {
+		method   string
+		response interface{}
+	}{
+		{qtum.MethodFromHexAddress, qtum.FromHexAddressResponse("0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960")},
+		{qtum.MethodGetDGPInfo, qtum.GetDGPInfoResponse{BlockGasLimit: 40000000}},
+		{qtum.MethodCallContract, succeeded},
+		{qtum.MethodCallContract, outOfGas},
+		{qtum.MethodCallContract, succeeded},
+	}
+	for _, r := range responses {
+		if err := mockedClientDoer.AddResponse(r.method, r.response); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	proxyEth := ProxyETHCall{qtumClient}
+	proxyEthEstimateGas := ProxyETHEstimateGas{&proxyEth}
+	got, jsonErr := proxyEthEstimateGas.Request(requestRPC, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
 
+	// searched between the buffered 60000 gas and the 100000 gas of the request
+	want := eth.EstimateGasResponse("0xecd1")
 	internal.CheckTestResultEthRequestCall(request, &want, got, t, false)
 }
 
@@ -98,6 +162,7 @@ func TestEstimateGasRequestExecutionReverted(t *testing.T) {
 	}
 
 	//preparing responses
+	revertData := eth.PackRevertReason("insufficient balance")
 	fromHexAddressResponse := qtum.FromHexAddressResponse("0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960")
 	err = mockedClientDoer.AddResponseWithRequestID(2, qtum.MethodFromHexAddress, fromHexAddressResponse)
 	if err != nil {
@@ -118,7 +183,8 @@ func TestEstimateGasRequestExecutionReverted(t *testing.T) {
 			GasForDeposit   int    `json:"gasForDeposit"`
 		}{
 			GasUsed:  21678,
-			Excepted: "OutOfGas",
+			Excepted: "Revert",
+			Output:   hex.EncodeToString(revertData),
 		},
 	}
 	err = mockedClientDoer.AddResponseWithRequestID(1, qtum.MethodCallContract, callContractResponse)
@@ -132,7 +198,10 @@ func TestEstimateGasRequestExecutionReverted(t *testing.T) {
 
 	_, got := proxyEthEstimateGas.Request(requestRPC, internal.NewEchoContext())
 
-	want := eth.NewCallbackError(ErrExecutionReverted.Error())
+	want := eth.NewRevertError(revertData)
+	if want.Message() != "execution reverted: insufficient balance" {
+		t.Errorf("Unexpected revert message %s", want.Message())
+	}
 
 	internal.CheckTestResultDefault(want, got, t, false)
 }
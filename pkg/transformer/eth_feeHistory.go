@@ -0,0 +1,193 @@
+package transformer
+
+import (
+	"context"
+	"math/big"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/labstack/echo"
+	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+// maxFeeHistoryBlocks is the most blocks eth_feeHistory returns, like geth
+const maxFeeHistoryBlocks = 1024
+
+// ProxyETHFeeHistory implements ETHProxy
+type ProxyETHFeeHistory struct {
+	*qtum.Qtum
+}
+
+func (p *ProxyETHFeeHistory) Method() string {
+	return "eth_feeHistory"
+}
+
+func (p *ProxyETHFeeHistory) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
+	var req eth.FeeHistoryRequest
+	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
+		// TODO: Correct error code?
+		return nil, eth.NewInvalidParamsError(err.Error())
+	}
+
+	return p.request(c.Request().Context(), &req)
+}
+
+func (p *ProxyETHFeeHistory) request(ctx context.Context, req *eth.FeeHistoryRequest) (*eth.FeeHistoryResponse, eth.JSONRPCError) {
+	newestBlock, jsonErr := getBlockNumberByRawParam(ctx, p.Qtum, req.NewestBlock, false)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	blockCount := req.BlockCount
+	if blockCount > maxFeeHistoryBlocks {
+		blockCount = maxFeeHistoryBlocks
+	}
+	// the range can't go past the genesis block
+	if blockCount > newestBlock.Uint64()+1 {
+		blockCount = newestBlock.Uint64() + 1
+	}
+	oldestBlock := newestBlock.Uint64() + 1 - blockCount
+
+	resp := &eth.FeeHistoryResponse{
+		OldestBlock:   hexutil.EncodeUint64(oldestBlock),
+		BaseFeePerGas: make([]string, 0, blockCount+1),
+		GasUsedRatio:  make([]float64, 0, blockCount),
+	}
+	if blockCount == 0 {
+		return resp, nil
+	}
+	if len(req.RewardPercentiles) > 0 {
+		resp.Reward = make([][]string, 0, blockCount)
+	}
+
+	gasLimit, err := hexutil.DecodeUint64(getBlockGasLimit(ctx, p.Qtum))
+	if err != nil {
+		return nil, eth.NewCallbackError("couldn't get block gas limit")
+	}
+	baseFee := blockBaseFeePerGas()
+	for height := oldestBlock; height < oldestBlock+blockCount; height++ {
+		fees, err := getBlockFees(ctx, p.Qtum, height, baseFee)
+		if err != nil {
+			p.GetDebugLogger().Log("msg", "couldn't get block fees", "height", height, "err", err)
+			return nil, eth.NewCallbackError("couldn't get block fees")
+		}
+
+		resp.BaseFeePerGas = append(resp.BaseFeePerGas, hexutil.EncodeBig(baseFee))
+		resp.GasUsedRatio = append(resp.GasUsedRatio, float64(fees.gasUsed)/float64(gasLimit))
+		if len(req.RewardPercentiles) > 0 {
+			rewards := make([]string, 0, len(req.RewardPercentiles))
+			for _, reward := range fees.rewards(req.RewardPercentiles) {
+				rewards = append(rewards, hexutil.EncodeBig(reward))
+			}
+			resp.Reward = append(resp.Reward, rewards)
+		}
+	}
+	// the base fee of the block after the newest one, which never changes
+	resp.BaseFeePerGas = append(resp.BaseFeePerGas, hexutil.EncodeBig(baseFee))
+
+	return resp, nil
+}
+
+// blockFees holds the priority fees paid by the contract transactions of a block, which are the only ones with a gas price
+type blockFees struct {
+	gasUsed uint64
+	// sorted by reward
+	txs []transactionFee
+}
+
+type transactionFee struct {
+	// the gas price of the transaction above the base fee, in wei
+	reward  *big.Int
+	gasUsed uint64
+}
+
+// minedBlockFees caches the fees of blocks by hash and base fee, they are only added when every receipt could be fetched
+var minedBlockFees = newBoundedCache(maxFeeHistoryBlocks)
+
+// getBlockFees reads the gas price of every OP_CALL and OP_CREATE output of the block at height, and weighs it by the gas
+// its transaction used. Without receipts, which need qtumd to run with -logevents, the gas limit of the output is used instead
+func getBlockFees(ctx context.Context, p *qtum.Qtum, height uint64, baseFee *big.Int) (*blockFees, error) {
+	blockHash, err := p.GetBlockHash(ctx, new(big.Int).SetUint64(height))
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't get block hash")
+	}
+	cacheKey := strings.ToLower(utils.RemoveHexPrefix(string(blockHash))) + "/" + baseFee.String()
+	if fees, ok := minedBlockFees.get(cacheKey); ok {
+		return fees.(*blockFees), nil
+	}
+	block, err := p.GetBlockVerbose(ctx, string(blockHash))
+	if err != nil {
+		return nil, errors.WithMessage(err, "couldn't get block")
+	}
+
+	fees := &blockFees{}
+	complete := true
+	for _, tx := range block.Txs {
+		info, isContractTx, err := tx.ExtractContractInfo()
+		if err != nil || !isContractTx {
+			continue
+		}
+		gasPrice, err := contractGasPriceInWei(info)
+		if err != nil {
+			p.GetDebugLogger().Log("msg", "couldn't parse gas price", "txid", tx.ID, "gasPrice", info.GasPrice, "err", err)
+			continue
+		}
+		reward := new(big.Int).Sub(gasPrice, baseFee)
+		if reward.Sign() < 0 {
+			reward.SetInt64(0)
+		}
+
+		var gasUsed uint64
+		receipts, err := p.GetTransactionReceipts(ctx, tx.ID)
+		if err == nil && len(receipts) > 0 {
+			for _, receipt := range receipts {
+				gasUsed += receipt.GasUsed
+			}
+		} else {
+			complete = false
+			if gasLimit, err := strconv.ParseUint(info.GasLimit, 16, 64); err == nil {
+				gasUsed = gasLimit
+			}
+		}
+
+		fees.gasUsed += gasUsed
+		fees.txs = append(fees.txs, transactionFee{reward: reward, gasUsed: gasUsed})
+	}
+	sort.SliceStable(fees.txs, func(i, j int) bool {
+		return fees.txs[i].reward.Cmp(fees.txs[j].reward) < 0
+	})
+
+	if complete {
+		minedBlockFees.add(cacheKey, fees)
+	}
+	return fees, nil
+}
+
+// rewards returns the priority fee paid at each percentile of the gas used by the block, the way geth computes them.
+// A block without contract transactions has rewards of zero
+func (f *blockFees) rewards(percentiles []float64) []*big.Int {
+	rewards := make([]*big.Int, len(percentiles))
+	if len(f.txs) == 0 {
+		for i := range rewards {
+			rewards[i] = big.NewInt(0)
+		}
+		return rewards
+	}
+
+	txIndex := 0
+	sumGasUsed := f.txs[0].gasUsed
+	for i, percentile := range percentiles {
+		thresholdGasUsed := uint64(float64(f.gasUsed) * percentile / 100)
+		for sumGasUsed < thresholdGasUsed && txIndex < len(f.txs)-1 {
+			txIndex++
+			sumGasUsed += f.txs[txIndex].gasUsed
+		}
+		rewards[i] = f.txs[txIndex].reward
+	}
+	return rewards
+}
@@ -0,0 +1,95 @@
+package transformer
+
+import (
+	"encoding/json"
+	"strings"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+// an OP_CALL output with a gas limit of 250000 and a gas price of 40 satoshi
+const callScriptHex = "540390d003012844095ea7b300000000000000000000000025495b3a87d82e9d7a71b341addfc0d7bb3475c7ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff1454fefdb5b31164f66ddb68becd7bdd864cacd65bc2"
+
+func callTransaction(txid string, gasPrice string) *qtum.BlockTransaction {
+	return &qtum.BlockTransaction{DecodedRawTransactionResponse: qtum.DecodedRawTransactionResponse{
+		ID: txid,
+		Vouts: []*qtum.DecodedRawTransactionOutV{
+			{N: 0, ScriptPubKey: qtum.DecodedRawTransactionScriptPubKey{Hex: strings.Replace(callScriptHex, "0128", "01"+gasPrice, 1)}},
+		},
+	}}
+}
+
+// setupFeeHistoryResponses mocks a block with a call paying the minimum gas price and using 10000 gas,
+// and one paying 100 satoshi and using 30000 gas, followed by a block without contract transactions
+func setupFeeHistoryResponses(t *testing.T, mockedClientDoer internal.Doer) {
+	minimumPriceID := "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451"
+	higherPriceID := "3ef34ec81e32cd6df4ad3d5b3b4f8b2c54d9bc6f1a7b8d0ec1f2a6b1c9d8e7f6"
+
+	block := internal.GetBlockVerboseResponse
+	block.Txs = append([]*qtum.BlockTransaction{}, internal.GetBlockVerboseResponse.Txs...)
+	block.Txs = append(block.Txs, callTransaction(minimumPriceID, "28"), callTransaction(higherPriceID, "64"))
+	responses := []struct {
+		method   string
+		response interface{}
+	}{
+		{qtum.MethodGetDGPInfo, qtum.GetDGPInfoResponse{BlockGasLimit: 40000000}},
+		{qtum.MethodGetBlockHash, qtum.GetBlockHashResponse(internal.GetTransactionByHashBlockHash)},
+		{qtum.MethodGetBlockHash, qtum.GetBlockHashResponse(internal.GetBlockResponse.Nextblockhash)},
+		{qtum.MethodGetBlock, block},
+		{qtum.MethodGetBlock, internal.GetBlockVerboseResponse},
+		{qtum.MethodGetTransactionReceipt, []qtum.TransactionReceipt{{TransactionHash: minimumPriceID, GasUsed: 10000}}},
+		{qtum.MethodGetTransactionReceipt, []qtum.TransactionReceipt{{TransactionHash: higherPriceID, GasUsed: 30000}}},
+	}
+	for _, r := range responses {
+		if err := mockedClientDoer.AddResponse(r.method, r.response); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestFeeHistoryRequest(t *testing.T) {
+	resetCaches()
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setupFeeHistoryResponses(t, mockedClientDoer)
+
+	request, err := internal.PrepareEthRPCRequest(1, []json.RawMessage{[]byte(`"0x2"`), []byte(`"0xf8f"`), []byte(`[20,90]`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyEth := ProxyETHFeeHistory{qtumClient}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	want := &eth.FeeHistoryResponse{
+		OldestBlock:   "0xf8e",
+		BaseFeePerGas: []string{"0x5d21dba000", "0x5d21dba000", "0x5d21dba000"},
+		GasUsedRatio:  []float64{0.001, 0},
+		// the call paying the minimum gas price used the first quarter of the gas of the first block
+		Reward: [][]string{{"0x0", "0x8bb2c97000"}, {"0x0", "0x0"}},
+	}
+	internal.CheckTestResultDefault(want, got, t, false)
+
+	// the fees of blocks whose receipts were all fetched are cached
+	delete(mockedClientDoer.Responses, qtum.MethodGetBlock)
+	delete(mockedClientDoer.Responses, qtum.MethodGetTransactionReceipt)
+	delete(mockedClientDoer.Responses, qtum.MethodGetBlockHash)
+	for _, hash := range []string{internal.GetTransactionByHashBlockHash, internal.GetBlockResponse.Nextblockhash} {
+		if err := mockedClientDoer.AddResponse(qtum.MethodGetBlockHash, qtum.GetBlockHashResponse(hash)); err != nil {
+			t.Fatal(err)
+		}
+	}
+	got, jsonErr = proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	internal.CheckTestResultDefault(want, got, t, false)
+}
@@ -6,12 +6,15 @@ import (
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/gasprice"
 	"github.com/qtumproject/janus/pkg/qtum"
 )
 
-// ProxyETHEstimateGas implements ETHProxy
+// ProxyETHGasPrice implements ETHProxy
 type ProxyETHGasPrice struct {
 	*qtum.Qtum
+	// suggests a gas price from recent blocks and the mempool, the minimum gas price is returned without it
+	oracle *gasprice.Oracle
 }
 
 func (p *ProxyETHGasPrice) Method() string {
@@ -19,7 +22,15 @@ func (p *ProxyETHGasPrice) Method() string {
 }
 
 func (p *ProxyETHGasPrice) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
-	qtumresp, err := p.Qtum.GetGasPrice(c.Request().Context())
+	var (
+		qtumresp *big.Int
+		err      error
+	)
+	if p.oracle != nil {
+		qtumresp, err = p.oracle.SuggestGasPrice(c.Request().Context())
+	} else {
+		qtumresp, err = p.Qtum.GetGasPrice(c.Request().Context())
+	}
 	if err != nil {
 		return nil, eth.NewCallbackError(err.Error())
 	}
@@ -2,9 +2,13 @@ package transformer
 
 import (
 	"encoding/json"
+	"strings"
 	"testing"
 
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/gasprice"
 	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
 )
 
 func TestGasPriceRequest(t *testing.T) {
@@ -22,7 +26,7 @@ func TestGasPriceRequest(t *testing.T) {
 	}
 
 	//preparing proxy & executing request
-	proxyEth := ProxyETHGasPrice{qtumClient}
+	proxyEth := ProxyETHGasPrice{Qtum: qtumClient}
 	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
 	if jsonErr != nil {
 		t.Fatal(jsonErr)
@@ -32,3 +36,94 @@ func TestGasPriceRequest(t *testing.T) {
 
 	internal.CheckTestResultDefault(want, got, t, false)
 }
+
+func TestGasPriceRequestWithOracle(t *testing.T) {
+	request, err := internal.PrepareEthRPCRequest(1, []json.RawMessage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setupGasPriceOracleResponses(t, mockedClientDoer)
+	oracle, err := gasprice.New(qtumClient, 1, gasprice.DefaultPercentile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHGasPrice{Qtum: qtumClient, oracle: oracle}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	want := string("0x1d1a94a2000")
+	internal.CheckTestResultDefault(want, got, t, false)
+}
+
+// setupGasPriceOracleResponses mocks a latest block without contract transactions, and a call paying 200 satoshi waiting in the mempool
+func setupGasPriceOracleResponses(t *testing.T, mockedClientDoer internal.Doer) {
+	mempoolTxID := "3ef34ec81e32cd6df4ad3d5b3b4f8b2c54d9bc6f1a7b8d0ec1f2a6b1c9d8e7f6"
+	responses := []struct {
+		method   string
+		response interface{}
+	}{
+		{qtum.MethodGetBlockChainInfo, qtum.GetBlockChainInfoResponse{Blocks: 3983, Bestblockhash: internal.GetTransactionByHashBlockHash}},
+		{qtum.MethodGetBlockHash, qtum.GetBlockHashResponse(internal.GetTransactionByHashBlockHash)},
+		{qtum.MethodGetBlock, internal.GetBlockVerboseResponse},
+		{qtum.MethodGetRawMempool, qtum.GetRawMempoolResponse{mempoolTxID}},
+		{qtum.MethodGetRawTransaction, qtum.GetRawTransactionResponse{
+			ID:    mempoolTxID,
+			Vouts: []qtum.RawTransactionVout{{Details: qtum.RawTransactionVoutDetails{Hex: strings.Replace(callScriptHex, "0128", "01c8", 1)}}},
+		}},
+	}
+	for _, r := range responses {
+		if err := mockedClientDoer.AddResponse(r.method, r.response); err != nil {
+			t.Fatal(err)
+		}
+	}
+}
+
+func TestMaxPriorityFeePerGasRequest(t *testing.T) {
+	request, err := internal.PrepareEthRPCRequest(1, []json.RawMessage{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	setupGasPriceOracleResponses(t, mockedClientDoer)
+	oracle, err := gasprice.New(qtumClient, 1, gasprice.DefaultPercentile)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// without the oracle the minimum gas price is enough, which is the base fee
+	proxyEth := ProxyETHMaxPriorityFeePerGas{Qtum: qtumClient}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	internal.CheckTestResultDefault(eth.MaxPriorityFeePerGasResponse("0x0"), got, t, false)
+
+	// the oracle suggests the 200 satoshi paid in the mempool, 160 satoshi above the base fee
+	proxyEth = ProxyETHMaxPriorityFeePerGas{Qtum: qtumClient, oracle: oracle}
+	got, jsonErr = proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	internal.CheckTestResultDefault(eth.MaxPriorityFeePerGasResponse("0x174876e8000"), got, t, false)
+
+	// eth_gasPrice suggests the base fee plus that priority fee, from the same sample
+	gasPrice, jsonErr := (&ProxyETHGasPrice{Qtum: qtumClient, oracle: oracle}).Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	internal.CheckTestResultDefault("0x1d1a94a2000", gasPrice, t, false)
+}
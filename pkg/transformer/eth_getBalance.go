@@ -1,6 +1,7 @@
 package transformer
 
 import (
+	"context"
 	"math/big"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -26,14 +27,26 @@ func (p *ProxyETHGetBalance) Request(rawreq *eth.JSONRPCRequest, c echo.Context)
 		return nil, eth.NewInvalidParamsError(err.Error())
 	}
 
+	return p.request(c.Request().Context(), &req)
+}
+
+func (p *ProxyETHGetBalance) request(ctx context.Context, req *eth.GetBalanceRequest) (interface{}, eth.JSONRPCError) {
+	blockNumber, jsonErr := getStateBlockNumber(ctx, p.Qtum, req.Block)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
 	addr := utils.RemoveHexPrefix(req.Address)
 	{
 		// is address a contract or an account?
 		qtumreq := qtum.GetAccountInfoRequest(addr)
-		qtumresp, err := p.GetAccountInfo(c.Request().Context(), &qtumreq)
+		qtumresp, err := p.GetAccountInfo(ctx, &qtumreq)
 
 		// the address is a contract
 		if err == nil {
+			if blockNumber != nil {
+				return nil, eth.NewCallbackError("historical balances of contracts are not supported, qtumd only keeps their latest balance")
+			}
 			// the unit of the balance Satoshi
 			p.GetDebugLogger().Log("method", p.Method(), "address", req.Address, "msg", "is a contract")
 			return hexutil.EncodeUint64(uint64(qtumresp.Balance)), nil
@@ -48,10 +61,14 @@ func (p *ProxyETHGetBalance) Request(rawreq *eth.JSONRPCRequest, c echo.Context)
 			return nil, eth.NewCallbackError(err.Error())
 		}
 
+		if blockNumber != nil {
+			return p.getHistoricalBalance(ctx, base58Addr, blockNumber)
+		}
+
 		qtumreq := qtum.GetAddressBalanceRequest{
 			Addresses: []string{base58Addr},
 		}
-		qtumresp, err := p.GetAddressBalance(c.Request().Context(), &qtumreq)
+		qtumresp, err := p.GetAddressBalance(ctx, &qtumreq)
 		if err != nil {
 			if err == qtum.ErrInvalidAddress {
 				// invalid address should return 0x0
@@ -70,3 +87,37 @@ func (p *ProxyETHGetBalance) Request(rawreq *eth.JSONRPCRequest, c echo.Context)
 		return hexutil.EncodeBig(balance), nil
 	}
 }
+
+// getHistoricalBalance adds up the changes to the balance of an address up to and including a block
+func (p *ProxyETHGetBalance) getHistoricalBalance(ctx context.Context, base58Addr string, blockNumber *big.Int) (interface{}, eth.JSONRPCError) {
+	if blockNumber.Sign() == 0 {
+		// the genesis block's output can't be spent
+		return "0x0", nil
+	}
+
+	deltas, err := p.GetAddressDeltas(ctx, &qtum.GetAddressDeltasRequest{
+		Addresses: []string{base58Addr},
+		Start:     1,
+		End:       blockNumber.Int64(),
+	})
+	if err != nil {
+		if err == qtum.ErrInvalidAddress {
+			return "0x0", nil
+		}
+		p.GetDebugLogger().Log("method", p.Method(), "address", base58Addr, "msg", "error getting address deltas", "error", err)
+		return nil, eth.NewCallbackError(err.Error())
+	}
+
+	balance := big.NewInt(0)
+	for _, delta := range deltas {
+		balance.Add(balance, big.NewInt(delta.Satoshis))
+	}
+	if balance.Sign() < 0 {
+		return nil, eth.NewCallbackError("address deltas add up to a negative balance")
+	}
+
+	//Balance for ETH response is represented in Weis (1 QTUM Satoshi = 10 ^ 10 Wei)
+	balance = balance.Mul(balance, big.NewInt(10000000000))
+
+	return hexutil.EncodeBig(balance), nil
+}
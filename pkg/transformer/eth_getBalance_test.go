@@ -2,6 +2,7 @@ package transformer
 
 import (
 	"encoding/json"
+	"math/big"
 	"testing"
 
 	"github.com/btcsuite/btcutil"
@@ -11,7 +12,7 @@ import (
 
 func TestGetBalanceRequestAccount(t *testing.T) {
 	//prepare request
-	requestParams := []json.RawMessage{[]byte(`"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"`), []byte(`"123"`)}
+	requestParams := []json.RawMessage{[]byte(`"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"`), []byte(`"latest"`)}
 	requestRPC, err := internal.PrepareEthRPCRequest(1, requestParams)
 	if err != nil {
 		t.Fatal(err)
@@ -61,7 +62,7 @@ func TestGetBalanceRequestAccount(t *testing.T) {
 
 func TestGetBalanceRequestContract(t *testing.T) {
 	//prepare request
-	requestParams := []json.RawMessage{[]byte(`"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"`), []byte(`"123"`)}
+	requestParams := []json.RawMessage{[]byte(`"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"`), []byte(`"latest"`)}
 	requestRPC, err := internal.PrepareEthRPCRequest(1, requestParams)
 	if err != nil {
 		t.Fatal(err)
@@ -103,3 +104,57 @@ func TestGetBalanceRequestContract(t *testing.T) {
 
 	internal.CheckTestResultEthRequestRPC(*requestRPC, want, got, t, false)
 }
+
+func TestGetBalanceRequestAtBlockNumber(t *testing.T) {
+	//prepare request
+	requestParams := []json.RawMessage{[]byte(`"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"`), []byte(`{"blockNumber": "0x64"}`)}
+	requestRPC, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//prepare client
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//prepare responses
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(200)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodFromHexAddress, qtum.FromHexAddressResponse("qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the current balance would be returned if the block number was ignored
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressBalance, qtum.GetAddressBalanceResponse{Balance: uint64(100000000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressDeltas, qtum.GetAddressDeltasResponse{
+		{Satoshis: 200000000, Height: 10},
+		{Satoshis: -50000000, Height: 90},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//preparing proxy & executing request
+	proxyEth := ProxyETHGetBalance{qtumClient}
+	got, jsonErr := proxyEth.Request(requestRPC, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	want := string("0x14d1120d7b160000") //1.5 Qtum represented in Wei
+
+	internal.CheckTestResultEthRequestRPC(*requestRPC, want, got, t, false)
+
+	// blocks that aren't mined yet have no state
+	requestRPC.Params = []byte(`["0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960", "0xc9"]`)
+	if _, jsonErr := proxyEth.Request(requestRPC, internal.NewEchoContext()); jsonErr == nil {
+		t.Fatal("Expected the balance at a future block to be rejected")
+	}
+}
@@ -3,11 +3,13 @@ package transformer
 import (
 	"context"
 	"fmt"
+	"strings"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/blockhash"
+	"github.com/qtumproject/janus/pkg/conversion"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/qtumproject/janus/pkg/utils"
@@ -125,11 +127,40 @@ func (p *ProxyETHGetBlockByHash) request(ctx context.Context, req *eth.GetBlockB
 		p.GetDebugLogger().Log("msg", "couldn't get block header", "blockHash", req.BlockHash)
 		return nil, eth.NewCallbackError("couldn't get block header")
 	}
-	block, err := p.GetBlock(ctx, req.BlockHash)
+	// getblock with verbosity 3 returns every decoded transaction along with the block, which tells the contract
+	// transactions having receipts apart without a call per transaction
+	verboseBlock, err := p.GetBlockVerbose(ctx, req.BlockHash)
 	if err != nil {
 		p.GetDebugLogger().Log("msg", "couldn't get block", "blockHash", req.BlockHash)
 		return nil, eth.NewCallbackError("couldn't get block")
 	}
+	block := &verboseBlock.GetBlockResponse
+	block.Txs = verboseBlock.TxIDs()
+	resp := blockHeaderResponse(block, getBlockDetails(ctx, p.Qtum, blockHeader, verboseBlock))
+	resp.Transactions = make([]interface{}, 0, len(block.Txs))
+
+	if req.FullTransaction {
+		transactions, jsonErr := getBlockTransactions(ctx, p.Qtum, verboseBlock, true)
+		if jsonErr != nil {
+			return nil, jsonErr
+		}
+		resp.Transactions = transactions
+	} else {
+		for _, txHash := range block.Txs {
+			// NOTE:
+			// 	Etherium RPC API doc says, that tx hashes must be of [32]byte,
+			// 	however it doesn't seem to be correct, 'cause Etherium tx hash
+			// 	has [64]byte just like Qtum tx hash has. In this case we do no
+			// 	additional convertations now, while everything works fine
+			resp.Transactions = append(resp.Transactions, utils.AddHexPrefix(txHash))
+		}
+	}
+
+	return resp, nil
+}
+
+// blockHeaderResponse returns the header fields of a block fetched with getblock, along with the details derived from its transactions
+func blockHeaderResponse(block *qtum.GetBlockResponse, details blockDetails) *eth.GetBlockByHashResponse {
 	nonce := hexutil.EncodeUint64(uint64(block.Nonce))
 	// left pad nonce with 0 to length 16, eg: 0x0000000000000042
 	nonce = utils.AddHexPrefix(fmt.Sprintf("%016v", utils.RemoveHexPrefix(nonce)))
@@ -137,7 +168,7 @@ func (p *ProxyETHGetBlockByHash) request(ctx context.Context, req *eth.GetBlockB
 		// TODO: researching
 		// * If ETH block has pending status, then the following values must be null
 		// ? Is it possible case for Qtum
-		Hash:   utils.AddHexPrefix(req.BlockHash),
+		Hash:   utils.AddHexPrefix(block.Hash),
 		Number: hexutil.EncodeUint64(uint64(block.Height)),
 
 		// TODO: researching
@@ -148,7 +179,7 @@ func (p *ProxyETHGetBlockByHash) request(ctx context.Context, req *eth.GetBlockB
 		// TODO: researching
 		// ! Not found
 		// ! Probably, may be calculated by huge amount of requests
-		TotalDifficulty: hexutil.EncodeUint64(uint64(blockHeader.Difficulty)),
+		TotalDifficulty: hexutil.EncodeUint64(uint64(block.Difficulty)),
 
 		// TODO: researching
 		// ! Not found
@@ -158,10 +189,7 @@ func (p *ProxyETHGetBlockByHash) request(ctx context.Context, req *eth.GetBlockB
 		// TODO: check value correctness
 		Sha3Uncles: eth.DefaultSha3Uncles,
 
-		// TODO: backlog
-		// ! Not found
-		// - Temporary expect this value to be always zero, as Etherium logs are usually zeros
-		LogsBloom: eth.EmptyLogsBloom,
+		LogsBloom: details.logsBloom,
 
 		// TODO: researching
 		// ? What value to put
@@ -171,74 +199,415 @@ func (p *ProxyETHGetBlockByHash) request(ctx context.Context, req *eth.GetBlockB
 
 		Nonce:            nonce,
 		Size:             hexutil.EncodeUint64(uint64(block.Size)),
-		Difficulty:       hexutil.EncodeUint64(uint64(blockHeader.Difficulty)),
-		StateRoot:        utils.AddHexPrefix(blockHeader.HashStateRoot),
+		Difficulty:       hexutil.EncodeUint64(uint64(block.Difficulty)),
+		StateRoot:        utils.AddHexPrefix(block.HashStateRoot),
 		TransactionsRoot: utils.AddHexPrefix(block.Merkleroot),
-		Transactions:     make([]interface{}, 0, len(block.Txs)),
-		Timestamp:        hexutil.EncodeUint64(blockHeader.Time),
+		Timestamp:        hexutil.EncodeUint64(uint64(block.Time)),
 	}
 
-	if blockHeader.IsGenesisBlock() {
+	if block.IsGenesisBlock() {
 		resp.ParentHash = "0x0000000000000000000000000000000000000000000000000000000000000000"
-		resp.Miner = utils.AddHexPrefix(qtum.ZeroAddress)
 	} else {
-		resp.ParentHash = utils.AddHexPrefix(blockHeader.Previousblockhash)
-		// ! Not found
-		//
-		// NOTE:
-		// 	In order to find a miner it seems, that we have to check
-		// 	address field of the txout method response. Current
-		// 	suggestion is to fill this field with zeros, not to
-		// 	spend much time on requests execution
-		//
-		// TODO: check if it's value is acquirable via logs
-		resp.Miner = "0x0000000000000000000000000000000000000000"
-	}
-
-	// TODO: rethink later
-	// ! Found only for contracts transactions
-	// As there is no gas values presented at common block info, we set
-	// gas limit value equalling to default gas limit of a block
-	resp.GasLimit = utils.AddHexPrefix(qtum.DefaultBlockGasLimit)
-	resp.GasUsed = "0x0"
-
-	// TODO: Future improvement: If getBlock is called with verbosity 2 it also returns full tx info as if getRawTransaction was called for each,
-	// so using that from the start instead of requesting each tx individually as done here would save a lot of back-and-forth
+		resp.ParentHash = utils.AddHexPrefix(block.Previousblockhash)
+	}
 
-	if req.FullTransaction {
-		for _, txHash := range block.Txs {
-			tx, err := getTransactionByHash(ctx, p.Qtum, txHash)
+	resp.Miner = details.miner
+	resp.GasLimit = details.gasLimit
+	resp.GasUsed = details.gasUsed
+	resp.BaseFeePerGas = hexutil.EncodeBig(blockBaseFeePerGas())
+	return resp
+}
+
+// getBlockTransactions translates every transaction of a block fetched with getblock verbosity 3.
+// The block already holds each decoded transaction, its position and the outputs it spends, so when withNonces is set
+// the nonces of every sender are looked up with a single getaddressdeltas call. On qtumd versions without verbosity 3,
+// the reward transactions and the senders spending outputs created in other blocks need a call to qtumd each
+func getBlockTransactions(ctx context.Context, p *qtum.Qtum, block *qtum.GetBlockVerboseResponse, withNonces bool) ([]interface{}, eth.JSONRPCError) {
+	var (
+		transactions = make([]*eth.GetTransactionByHashResponse, 0, len(block.Txs))
+		proofOfStake = strings.Contains(block.Flags, "proof-of-stake")
+		blockTxs     = make(map[string]*qtum.BlockTransaction, len(block.Txs))
+		// the transactions the nonce is looked up for, getTransactionByHash already sets it
+		needNonce = make(map[*eth.GetTransactionByHashResponse]bool)
+	)
+	for _, tx := range block.Txs {
+		blockTxs[tx.ID] = tx
+	}
+
+	for i, tx := range block.Txs {
+		ethTx := &eth.GetTransactionByHashResponse{
+			Hash:             utils.AddHexPrefix(tx.ID),
+			Nonce:            "0x0",
+			BlockHash:        utils.AddHexPrefix(block.Hash),
+			BlockNumber:      hexutil.EncodeUint64(uint64(block.Height)),
+			TransactionIndex: hexutil.EncodeUint64(uint64(i)),
+
+			// Added for go-ethereum client and graph-node support
+			R: "0xf000000000000000000000000000000000000000000000000000000000000000",
+			S: "0xf000000000000000000000000000000000000000000000000000000000000000",
+			V: "0x25",
+
+			Gas:      "0x0",
+			GasPrice: "0x0",
+		}
+
+		if i == 0 || (proofOfStake && i == 1) {
+			if block.Height == 0 {
+				// the genesis block coinbase is not considered an ordinary transaction and cannot be retrieved,
+				// mainnet ethereum also doesn't return any data about the genesis coinbase
+				continue
+			}
+			if rawTx, ok := rawRewardTransaction(tx); ok {
+				// translated the same way getTransactionByHash does, from the spent outputs getblock included
+				if len(rawTx.Vouts) > 0 {
+					ethTx.Value = "0x0"
+				}
+				setRewardTransactionContent(p, ethTx, rawTx)
+				getSender := func() (string, error) {
+					return getBlockTransactionSender(ctx, p, blockTxs, tx)
+				}
+				if err := setTransactionContent(p, ethTx, &tx.DecodedRawTransactionResponse, tx.Hex, false, getSender); err != nil {
+					return nil, err
+				}
+				transactions = append(transactions, ethTx)
+				needNonce[ethTx] = true
+				continue
+			}
+
+			// the value of the coinstake transaction depends on the amounts of the outputs it spends, which verbosity 2 doesn't include
+			rewardTx, err := getTransactionByHash(ctx, p, tx.ID)
 			if err != nil {
-				p.GetDebugLogger().Log("msg", "Couldn't get transaction by hash", "hash", txHash, "err", err)
+				p.GetDebugLogger().Log("msg", "Couldn't get transaction by hash", "hash", tx.ID, "err", err)
 				return nil, eth.NewCallbackError("couldn't get transaction by hash")
 			}
-			if tx == nil {
-				if block.Height == 0 {
-					// Error Invalid address - The genesis block coinbase is not considered an ordinary transaction and cannot be retrieved
-					// the coinbase we can ignore since its not a real transaction, mainnet ethereum also doesn't return any data about the genesis coinbase
-					p.GetDebugLogger().Log("msg", "Failed to get transaction in genesis block, probably the coinbase which we can't get")
-				} else {
-					p.GetDebugLogger().Log("msg", "Failed to get transaction by hash included in a block", "hash", txHash)
-					if !p.GetFlagBool(qtum.FLAG_IGNORE_UNKNOWN_TX) {
-						return nil, eth.NewCallbackError("couldn't get transaction by hash included in a block")
-					}
+			if rewardTx == nil {
+				p.GetDebugLogger().Log("msg", "Failed to get transaction by hash included in a block", "hash", tx.ID)
+				if !p.GetFlagBool(qtum.FLAG_IGNORE_UNKNOWN_TX) {
+					return nil, eth.NewCallbackError("couldn't get transaction by hash included in a block")
 				}
-			} else {
-				resp.Transactions = append(resp.Transactions, *tx)
+				continue
+			}
+			transactions = append(transactions, rewardTx)
+			continue
+		}
+
+		getSender := func() (string, error) {
+			return getBlockTransactionSender(ctx, p, blockTxs, tx)
+		}
+		if err := setTransactionContent(p, ethTx, &tx.DecodedRawTransactionResponse, tx.Hex, false, getSender); err != nil {
+			return nil, err
+		}
+		transactions = append(transactions, ethTx)
+		needNonce[ethTx] = true
+	}
+
+	if withNonces {
+		setBlockTransactionNonces(ctx, p, block, transactions, needNonce)
+	}
+
+	result := make([]interface{}, 0, len(transactions))
+	for _, ethTx := range transactions {
+		result = append(result, *ethTx)
+	}
+	return result, nil
+}
+
+// setBlockTransactionNonces sets the nonces of the given transactions of a block, looking up the transactions of every sender at once
+func setBlockTransactionNonces(ctx context.Context, p *qtum.Qtum, block *qtum.GetBlockVerboseResponse, transactions []*eth.GetTransactionByHashResponse, needNonce map[*eth.GetTransactionByHashResponse]bool) {
+	senders := []string{}
+	seen := map[string]bool{}
+	for _, ethTx := range transactions {
+		if !needNonce[ethTx] || ethTx.From == "" || ethTx.From == utils.AddHexPrefix(qtum.ZeroAddress) || seen[ethTx.From] {
+			continue
+		}
+		seen[ethTx.From] = true
+		senders = append(senders, ethTx.From)
+	}
+	if len(senders) == 0 {
+		return
+	}
+
+	sent, err := getSentTransactionsOfAddresses(ctx, p, senders, hexutil.EncodeUint64(uint64(block.Height)), block.Hash)
+	if err != nil {
+		// getaddressdeltas needs qtumd to run with -addrindex, the nonces are left at 0 without it
+		p.GetDebugLogger().Log("msg", "Couldn't get transactions sent by the block's senders", "blockHash", block.Hash, "err", err)
+		return
+	}
+	for _, ethTx := range transactions {
+		if !needNonce[ethTx] {
+			continue
+		}
+		txid := utils.RemoveHexPrefix(ethTx.Hash)
+		for nonce, sentTxid := range sent[ethTx.From] {
+			if sentTxid == txid {
+				ethTx.Nonce = formatQtumNonce(nonce)
+				break
+			}
+		}
+	}
+}
+
+// rawRewardTransaction returns a coinbase or coinstake transaction of a block fetched with getblock verbosity 3 the way getrawtransaction
+// describes it, with the address and amount of the output each input spends. It's false when qtumd didn't include the spent outputs
+func rawRewardTransaction(tx *qtum.BlockTransaction) (*qtum.GetRawTransactionResponse, bool) {
+	rawTx := &qtum.GetRawTransactionResponse{
+		Hex:  tx.Hex,
+		ID:   tx.ID,
+		Hash: tx.Hash,
+	}
+	for _, vin := range tx.Vins {
+		rawVin := qtum.RawTransactionVin{ID: vin.TxID, VoutN: vin.Vout}
+		if !vin.IsCoinbase() {
+			if vin.Prevout == nil {
+				return nil, false
 			}
-			// TODO: fill gas used
-			// TODO: fill gas limit?
+			if addresses := vin.Prevout.ScriptPubKey.GetAddresses(); len(addresses) > 0 {
+				rawVin.Address = addresses[0]
+			}
+			rawVin.Amount, _ = vin.Prevout.Value.Float64()
+			rawVin.AmountSatoshi = vin.Prevout.Value.Shift(8).IntPart()
 		}
+		rawTx.Vins = append(rawTx.Vins, rawVin)
+	}
+	for _, vout := range tx.Vouts {
+		amount, _ := vout.Value.Float64()
+		rawTx.Vouts = append(rawTx.Vouts, qtum.RawTransactionVout{
+			Amount:        amount,
+			AmountSatoshi: vout.Value.Shift(8).IntPart(),
+			Details: qtum.RawTransactionVoutDetails{
+				Addresses: vout.ScriptPubKey.Addresses,
+				Asm:       vout.ScriptPubKey.ASM,
+				Hex:       vout.ScriptPubKey.Hex,
+				Type:      vout.ScriptPubKey.Type,
+			},
+		})
+	}
+	return rawTx, true
+}
+
+// getBlockTransactionSender returns the address owning the first input of a transaction in a block, reading the spent output
+// from the input when getblock included it or from the block when it was created there, instead of asking qtumd for it
+func getBlockTransactionSender(ctx context.Context, p *qtum.Qtum, blockTxs map[string]*qtum.BlockTransaction, tx *qtum.BlockTransaction) (string, error) {
+	if len(tx.Vins) > 0 {
+		vin := tx.Vins[0]
+		if vin.IsCoinbase() {
+			return "", errors.New("coinbase transactions have no sender")
+		}
+		var addresses []string
+		if vin.Prevout != nil {
+			addresses = vin.Prevout.ScriptPubKey.GetAddresses()
+		} else if prevTx, ok := blockTxs[vin.TxID]; ok && vin.Vout >= 0 && vin.Vout < int64(len(prevTx.Vouts)) {
+			addresses = prevTx.Vouts[vin.Vout].ScriptPubKey.Addresses
+		}
+		for _, address := range addresses {
+			if address == "" {
+				continue
+			}
+			hexAddress, err := utils.ConvertQtumAddress(address)
+			if err != nil {
+				return "", err
+			}
+			return utils.AddHexPrefix(hexAddress), nil
+		}
+	}
+	return getNonContractTxSenderAddress(ctx, p, &tx.DecodedRawTransactionResponse)
+}
+
+// contractTransactionIDs returns the ids of the transactions with OP_CREATE or OP_CALL outputs, the only ones with EVM receipts
+func contractTransactionIDs(txs []*qtum.BlockTransaction) []string {
+	txids := []string{}
+	for _, tx := range txs {
+		if _, isContractTx, _ := tx.ExtractContractInfo(); isContractTx {
+			txids = append(txids, tx.ID)
+		}
+	}
+	return txids
+}
+
+// minedBlockReceipts caches the EVM receipts of the contract transactions of blocks by block hash, then by transaction id.
+// A block's receipts are only added once every one of them was fetched
+var minedBlockReceipts = newBoundedCache(1024)
+
+// getBlockTransactionReceipts returns the EVM receipts of the contract transactions of a block by transaction id, transactions without contract outputs have none.
+// The transactions whose receipts can't be fetched are left out, and the first error is returned along with the other receipts
+func getBlockTransactionReceipts(ctx context.Context, p *qtum.Qtum, block *qtum.GetBlockVerboseResponse) (map[string][]qtum.TransactionReceipt, error) {
+	hash := strings.ToLower(utils.RemoveHexPrefix(block.Hash))
+	if receipts, ok := minedBlockReceipts.get(hash); ok {
+		return receipts.(map[string][]qtum.TransactionReceipt), nil
+	}
+
+	var firstErr error
+	receipts := map[string][]qtum.TransactionReceipt{}
+	for _, txHash := range contractTransactionIDs(block.Txs) {
+		txReceipts, err := p.GetTransactionReceipts(ctx, txHash)
+		if err != nil {
+			if firstErr == nil {
+				firstErr = errors.WithMessagef(err, "couldn't get receipts of transaction %s", txHash)
+			}
+			continue
+		}
+		receipts[txHash] = txReceipts
+	}
+	if firstErr != nil {
+		return receipts, firstErr
+	}
+	minedBlockReceipts.add(hash, receipts)
+	return receipts, nil
+}
+
+// getBlockReceipts returns the EVM receipts of the contract transactions of a block in the order of the transactions, failing when any can't be fetched
+func getBlockReceipts(ctx context.Context, p *qtum.Qtum, block *qtum.GetBlockVerboseResponse) ([]qtum.TransactionReceipt, error) {
+	receiptsByTx, err := getBlockTransactionReceipts(ctx, p, block)
+	if err != nil {
+		return nil, err
+	}
+	receipts := []qtum.TransactionReceipt{}
+	for _, txHash := range contractTransactionIDs(block.Txs) {
+		receipts = append(receipts, receiptsByTx[txHash]...)
+	}
+	return receipts, nil
+}
+
+// blockLogsBloom returns the bloom filter of the logs of every receipt in a block
+func blockLogsBloom(receipts []qtum.TransactionReceipt) string {
+	logs := []eth.Log{}
+	for i := range receipts {
+		logs = append(logs, conversion.ExtractETHLogsFromTransactionReceipt(&receipts[i], receipts[i].Log)...)
+	}
+	return eth.LogsBloom(logs)
+}
+
+// blockGasUsed returns the gas used by the EVM receipts of a block
+func blockGasUsed(receipts []qtum.TransactionReceipt) uint64 {
+	var gasUsed uint64
+	for _, receipt := range receipts {
+		gasUsed += receipt.GasUsed
+	}
+	return gasUsed
+}
+
+// blockDetails are the fields of a block derived from its transactions and the chain's governance contracts rather than from its header
+type blockDetails struct {
+	miner     string
+	gasLimit  string
+	gasUsed   string
+	logsBloom string
+}
+
+// minedBlockDetails caches the details of blocks by hash, they are only added once every lookup succeeded.
+// The gas limit is the one when the block was first served, getdgpinfo only returns the current one
+var minedBlockDetails = newBoundedCache(1024)
+
+// getBlockDetails returns the details of a block fetched with getblock verbosity 3, falling back on defaults for the ones qtumd can't provide
+func getBlockDetails(ctx context.Context, p *qtum.Qtum, header *qtum.GetBlockHeaderResponse, block *qtum.GetBlockVerboseResponse) blockDetails {
+	hash := strings.ToLower(utils.RemoveHexPrefix(header.Hash))
+	if details, ok := minedBlockDetails.get(hash); ok {
+		return details.(blockDetails)
+	}
+	complete := true
+
+	details := blockDetails{
+		miner:     utils.AddHexPrefix(qtum.ZeroAddress),
+		gasLimit:  utils.AddHexPrefix(qtum.DefaultBlockGasLimit),
+		gasUsed:   "0x0",
+		logsBloom: eth.FullLogsBloom,
+	}
+	receipts, err := getBlockReceipts(ctx, p, block)
+	if err != nil {
+		// gettransactionreceipt needs qtumd to run with -logevents, without it the block's logs are unknown and
+		// the bloom matches everything, so clients filtering blocks by their bloom don't miss any log
+		p.GetDebugLogger().Log("msg", "couldn't get receipts of block", "blockHash", header.Hash, "err", err)
+		complete = false
 	} else {
-		for _, txHash := range block.Txs {
-			// NOTE:
-			// 	Etherium RPC API doc says, that tx hashes must be of [32]byte,
-			// 	however it doesn't seem to be correct, 'cause Etherium tx hash
-			// 	has [64]byte just like Qtum tx hash has. In this case we do no
-			// 	additional convertations now, while everything works fine
-			resp.Transactions = append(resp.Transactions, utils.AddHexPrefix(txHash))
+		details.logsBloom = blockLogsBloom(receipts)
+		details.gasUsed = hexutil.EncodeUint64(blockGasUsed(receipts))
+	}
+
+	if !header.IsGenesisBlock() {
+		miner, err := getBlockMiner(ctx, p, block)
+		if err != nil {
+			p.GetDebugLogger().Log("msg", "couldn't get block miner", "blockHash", header.Hash, "err", err)
+			complete = false
+		} else {
+			details.miner = miner
 		}
 	}
 
-	return resp, nil
+	gasLimit, err := getDGPBlockGasLimit(ctx, p)
+	if err != nil {
+		p.GetDebugLogger().Log("msg", "couldn't get block gas limit, using the default one", "err", err)
+		complete = false
+	} else {
+		details.gasLimit = gasLimit
+	}
+
+	if complete {
+		minedBlockDetails.add(hash, details)
+	}
+	return details
+}
+
+// getBlockGasLimit returns the block gas limit set by the chain's governance contracts, or the default one when getdgpinfo fails
+func getBlockGasLimit(ctx context.Context, p *qtum.Qtum) string {
+	gasLimit, err := getDGPBlockGasLimit(ctx, p)
+	if err != nil {
+		p.GetDebugLogger().Log("msg", "couldn't get block gas limit, using the default one", "err", err)
+		return utils.AddHexPrefix(qtum.DefaultBlockGasLimit)
+	}
+	return gasLimit
+}
+
+// getDGPBlockGasLimit returns the current block gas limit set by the chain's governance contracts
+func getDGPBlockGasLimit(ctx context.Context, p *qtum.Qtum) (string, error) {
+	dgpInfo, err := p.GetDGPInfo(ctx)
+	if err != nil {
+		return "", err
+	}
+	if dgpInfo.BlockGasLimit == 0 {
+		return "", errors.New("getdgpinfo returned no block gas limit")
+	}
+	return hexutil.EncodeUint64(dgpInfo.BlockGasLimit), nil
+}
+
+// getBlockMiner returns the address of the staker of a proof of stake block, which spends its coins in the block's coinstake transaction,
+// or the address the coinbase transaction of a proof of work block pays to. It's the zero address when the block pays no address.
+// Only the staker of a block fetched without the outputs its transactions spend is looked up with getrawtransaction
+func getBlockMiner(ctx context.Context, p *qtum.Qtum, block *qtum.GetBlockVerboseResponse) (string, error) {
+	miner := utils.AddHexPrefix(qtum.ZeroAddress)
+	if len(block.Txs) == 0 {
+		return miner, nil
+	}
+
+	var addresses []string
+	if strings.Contains(block.Flags, "proof-of-stake") && len(block.Txs) > 1 {
+		// the coinbase of a proof of stake block is empty, the coinstake transaction comes next
+		coinstake := block.Txs[1]
+		if len(coinstake.Vins) > 0 && coinstake.Vins[0].Prevout != nil {
+			addresses = coinstake.Vins[0].Prevout.ScriptPubKey.GetAddresses()
+		} else {
+			rawTx, err := p.GetRawTransaction(ctx, coinstake.ID, false)
+			if err != nil {
+				return "", errors.WithMessagef(err, "couldn't get transaction %s paying the miner", coinstake.ID)
+			}
+			if len(rawTx.Vins) > 0 {
+				addresses = []string{rawTx.Vins[0].Address}
+			}
+		}
+	} else {
+		for _, vout := range block.Txs[0].Vouts {
+			if len(vout.ScriptPubKey.Addresses) > 0 {
+				addresses = vout.ScriptPubKey.Addresses
+				break
+			}
+		}
+	}
+	if len(addresses) == 0 || addresses[0] == "" {
+		return miner, nil
+	}
+
+	hexAddress, err := utils.ConvertQtumAddress(addresses[0])
+	if err != nil {
+		return "", errors.WithMessagef(err, "couldn't convert miner address %s", addresses[0])
+	}
+	return utils.AddHexPrefix(hexAddress), nil
 }
@@ -4,6 +4,9 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/shopspring/decimal"
+
+	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/internal"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/qtumproject/janus/pkg/utils"
@@ -20,19 +23,268 @@ func TestGetBlockByHashRequestNonceLength(t *testing.T) {
 }
 
 func TestGetBlockByHashRequest(t *testing.T) {
-	testETHProxyRequest(
+	testETHProxyRequestWithSetup(
 		t,
 		initializeProxyETHGetBlockByHash,
 		[]json.RawMessage{[]byte(`"` + internal.GetTransactionByHashBlockHexHash + `"`), []byte(`false`)},
 		&internal.GetTransactionByHashResponse,
+		internal.SetupGetBlockByHashResponsesWithTransactions,
 	)
 }
 
 func TestGetBlockByHashTransactionsRequest(t *testing.T) {
-	testETHProxyRequest(
+	testETHProxyRequestWithSetup(
 		t,
 		initializeProxyETHGetBlockByHash,
 		[]json.RawMessage{[]byte(`"` + internal.GetTransactionByHashBlockHexHash + `"`), []byte(`true`)},
 		&internal.GetTransactionByHashResponseWithTransactions,
+		internal.SetupGetBlockByHashResponsesWithTransactions,
 	)
 }
+
+func TestGetBlockByHashReceiptFields(t *testing.T) {
+	resetCaches()
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockHeader, qtum.GetBlockHeaderResponse{Hash: internal.GetTransactionByHashBlockHash, Height: 3983})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the block's call transaction has two receipts, the coinbase and coinstake transactions have none and aren't looked up
+	callID := "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451"
+	block := internal.GetBlockVerboseResponse
+	block.Txs = append([]*qtum.BlockTransaction{}, internal.GetBlockVerboseResponse.Txs...)
+	block.Txs = append(block.Txs, callTransaction(callID, "28"))
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlock, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	receipt := internal.QtumTransactionReceipt([]qtum.Log{{
+		Address: "db46f738bf32cdafb9a4a70eb8b44c76646bcaf0",
+		Topics:  []string{"ddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"},
+	}})
+	receipt.GasUsed = 21000
+	otherReceipt := internal.QtumTransactionReceipt([]qtum.Log{})
+	otherReceipt.GasUsed = 30000
+	err = mockedClientDoer.AddResponse(qtum.MethodGetTransactionReceipt, []qtum.TransactionReceipt{receipt, otherReceipt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetDGPInfo, qtum.GetDGPInfoResponse{BlockGasLimit: 40000000})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetRawTransaction, qtum.GetRawTransactionResponse{
+		Vins: []qtum.RawTransactionVin{{Address: "QXeZZ5MsAF5pPrPy47ZFMmtCpg7RExT4mi"}},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHGetBlockByHash{qtumClient}
+	got, jsonErr := proxyEth.request(internal.NewEchoContext().Request().Context(), &eth.GetBlockByHashRequest{
+		BlockHash: internal.GetTransactionByHashBlockHash,
+	})
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	wantBloom := eth.LogsBloom([]eth.Log{{
+		Address: "0xdb46f738bf32cdafb9a4a70eb8b44c76646bcaf0",
+		Topics:  []string{"0xddf252ad1be2c89b69c2b068fc378daa952ba7f163c4a11628f55a4df523b3ef"},
+	}})
+	if got.LogsBloom == eth.EmptyLogsBloom || got.LogsBloom != wantBloom {
+		t.Errorf("error\nwant: %s\ngot: %s", wantBloom, got.LogsBloom)
+	}
+	if got.GasUsed != "0xc738" {
+		t.Errorf("Expected the gas used by both receipts (0xc738), got %s", got.GasUsed)
+	}
+	if got.GasLimit != "0x2625a00" {
+		t.Errorf("Expected the block gas limit from getdgpinfo (0x2625a00), got %s", got.GasLimit)
+	}
+	if got.Miner != "0x7926223070547d2d15b2ef5e7383e541c338ffe9" {
+		t.Errorf("Expected the staker to be the miner, got %s", got.Miner)
+	}
+
+	// the details of the block are cached, serving it again only needs the header and the block
+	for _, method := range []string{qtum.MethodGetTransactionReceipt, qtum.MethodGetDGPInfo, qtum.MethodGetRawTransaction} {
+		delete(mockedClientDoer.Responses, method)
+	}
+	cached, jsonErr := proxyEth.request(internal.NewEchoContext().Request().Context(), &eth.GetBlockByHashRequest{
+		BlockHash: internal.GetTransactionByHashBlockHash,
+	})
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if cached.LogsBloom != got.LogsBloom || cached.GasUsed != got.GasUsed || cached.GasLimit != got.GasLimit || cached.Miner != got.Miner {
+		t.Errorf("Expected the cached block details to be served, got %+v", cached)
+	}
+}
+
+func TestGetBlockByHashBloomWithoutReceipts(t *testing.T) {
+	resetCaches()
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockHeader, qtum.GetBlockHeaderResponse{Hash: internal.GetTransactionByHashBlockHash, Height: 3983})
+	if err != nil {
+		t.Fatal(err)
+	}
+	block := internal.GetBlockVerboseResponse
+	block.Txs = append([]*qtum.BlockTransaction{}, internal.GetBlockVerboseResponse.Txs...)
+	block.Txs = append(block.Txs, callTransaction("d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", "28"))
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlock, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+	// qtumd runs without -logevents
+	err = mockedClientDoer.AddError(qtum.MethodGetTransactionReceipt, eth.NewCallbackError("-logevents is not enabled"))
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHGetBlockByHash{qtumClient}
+	got, jsonErr := proxyEth.request(internal.NewEchoContext().Request().Context(), &eth.GetBlockByHashRequest{
+		BlockHash: internal.GetTransactionByHashBlockHash,
+	})
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	if got.LogsBloom != eth.FullLogsBloom {
+		t.Errorf("Expected a bloom matching every log when the receipts are unknown, got %s", got.LogsBloom)
+	}
+}
+
+func TestGetBlockByHashTransactionsFromVerboseBlock(t *testing.T) {
+	resetCaches()
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	staker := "qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW"
+	coinstakeID := internal.GetBlockResponse.Txs[1]
+	paymentID := "3ef34ec81e32cd6df4ad3d5b3b4f8b2c54d9bc6f1a7b8d0ec1f2a6b1c9d8e7f6"
+	block := internal.GetBlockVerboseResponse
+	block.Txs = []*qtum.BlockTransaction{
+		internal.GetBlockVerboseResponse.Txs[0],
+		{DecodedRawTransactionResponse: qtum.DecodedRawTransactionResponse{
+			ID: coinstakeID,
+			// getblock verbosity 3 includes the staked output
+			Vins: []*qtum.DecodedRawTransactionInV{{
+				TxID: "7f5350dc474f2953a3f30282c1afcad2fb61cdcea5bd949c808ecc6f64ce1503",
+				Prevout: &qtum.DecodedRawTransactionPrevout{
+					Height:       3000,
+					Value:        decimal.NewFromFloat(2.5),
+					ScriptPubKey: qtum.RawTransactionVoutDetails{Address: staker},
+				},
+			}},
+			Vouts: []*qtum.DecodedRawTransactionOutV{
+				{N: 0},
+				{Value: decimal.NewFromFloat(2.9), N: 1, ScriptPubKey: qtum.DecodedRawTransactionScriptPubKey{Addresses: []string{staker}}},
+			},
+		}},
+		{
+			DecodedRawTransactionResponse: qtum.DecodedRawTransactionResponse{
+				ID: paymentID,
+				// spends the staker's output of the coinstake
+				Vins: []*qtum.DecodedRawTransactionInV{{
+					TxID: coinstakeID,
+					Vout: 1,
+					Prevout: &qtum.DecodedRawTransactionPrevout{
+						Height:       3983,
+						Value:        decimal.NewFromFloat(2.9),
+						ScriptPubKey: qtum.RawTransactionVoutDetails{Address: staker},
+					},
+				}},
+				Vouts: []*qtum.DecodedRawTransactionOutV{
+					{Value: decimal.NewFromFloat(1.5), N: 0, ScriptPubKey: qtum.DecodedRawTransactionScriptPubKey{Addresses: []string{"qTTH1Yr2eKCuDLqfxUyBLCAjmomQ8pyrBt"}}},
+					{Value: decimal.NewFromFloat(0.4), N: 1, ScriptPubKey: qtum.DecodedRawTransactionScriptPubKey{Addresses: []string{staker}}},
+				},
+			},
+			Hex: "0200000001",
+		},
+	}
+
+	// no transaction is looked up on its own: getrawtransaction and gettransaction aren't mocked
+	responses := []struct {
+		method   string
+		response interface{}
+	}{
+		{qtum.MethodGetBlockHeader, qtum.GetBlockHeaderResponse{Hash: internal.GetTransactionByHashBlockHash, Height: 3983, Previousblockhash: internal.GetBlockResponse.Previousblockhash}},
+		{qtum.MethodGetBlock, block},
+		{qtum.MethodGetDGPInfo, qtum.GetDGPInfoResponse{BlockGasLimit: 40000000}},
+		{qtum.MethodGetHexAddress, "7926223070547d2d15b2ef5e7383e541c338ffe9"},
+		// the transactions of every sender are looked up at once
+		{qtum.MethodGetAddressDeltas, qtum.GetAddressDeltasResponse{
+			{TXID: "0c0dd2bd1c3ab5b3be4cc5a2d3a9d1c63ce9b55d4f9c7a22c5d7b0e8f7b6a5c4", Satoshis: -100000000, Height: 3000, Address: staker},
+			{TXID: coinstakeID, Satoshis: -250000000, Height: 3983, BlockIndex: 1, Address: staker},
+			{TXID: paymentID, Satoshis: -290000000, Height: 3983, BlockIndex: 2, Address: staker},
+		}},
+	}
+	for _, r := range responses {
+		if err := mockedClientDoer.AddResponse(r.method, r.response); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	proxyEth := ProxyETHGetBlockByHash{qtumClient}
+	got, jsonErr := proxyEth.request(internal.NewEchoContext().Request().Context(), &eth.GetBlockByHashRequest{
+		BlockHash:       internal.GetTransactionByHashBlockHash,
+		FullTransaction: true,
+	})
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if len(got.Transactions) != 3 {
+		t.Fatalf("Expected 3 transactions, got %d", len(got.Transactions))
+	}
+	if got.Miner != "0x7926223070547d2d15b2ef5e7383e541c338ffe9" {
+		t.Errorf("Expected the staker to be the miner, got %s", got.Miner)
+	}
+
+	wantCoinstake := eth.GetTransactionByHashResponse{
+		BlockHash:        internal.GetTransactionByHashBlockHexHash,
+		BlockNumber:      internal.GetTransactionByHashBlockNumberHex,
+		TransactionIndex: "0x1",
+		Hash:             utils.AddHexPrefix(coinstakeID),
+		Nonce:            "0x1",
+		Value:            "0x0",
+		Input:            "0x",
+		From:             "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
+		To:               "0x0000000000000000000000000000000000000000",
+		Gas:              "0x0",
+		GasPrice:         "0x0",
+		R:                "0xf000000000000000000000000000000000000000000000000000000000000000",
+		S:                "0xf000000000000000000000000000000000000000000000000000000000000000",
+		V:                "0x25",
+	}
+	internal.CheckTestResultDefault(wantCoinstake, got.Transactions[1], t, false)
+
+	want := eth.GetTransactionByHashResponse{
+		BlockHash:        internal.GetTransactionByHashBlockHexHash,
+		BlockNumber:      internal.GetTransactionByHashBlockNumberHex,
+		TransactionIndex: "0x2",
+		Hash:             utils.AddHexPrefix(paymentID),
+		Nonce:            "0x2",
+		Value:            "0x1a5e27eef13e0000",
+		Input:            "0x0200000001",
+		From:             "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
+		To:               "0x6c89a1a6ca2ae7c00b248bb2832d6f480f27da68",
+		Gas:              "0x0",
+		GasPrice:         "0x0",
+		R:                "0xf000000000000000000000000000000000000000000000000000000000000000",
+		S:                "0xf000000000000000000000000000000000000000000000000000000000000000",
+		V:                "0x25",
+	}
+	internal.CheckTestResultDefault(want, got.Transactions[2], t, false)
+}
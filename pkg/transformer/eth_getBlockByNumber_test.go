@@ -14,20 +14,22 @@ func initializeProxyETHGetBlockByNumber(qtumClient *qtum.Qtum) ETHProxy {
 }
 
 func TestGetBlockByNumberRequest(t *testing.T) {
-	testETHProxyRequest(
+	testETHProxyRequestWithSetup(
 		t,
 		initializeProxyETHGetBlockByNumber,
 		[]json.RawMessage{[]byte(`"` + internal.GetTransactionByHashBlockNumberHex + `"`), []byte(`false`)},
 		&internal.GetTransactionByHashResponse,
+		internal.SetupGetBlockByHashResponsesWithTransactions,
 	)
 }
 
 func TestGetBlockByNumberWithTransactionsRequest(t *testing.T) {
-	testETHProxyRequest(
+	testETHProxyRequestWithSetup(
 		t,
 		initializeProxyETHGetBlockByNumber,
 		[]json.RawMessage{[]byte(`"` + internal.GetTransactionByHashBlockNumberHex + `"`), []byte(`true`)},
 		&internal.GetTransactionByHashResponseWithTransactions,
+		internal.SetupGetBlockByHashResponsesWithTransactions,
 	)
 }
 
@@ -0,0 +1,104 @@
+package transformer
+
+import (
+	"context"
+
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+// ProxyETHGetBlockReceipts implements ETHProxy
+type ProxyETHGetBlockReceipts struct {
+	*qtum.Qtum
+}
+
+func (p *ProxyETHGetBlockReceipts) Method() string {
+	return "eth_getBlockReceipts"
+}
+
+func (p *ProxyETHGetBlockReceipts) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
+	var req eth.GetBlockReceiptsRequest
+	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
+		// TODO: Correct error code?
+		return nil, eth.NewInvalidParamsError(err.Error())
+	}
+
+	return p.request(c.Request().Context(), &req)
+}
+
+func (p *ProxyETHGetBlockReceipts) request(ctx context.Context, req *eth.GetBlockReceiptsRequest) (eth.GetBlockReceiptsResponse, eth.JSONRPCError) {
+	blockHash := utils.RemoveHexPrefix(req.BlockHash)
+	if blockHash == "" {
+		blockNum, jsonErr := getBlockNumberByRawParam(ctx, p.Qtum, req.BlockNumber, false)
+		if jsonErr != nil {
+			return nil, jsonErr
+		}
+		hash, jsonErr := proxyETHGetBlockByHash(ctx, p, p.Qtum, blockNum)
+		if jsonErr != nil {
+			return nil, jsonErr
+		}
+		if hash == nil {
+			return nil, nil
+		}
+		blockHash = utils.RemoveHexPrefix(string(*hash))
+	}
+
+	// getblock with verbosity 3 returns every decoded transaction along with the block,
+	// only the transactions with contract outputs have EVM receipts to fetch
+	block, err := p.GetBlockVerbose(ctx, blockHash)
+	if err != nil {
+		if err == qtum.ErrInvalidAddress {
+			p.GetDebugLogger().Log("msg", "Unknown block hash", "blockHash", blockHash)
+			return nil, nil
+		}
+		p.GetDebugLogger().Log("msg", "couldn't get block", "blockHash", blockHash, "err", err)
+		return nil, eth.NewCallbackError("couldn't get block")
+	}
+
+	contractTxs := map[string]*qtum.BlockTransaction{}
+	for _, tx := range block.Txs {
+		if _, isContractTx, _ := tx.ExtractContractInfo(); isContractTx {
+			contractTxs[tx.ID] = tx
+		}
+	}
+	// the receipts are shared with eth_getBlockByHash, which fetches them for the block's gas used and logs bloom
+	qtumReceipts, err := getBlockTransactionReceipts(ctx, p.Qtum, block)
+	if err != nil {
+		// gettransactionreceipt needs qtumd to run with -logevents
+		p.GetDebugLogger().Log("msg", "couldn't get receipts of block", "blockHash", blockHash, "err", err)
+	}
+
+	// receipts don't have nonces, which would take a lookup per sender
+	transactions, jsonErr := getBlockTransactions(ctx, p.Qtum, block, false)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	receipts := make(eth.GetBlockReceiptsResponse, 0, len(transactions))
+	for _, transaction := range transactions {
+		ethTx := transaction.(eth.GetTransactionByHashResponse)
+		txid := utils.RemoveHexPrefix(ethTx.Hash)
+		tx, isContractTx := contractTxs[txid]
+		if !isContractTx {
+			receipts = append(receipts, newNonContractTransactionReceipt(&ethTx))
+			continue
+		}
+
+		txReceipts, fetched := qtumReceipts[txid]
+		if !fetched {
+			// the other receipts of the block are still served, clients can retry this one with eth_getTransactionReceipt
+			receipts = append(receipts, nil)
+			continue
+		}
+		if len(txReceipts) == 0 {
+			receipts = append(receipts, newNonContractTransactionReceipt(&ethTx))
+			continue
+		}
+		// like eth_getTransactionReceipt, a transaction calling several contracts is represented by the receipt of its first contract output
+		receipts = append(receipts, newContractTransactionReceipt(p.Qtum, &txReceipts[0], tx.IsContractCreation()))
+	}
+
+	return receipts, nil
+}
@@ -0,0 +1,236 @@
+package transformer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/conversion"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
+	"github.com/shopspring/decimal"
+)
+
+// blockReceiptsTestBlock returns a block with a coinbase and a coinstake transaction, a contract call for each of callIDs and a payment
+func blockReceiptsTestBlock(callIDs []string, paymentID string) qtum.GetBlockVerboseResponse {
+	coinstakeID := internal.GetBlockResponse.Txs[1]
+	block := internal.GetBlockVerboseResponse
+	block.Txs = []*qtum.BlockTransaction{
+		internal.GetBlockVerboseResponse.Txs[0],
+		{DecodedRawTransactionResponse: qtum.DecodedRawTransactionResponse{
+			ID: coinstakeID,
+			Vouts: []*qtum.DecodedRawTransactionOutV{
+				{N: 0},
+				{N: 1, ScriptPubKey: qtum.DecodedRawTransactionScriptPubKey{Addresses: []string{"qUbxboqjBRp96j3La8D1RYkyqx5uQbJPoW"}}},
+			},
+		}},
+	}
+	for _, callID := range callIDs {
+		block.Txs = append(block.Txs, &qtum.BlockTransaction{
+			DecodedRawTransactionResponse: qtum.DecodedRawTransactionResponse{
+				ID:   callID,
+				Vins: []*qtum.DecodedRawTransactionInV{{TxID: coinstakeID, Vout: 1}},
+				Vouts: []*qtum.DecodedRawTransactionOutV{
+					{Value: decimal.Zero, N: 0, ScriptPubKey: qtum.DecodedRawTransactionScriptPubKey{
+						Hex: "540390d003012844095ea7b300000000000000000000000025495b3a87d82e9d7a71b341addfc0d7bb3475c7ffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffffff1454fefdb5b31164f66ddb68becd7bdd864cacd65bc2",
+					}},
+				},
+			},
+			Hex: "0200000002",
+		})
+	}
+	block.Txs = append(block.Txs, &qtum.BlockTransaction{
+		DecodedRawTransactionResponse: qtum.DecodedRawTransactionResponse{
+			ID:   paymentID,
+			Vins: []*qtum.DecodedRawTransactionInV{{TxID: coinstakeID, Vout: 1}},
+			Vouts: []*qtum.DecodedRawTransactionOutV{
+				{Value: decimal.NewFromFloat(1.5), N: 0, ScriptPubKey: qtum.DecodedRawTransactionScriptPubKey{Addresses: []string{"qTTH1Yr2eKCuDLqfxUyBLCAjmomQ8pyrBt"}}},
+			},
+		},
+		Hex: "0200000001",
+	})
+	return block
+}
+
+func TestGetBlockReceiptsRequest(t *testing.T) {
+	resetCaches()
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callID := "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451"
+	paymentID := "3ef34ec81e32cd6df4ad3d5b3b4f8b2c54d9bc6f1a7b8d0ec1f2a6b1c9d8e7f6"
+	block := blockReceiptsTestBlock([]string{callID}, paymentID)
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlock, block)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callReceipt := qtum.TransactionReceipt{
+		BlockHash:         internal.GetTransactionByHashBlockHash,
+		BlockNumber:       3983,
+		TransactionHash:   callID,
+		TransactionIndex:  2,
+		From:              "7926223070547d2d15b2ef5e7383e541c338ffe9",
+		To:                "54fefdb5b31164f66ddb68becd7bdd864cacd65b",
+		CumulativeGasUsed: 46138,
+		GasUsed:           46138,
+		ContractAddress:   "54fefdb5b31164f66ddb68becd7bdd864cacd65b",
+		Excepted:          "None",
+		Log: []qtum.Log{{
+			Address: "54fefdb5b31164f66ddb68becd7bdd864cacd65b",
+			Topics:  []string{"8c5be1e5ebec7d5bd14f71427d1e84f3dd0314c0f7b2291e5b200ac8c7c3b925"},
+			Data:    "00000000000000000000000000000000000000000000000000000000000000ff",
+		}},
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetTransactionReceipt, []qtum.TransactionReceipt{callReceipt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the reward transactions are still looked up one by one
+	internal.SetupGetBlockByHashResponses(t, mockedClientDoer)
+
+	requestParams := []json.RawMessage{[]byte(`"` + internal.GetTransactionByHashBlockHexHash + `"`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyEth := ProxyETHGetBlockReceipts{qtumClient}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	receipts := got.(eth.GetBlockReceiptsResponse)
+	if len(receipts) != 4 {
+		t.Fatalf("Expected 4 receipts, got %d", len(receipts))
+	}
+
+	r := qtum.TransactionReceipt(callReceipt)
+	wantCall := &eth.GetTransactionReceiptResponse{
+		TransactionHash:   utils.AddHexPrefix(callID),
+		TransactionIndex:  "0x2",
+		BlockHash:         internal.GetTransactionByHashBlockHexHash,
+		BlockNumber:       internal.GetTransactionByHashBlockNumberHex,
+		From:              "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
+		To:                "0x54fefdb5b31164f66ddb68becd7bdd864cacd65b",
+		EffectiveGasPrice: "0x0",
+		CumulativeGasUsed: "0xb43a",
+		GasUsed:           "0xb43a",
+		Logs:              conversion.ExtractETHLogsFromTransactionReceipt(&r, r.Log),
+		Status:            STATUS_SUCCESS,
+	}
+	wantCall.LogsBloom = eth.LogsBloom(wantCall.Logs)
+	internal.CheckTestResultDefault(wantCall, receipts[2], t, false)
+
+	wantPayment := &eth.GetTransactionReceiptResponse{
+		TransactionHash:   utils.AddHexPrefix(paymentID),
+		TransactionIndex:  "0x3",
+		BlockHash:         internal.GetTransactionByHashBlockHexHash,
+		BlockNumber:       internal.GetTransactionByHashBlockNumberHex,
+		From:              "0x7926223070547d2d15b2ef5e7383e541c338ffe9",
+		To:                "0x6c89a1a6ca2ae7c00b248bb2832d6f480f27da68",
+		EffectiveGasPrice: "0x0",
+		CumulativeGasUsed: NonContractVMGasLimit,
+		GasUsed:           NonContractVMGasLimit,
+		Logs:              []eth.Log{},
+		LogsBloom:         eth.EmptyLogsBloom,
+		Status:            STATUS_SUCCESS,
+	}
+	internal.CheckTestResultDefault(wantPayment, receipts[3], t, false)
+
+	// the reward transactions don't execute contracts
+	for i, receipt := range receipts[:2] {
+		if receipt.GasUsed != NonContractVMGasLimit || len(receipt.Logs) != 0 || receipt.Status != STATUS_SUCCESS {
+			t.Errorf("Expected a receipt without logs for reward transaction %d, got %+v", i, receipt)
+		}
+	}
+
+	// the receipts of the block are cached, so they aren't fetched again
+	delete(mockedClientDoer.Responses, qtum.MethodGetTransactionReceipt)
+	got, jsonErr = proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	internal.CheckTestResultDefault(wantCall, got.(eth.GetBlockReceiptsResponse)[2], t, false)
+}
+
+func TestGetBlockReceiptsWithFailedReceipt(t *testing.T) {
+	resetCaches()
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	callID := "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451"
+	failedCallID := "8f0c6d2a1e5b4c7d9a3e2f1b0c4d5e6f7a8b9c0d1e2f3a4b5c6d7e8f9a0b1c2d"
+	paymentID := "3ef34ec81e32cd6df4ad3d5b3b4f8b2c54d9bc6f1a7b8d0ec1f2a6b1c9d8e7f6"
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlock, blockReceiptsTestBlock([]string{callID, failedCallID}, paymentID))
+	if err != nil {
+		t.Fatal(err)
+	}
+	callReceipt := qtum.TransactionReceipt{
+		BlockHash:        internal.GetTransactionByHashBlockHash,
+		BlockNumber:      3983,
+		TransactionHash:  callID,
+		TransactionIndex: 2,
+		GasUsed:          46138,
+		Excepted:         "None",
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetTransactionReceipt, []qtum.TransactionReceipt{callReceipt})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddError(qtum.MethodGetTransactionReceipt, eth.NewCallbackError("couldn't read receipt"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	internal.SetupGetBlockByHashResponses(t, mockedClientDoer)
+
+	requestParams := []json.RawMessage{[]byte(`"` + internal.GetTransactionByHashBlockHexHash + `"`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	proxyEth := ProxyETHGetBlockReceipts{qtumClient}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	// the coinbase, coinstake, call and payment receipts are still served, the failed one is null
+	receipts := got.(eth.GetBlockReceiptsResponse)
+	if len(receipts) != 5 {
+		t.Fatalf("Expected 5 receipts, got %d", len(receipts))
+	}
+	for i, receipt := range receipts {
+		if i == 3 {
+			if receipt != nil {
+				t.Errorf("Expected no receipt for the transaction whose receipt couldn't be fetched, got %+v", receipt)
+			}
+			continue
+		}
+		if receipt == nil {
+			t.Fatalf("Expected a receipt for transaction %d", i)
+		}
+	}
+	if receipts[2].TransactionHash != utils.AddHexPrefix(callID) || receipts[2].GasUsed != "0xb43a" {
+		t.Errorf("Expected the receipt of the call, got %+v", receipts[2])
+	}
+	if receipts[4].TransactionHash != utils.AddHexPrefix(paymentID) || receipts[4].GasUsed != NonContractVMGasLimit {
+		t.Errorf("Expected the receipt of the payment, got %+v", receipts[4])
+	}
+	for i, receipt := range receipts[:2] {
+		if receipt.GasUsed != NonContractVMGasLimit || len(receipt.Logs) != 0 {
+			t.Errorf("Expected a receipt without logs for reward transaction %d, got %+v", i, receipt)
+		}
+	}
+
+	// receipts of a block are only cached once they were all fetched
+	if _, ok := minedBlockReceipts.get(internal.GetTransactionByHashBlockHash); ok {
+		t.Error("Expected the receipts of a block with a failed receipt not to be cached")
+	}
+}
@@ -29,6 +29,11 @@ func (p *ProxyETHGetCode) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (i
 }
 
 func (p *ProxyETHGetCode) request(ctx context.Context, ethreq *eth.GetCodeRequest) (eth.GetCodeResponse, eth.JSONRPCError) {
+	// qtumd only keeps the latest code of contracts, which is returned for past blocks too
+	if _, jsonErr := getStateBlockNumber(ctx, p.Qtum, ethreq.BlockNumber); jsonErr != nil {
+		return "", jsonErr
+	}
+
 	qtumreq := qtum.GetAccountInfoRequest(utils.RemoveHexPrefix(ethreq.Address))
 
 	qtumresp, err := p.GetAccountInfo(ctx, &qtumreq)
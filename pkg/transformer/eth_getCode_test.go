@@ -12,7 +12,7 @@ import (
 
 func TestGetAccountInfoRequest(t *testing.T) {
 	//prepare request
-	requestParams := []json.RawMessage{[]byte(`"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"`), []byte(`"123"`)}
+	requestParams := []json.RawMessage{[]byte(`"0x1e6f89d7399081b4f8f8aa1ae2805a5efff2f960"`), []byte(`"latest"`)}
 	requestRPC, err := internal.PrepareEthRPCRequest(1, requestParams)
 	if err != nil {
 		t.Fatal(err)
@@ -57,7 +57,7 @@ func TestGetAccountInfoRequest(t *testing.T) {
 
 func TestGetCodeInvalidAddressRequest(t *testing.T) {
 	//prepare request
-	requestParams := []json.RawMessage{[]byte(`"0x0000000000000000000000000000000000000000"`), []byte(`"123"`)}
+	requestParams := []json.RawMessage{[]byte(`"0x0000000000000000000000000000000000000000"`), []byte(`"latest"`)}
 	requestRPC, err := internal.PrepareEthRPCRequest(1, requestParams)
 	if err != nil {
 		t.Fatal(err)
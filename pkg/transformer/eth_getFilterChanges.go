@@ -9,6 +9,8 @@ import (
 
 	"github.com/qtumproject/janus/pkg/conversion"
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/logindex"
+	"github.com/qtumproject/janus/pkg/notifier"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/qtumproject/janus/pkg/utils"
 )
@@ -16,7 +18,9 @@ import (
 // ProxyETHGetFilterChanges implements ETHProxy
 type ProxyETHGetFilterChanges struct {
 	*qtum.Qtum
-	filter *eth.FilterSimulator
+	filter   *eth.FilterSimulator
+	logIndex *logindex.Index
+	agent    *notifier.Agent
 }
 
 func (p *ProxyETHGetFilterChanges) Method() string {
@@ -36,7 +40,7 @@ func (p *ProxyETHGetFilterChanges) Request(rawreq *eth.JSONRPCRequest, c echo.Co
 	case eth.NewBlockFilterTy:
 		return p.requestBlockFilter(c.Request().Context(), filter)
 	case eth.NewPendingTransactionFilterTy:
-		fallthrough
+		return p.requestPendingTransactionFilter(filter)
 	default:
 		return nil, eth.NewInvalidParamsError("Unknown filter type")
 	}
@@ -76,6 +80,26 @@ func (p *ProxyETHGetFilterChanges) requestBlockFilter(ctx context.Context, filte
 	return
 }
 
+func (p *ProxyETHGetFilterChanges) requestPendingTransactionFilter(filter *eth.Filter) (qtumresp eth.GetFilterChangesResponse, err eth.JSONRPCError) {
+	qtumresp = make(eth.GetFilterChangesResponse, 0)
+
+	_cursor, ok := filter.Data.Load("cursor")
+	if !ok {
+		return qtumresp, eth.NewCallbackError("Could not get cursor")
+	}
+	if p.agent == nil {
+		return qtumresp, eth.NewCallbackError("Pending transactions are not available")
+	}
+
+	hashes, cursor := p.agent.PendingTransactionsSince(_cursor.(uint64))
+	for _, hash := range hashes {
+		qtumresp = append(qtumresp, hash)
+	}
+
+	filter.Data.Store("cursor", cursor)
+	return
+}
+
 func (p *ProxyETHGetFilterChanges) requestFilter(ctx context.Context, filter *eth.Filter) (qtumresp eth.GetFilterChangesResponse, err eth.JSONRPCError) {
 	qtumresp = make(eth.GetFilterChangesResponse, 0)
 
@@ -106,7 +130,7 @@ func (p *ProxyETHGetFilterChanges) requestFilter(ctx context.Context, filter *et
 }
 
 func (p *ProxyETHGetFilterChanges) doSearchLogs(ctx context.Context, req *qtum.SearchLogsRequest) (eth.GetFilterChangesResponse, eth.JSONRPCError) {
-	resp, err := conversion.SearchLogsAndFilterExtraTopics(ctx, p.Qtum, req)
+	resp, err := searchLogs(ctx, p.Qtum, p.logIndex, req)
 	if err != nil {
 		return nil, err
 	}
@@ -1,12 +1,14 @@
 package transformer
 
 import (
+	"context"
 	"encoding/json"
 	"math/big"
 	"testing"
 
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/notifier"
 	"github.com/qtumproject/janus/pkg/qtum"
 )
 
@@ -48,7 +50,7 @@ func TestGetFilterChangesRequest_EmptyResult(t *testing.T) {
 	filter.Data.Store("lastBlockNumber", uint64(657655))
 
 	//preparing proxy & executing request
-	proxyEth := ProxyETHGetFilterChanges{qtumClient, filterSimulator}
+	proxyEth := ProxyETHGetFilterChanges{Qtum: qtumClient, filter: filterSimulator}
 	got, jsonErr := proxyEth.Request(requestRPC, internal.NewEchoContext())
 	if jsonErr != nil {
 		t.Fatal(jsonErr)
@@ -88,7 +90,7 @@ func TestGetFilterChangesRequest_NoNewBlocks(t *testing.T) {
 	filter.Data.Store("lastBlockNumber", uint64(657655))
 
 	//preparing proxy & executing request
-	proxyEth := ProxyETHGetFilterChanges{qtumClient, filterSimulator}
+	proxyEth := ProxyETHGetFilterChanges{Qtum: qtumClient, filter: filterSimulator}
 	got, jsonErr := proxyEth.Request(requestRPC, internal.NewEchoContext())
 	if jsonErr != nil {
 		t.Fatal(jsonErr)
@@ -115,10 +117,57 @@ func TestGetFilterChangesRequest_NoSuchFilter(t *testing.T) {
 
 	//preparing proxy & executing request
 	filterSimulator := eth.NewFilterSimulator()
-	proxyEth := ProxyETHGetFilterChanges{qtumClient, filterSimulator}
+	proxyEth := ProxyETHGetFilterChanges{Qtum: qtumClient, filter: filterSimulator}
 	_, got := proxyEth.Request(requestRPC, internal.NewEchoContext())
 
 	want := eth.NewCallbackError("Invalid filter id")
 
 	internal.CheckTestResultEthRequestRPC(*requestRPC, want, got, t, false)
 }
+
+func TestGetFilterChangesRequest_PendingTransactionFilter(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	defer cancel()
+
+	//prepare request
+	requestParams := []json.RawMessage{[]byte(`"0x1"`)}
+	requestRPC, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+	//prepare client
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//preparing client response
+	err = mockedClientDoer.AddResponse(qtum.MethodGetRawMempool, qtum.GetRawMempoolResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//preparing filter
+	agent := notifier.NewAgent(ctx, qtumClient, nil)
+	filterSimulator := eth.NewFilterSimulator()
+	newFilterProxy := ProxyETHNewPendingTransactionFilter{Qtum: qtumClient, filter: filterSimulator, agent: agent}
+	filterID, jsonErr := newFilterProxy.Request(requestRPC, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	if filterID != eth.NewPendingTransactionFilterResponse("0x1") {
+		t.Fatalf("Unexpected filter id %v", filterID)
+	}
+
+	//preparing proxy & executing request
+	proxyEth := ProxyETHGetFilterChanges{Qtum: qtumClient, filter: filterSimulator, agent: agent}
+	got, jsonErr := proxyEth.Request(requestRPC, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	want := eth.GetFilterChangesResponse{}
+
+	internal.CheckTestResultEthRequestRPC(*requestRPC, want, got, t, false)
+}
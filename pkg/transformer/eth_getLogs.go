@@ -7,6 +7,7 @@ import (
 	"github.com/labstack/echo"
 	"github.com/qtumproject/janus/pkg/conversion"
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/logindex"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/qtumproject/janus/pkg/utils"
 )
@@ -14,6 +15,7 @@ import (
 // ProxyETHGetLogs implements ETHProxy
 type ProxyETHGetLogs struct {
 	*qtum.Qtum
+	logIndex *logindex.Index
 }
 
 func (p *ProxyETHGetLogs) Method() string {
@@ -42,7 +44,7 @@ func (p *ProxyETHGetLogs) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (i
 }
 
 func (p *ProxyETHGetLogs) request(ctx context.Context, req *qtum.SearchLogsRequest) (*eth.GetLogsResponse, eth.JSONRPCError) {
-	receipts, err := conversion.SearchLogsAndFilterExtraTopics(ctx, p.Qtum, req)
+	receipts, err := searchLogs(ctx, p.Qtum, p.logIndex, req)
 	if err != nil {
 		return nil, err
 	}
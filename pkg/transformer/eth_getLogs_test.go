@@ -262,7 +262,7 @@ func TestMultipleLogsWithORdTopics(t *testing.T) {
 
 	//Prepare proxy & execute
 	//preparing proxy & executing
-	proxyEth := ProxyETHGetLogs{qtumClient}
+	proxyEth := ProxyETHGetLogs{Qtum: qtumClient}
 
 	got, jsonErr := proxyEth.Request(requestRPC, internal.NewEchoContext())
 	if jsonErr != nil {
@@ -391,7 +391,7 @@ func testGetLogsWithTopics(t *testing.T, topics []interface{}, want eth.GetLogsR
 
 	//Prepare proxy & execute
 	//preparing proxy & executing
-	proxyEth := ProxyETHGetLogs{qtumClient}
+	proxyEth := ProxyETHGetLogs{Qtum: qtumClient}
 
 	got, jsonErr := proxyEth.Request(requestRPC, internal.NewEchoContext())
 	if jsonErr != nil {
@@ -435,7 +435,7 @@ func TestGetLogsTranslateTopicWorksWithNil(t *testing.T) {
 
 	//Prepare proxy & execute
 	//preparing proxy & executing
-	proxyEth := ProxyETHGetLogs{qtumClient}
+	proxyEth := ProxyETHGetLogs{Qtum: qtumClient}
 
 	qtumRequest, jsonErr := proxyEth.ToRequest(context.Background(), &request)
 	if jsonErr != nil {
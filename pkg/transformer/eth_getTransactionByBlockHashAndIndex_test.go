@@ -13,10 +13,11 @@ func initializeProxyETHGetTransactionByBlockHashAndIndex(qtumClient *qtum.Qtum)
 }
 
 func TestGetTransactionByBlockHashAndIndex(t *testing.T) {
-	testETHProxyRequest(
+	testETHProxyRequestWithSetup(
 		t,
 		initializeProxyETHGetTransactionByBlockHashAndIndex,
 		[]json.RawMessage{[]byte(`"` + internal.GetTransactionByHashBlockHash + `"`), []byte(`"0x0"`)},
-		internal.GetTransactionByHashResponseData,
+		internal.GetTransactionByHashCoinbaseData,
+		internal.SetupGetBlockByHashResponsesWithTransactions,
 	)
 }
@@ -13,10 +13,11 @@ func initializeProxyETHGetTransactionByBlockNumberAndIndex(qtumClient *qtum.Qtum
 }
 
 func TestGetTransactionByBlockNumberAndIndex(t *testing.T) {
-	testETHProxyRequest(
+	testETHProxyRequestWithSetup(
 		t,
 		initializeProxyETHGetTransactionByBlockNumberAndIndex,
 		[]json.RawMessage{[]byte(`"` + internal.GetTransactionByHashBlockNumberHex + `"`), []byte(`"0x0"`)},
-		internal.GetTransactionByHashResponseData,
+		internal.GetTransactionByHashCoinbaseData,
+		internal.SetupGetBlockByHashResponsesWithTransactions,
 	)
 }
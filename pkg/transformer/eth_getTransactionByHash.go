@@ -4,7 +4,6 @@ import (
 	"context"
 	"encoding/json"
 	"math/big"
-	"strings"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
@@ -50,10 +49,7 @@ func (p *ProxyETHGetTransactionByHash) request(ctx context.Context, req *qtum.Ge
 
 // TODO: think of returning flag if it's a reward transaction for miner
 //
-// FUTURE WORK: It might be possible to simplify this (and other?) translation by using a single verbose getblock qtum RPC command,
-// since it returns a lot of data including the equivalent of calling GetRawTransaction on every transaction in block.
-// The last point is of particular interest because GetRawTransaction doesn't by default work for every transaction.
-// This would mean fetching a lot of probably unnecessary data, but in this setup query response delay is reasonably the biggest bottleneck anyway
+// This takes several round trips to qtumd, blocks with full transactions are built from the verbose block and one getaddressdeltas call for the nonces instead, see getBlockTransactions
 func getTransactionByHash(ctx context.Context, p *qtum.Qtum, hash string) (*eth.GetTransactionByHashResponse, eth.JSONRPCError) {
 	qtumTx, err := p.GetTransaction(ctx, hash)
 	var ethTx *eth.GetTransactionByHashResponse
@@ -131,18 +127,32 @@ func getTransactionByHash(ctx context.Context, p *qtum.Qtum, hash string) (*eth.
 		}
 	}
 
+	getSender := func() (string, error) {
+		return getNonContractTxSenderAddress(ctx, p, qtumDecodedRawTx)
+	}
+	if err := setTransactionContent(p, ethTx, qtumDecodedRawTx, qtumTx.Hex, qtumTx.Generated, getSender); err != nil {
+		return nil, err
+	}
+
+	setTransactionNonce(ctx, p, ethTx)
+	return ethTx, nil
+}
+
+// setTransactionContent fills the value, input, sender, receiver and gas fields of a transaction from its decoded outputs.
+// getSender finds the sender of a transaction without an OP_SENDER output, it's allowed to fail for generated transactions
+func setTransactionContent(p *qtum.Qtum, ethTx *eth.GetTransactionByHashResponse, decoded *qtum.DecodedRawTransactionResponse, hex string, generated bool, getSender func() (string, error)) eth.JSONRPCError {
 	if ethTx.Value == "" {
 		// TODO: This CalcAmount() func needs improvement
-		ethAmount, err := formatQtumAmount(qtumDecodedRawTx.CalcAmount())
+		ethAmount, err := formatQtumAmount(decoded.CalcAmount())
 		if err != nil {
 			// TODO: Correct error code?
-			p.GetDebugLogger().Log("msg", "Couldn't format qtum amount", "qtum", qtumDecodedRawTx.CalcAmount().String(), "err", err)
-			return nil, eth.NewInvalidParamsError("couldn't format amount")
+			p.GetDebugLogger().Log("msg", "Couldn't format qtum amount", "qtum", decoded.CalcAmount().String(), "err", err)
+			return eth.NewInvalidParamsError("couldn't format amount")
 		}
 		ethTx.Value = ethAmount
 	}
 
-	qtumTxContractInfo, isContractTx, _ := qtumDecodedRawTx.ExtractContractInfo()
+	qtumTxContractInfo, isContractTx, _ := decoded.ExtractContractInfo()
 	// parsing err is discarded because it's not an error if the transaction is not a valid contract call
 	// https://testnet.qtum.info/tx/24ed3749022ed21e53d8924764bb0303a4b6fa469f26922bfa64ba44507c4c4a
 	// if err != nil {
@@ -160,11 +170,12 @@ func getTransactionByHash(ctx context.Context, p *qtum.Qtum, hash string) (*eth.
 			ethTx.From = utils.AddHexPrefix(qtumTxContractInfo.From)
 		} else {
 			// It seems that ExtractContractInfo only looks for OP_SENDER address when assigning From field, so if none is present we handle it like for a non-contract TX
-			ethTx.From, err = getNonContractTxSenderAddress(ctx, p, qtumDecodedRawTx)
+			from, err := getSender()
 			if err != nil {
-				p.GetDebugLogger().Log("msg", "Contract tx parsing found no sender address", "tx", qtumDecodedRawTx, "err", err)
-				return nil, eth.NewCallbackError("Contract tx parsing found no sender address, and the fallback function also failed: " + err.Error())
+				p.GetDebugLogger().Log("msg", "Contract tx parsing found no sender address", "tx", decoded, "err", err)
+				return eth.NewCallbackError("Contract tx parsing found no sender address, and the fallback function also failed: " + err.Error())
 			}
+			ethTx.From = from
 		}
 		//TODO: research if 'To' adress could be other than zero address when 'isContractTx == TRUE'
 		if len(qtumTxContractInfo.To) == 0 {
@@ -179,31 +190,23 @@ func getTransactionByHash(ctx context.Context, p *qtum.Qtum, hash string) (*eth.
 		}
 		ethTx.Gas = utils.AddHexPrefix(qtumTxContractInfo.GasLimit)
 
-		// trim leading zeros from gasPrice
-		qtumTxContractInfo.GasPrice = strings.TrimLeft(qtumTxContractInfo.GasPrice, "0")
-		if len(qtumTxContractInfo.GasPrice) == 0 {
-			qtumTxContractInfo.GasPrice = "0"
-		}
-		// Gas price is in hex satoshis, convert to wei
-		gasPriceInSatoshis, err := utils.DecodeBig(qtumTxContractInfo.GasPrice)
+		gasPriceInWei, err := contractGasPriceInWei(qtumTxContractInfo)
 		if err != nil {
 			p.GetErrorLogger().Log("msg", "Failed to parse gasPrice: "+qtumTxContractInfo.GasPrice, "error", err.Error())
-			return ethTx, eth.NewCallbackError("Failed to parse gasPrice")
+			return eth.NewCallbackError("Failed to parse gasPrice")
 		}
-
-		gasPriceInWei := convertFromSatoshiToWei(gasPriceInSatoshis)
 		ethTx.GasPrice = hexutil.EncodeBig(gasPriceInWei)
 
-		return ethTx, nil
+		return nil
 	}
 
-	if qtumTx.Generated {
+	if generated {
 		ethTx.From = utils.AddHexPrefix(qtum.ZeroAddress)
 	} else {
 		// TODO: Figure out if following code still cause issues in some cases, see next comment
 
 		// causes issues on coinbase txs, coinbase will not have a sender and so this should be able to fail
-		ethTx.From, _ = getNonContractTxSenderAddress(ctx, p, qtumDecodedRawTx)
+		ethTx.From, _ = getSender()
 
 		// TODO: discuss
 		// ? Does func above return incorrect address for graph-node (len is < 40)
@@ -213,7 +216,8 @@ func getTransactionByHash(ctx context.Context, p *qtum.Qtum, hash string) (*eth.
 		}
 	}
 	if ethTx.To == "" {
-		ethTx.To, err = findNonContractTxReceiverAddress(qtumDecodedRawTx.Vouts)
+		var err error
+		ethTx.To, err = findNonContractTxReceiverAddress(decoded.Vouts)
 		if err != nil {
 			// TODO: discuss, research
 			// ? Some vouts doesn't have `receive` category at all
@@ -232,14 +236,29 @@ func getTransactionByHash(ctx context.Context, p *qtum.Qtum, hash string) (*eth.
 
 	// TODO: researching
 	// ! Temporary solution
-	//	if len(qtumTx.Hex) == 0 {
+	//	if len(hex) == 0 {
 	//		ethTx.Input = "0x0"
 	//	} else {
-	//		ethTx.Input = utils.AddHexPrefix(qtumTx.Hex)
+	//		ethTx.Input = utils.AddHexPrefix(hex)
 	//	}
-	ethTx.Input = utils.AddHexPrefix(qtumTx.Hex)
+	ethTx.Input = utils.AddHexPrefix(hex)
 
-	return ethTx, nil
+	return nil
+}
+
+// setTransactionNonce sets the nonce of a transaction to the number of transactions its sender sent before it, the same way eth_getTransactionCount counts them
+func setTransactionNonce(ctx context.Context, p *qtum.Qtum, ethTx *eth.GetTransactionByHashResponse) {
+	if ethTx.From == "" || ethTx.From == utils.AddHexPrefix(qtum.ZeroAddress) {
+		// coinbase and coinstake transactions have no sender
+		return
+	}
+	nonce, err := getTransactionNonce(ctx, p, ethTx.From, ethTx.Hash, ethTx.BlockNumber, ethTx.BlockHash)
+	if err != nil {
+		// getaddressdeltas needs qtumd to run with -addrindex, the nonce is left at 0 without it
+		p.GetDebugLogger().Log("msg", "Couldn't get transaction nonce", "hash", ethTx.Hash, "from", ethTx.From, "err", err)
+		return
+	}
+	ethTx.Nonce = formatQtumNonce(nonce)
 }
 
 // TODO: Does this need to return eth.JSONRPCError
@@ -301,6 +320,13 @@ func getRewardTransactionByHash(ctx context.Context, p *qtum.Qtum, hash string)
 		ethTx.Value = "0x0"
 	}
 
+	setRewardTransactionContent(p, ethTx, rawQtumTx)
+
+	return ethTx, rawQtumTx, nil
+}
+
+// setRewardTransactionContent sets the sender, receiver and value of a coinbase or coinstake transaction, the amounts of its inputs are those of the outputs they spend
+func setRewardTransactionContent(p *qtum.Qtum, ethTx *eth.GetTransactionByHashResponse, rawQtumTx *qtum.GetRawTransactionResponse) {
 	// TODO: discuss
 	// ? Do we have to set `from` == `0x00..00`
 	ethTx.From = utils.AddHexPrefix(qtum.ZeroAddress)
@@ -394,6 +420,4 @@ func getRewardTransactionByHash(ctx context.Context, p *qtum.Qtum, hash string)
 		// TODO: compute gasPrice based on fee, guess a gas amount based on vin/vout
 		// gas price is set in the OP_CALL/OP_CREATE script
 	}
-
-	return ethTx, rawQtumTx, nil
 }
@@ -1,15 +1,18 @@
 package transformer
 
 import (
+	"context"
 	"math/big"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/ethereum/go-ethereum/common/hexutil"
 	"github.com/labstack/echo"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/qtumproject/janus/pkg/utils"
 )
 
-// ProxyETHEstimateGas implements ETHProxy
+// ProxyETHTxCount implements ETHProxy
 type ProxyETHTxCount struct {
 	*qtum.Qtum
 }
@@ -19,13 +22,38 @@ func (p *ProxyETHTxCount) Method() string {
 }
 
 func (p *ProxyETHTxCount) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
-
-	/* not sure we need this. Need to figure out how to best unmarshal this in the future. For now this will work.
 	var req eth.GetTransactionCountRequest
 	if err := unmarshalRequest(rawreq.Params, &req); err != nil {
-		return nil, err
-	}*/
-	qtumresp, err := p.Qtum.GetTransactionCount(c.Request().Context(), "", "")
+		// TODO: Correct error code?
+		return nil, eth.NewInvalidParamsError(err.Error())
+	}
+
+	return p.request(c.Request().Context(), &req)
+}
+
+func (p *ProxyETHTxCount) request(ctx context.Context, req *eth.GetTransactionCountRequest) (interface{}, eth.JSONRPCError) {
+	if !common.IsHexAddress(req.Address) {
+		return nil, eth.NewInvalidParamsError("invalid address: " + req.Address)
+	}
+	hexAddress := utils.RemoveHexPrefix(req.Address)
+	// Ethereum transactions of an --accounts key are sent from the key's Qtum address, so their nonces count
+	// the transactions of that address, like the nonce check of eth_sendRawTransaction
+	if key := p.Accounts.FindByEthereumAddress(common.HexToAddress(req.Address)); key != nil {
+		hexAddress = (&qtum.Account{WIF: key}).ToHexAddress()
+	}
+	address, err := convertETHAddress(hexAddress, p.Chain())
+	if err != nil {
+		return nil, eth.NewInvalidParamsError(err.Error())
+	}
+
+	// transactions in the mempool are only counted for "pending", so that it returns the nonce of the next transaction
+	includeMempool := string(req.Tag) == `"pending"`
+	blockNumber, jsonErr := getStateBlockNumber(ctx, p.Qtum, req.Tag)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	qtumresp, err := p.Qtum.GetTransactionCount(ctx, address, blockNumber, includeMempool)
 	if err != nil {
 		return nil, eth.NewCallbackError(err.Error())
 	}
@@ -5,11 +5,12 @@ import (
 	"testing"
 
 	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
 )
 
 func TestGetTransactionCountRequest(t *testing.T) {
 	//preparing request
-	requestParams := []json.RawMessage{}
+	requestParams := []json.RawMessage{[]byte(`"0x6d358cf96533189dd5a602d0937fddf0888ad3ae"`), []byte(`"latest"`)}
 	request, err := internal.PrepareEthRPCRequest(1, requestParams)
 	if err != nil {
 		t.Fatal(err)
@@ -21,6 +22,25 @@ func TestGetTransactionCountRequest(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	//preparing responses
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressDeltas, qtum.GetAddressDeltasResponse{
+		{TXID: "11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5", Satoshis: 200000000, Height: 10},
+		// a transaction spending two outputs is only counted once
+		{TXID: "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", Satoshis: -100000000, Height: 20},
+		{TXID: "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", Satoshis: -100000000, Height: 20},
+		{TXID: "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", Satoshis: 50000000, Height: 20},
+		{TXID: "7e22630f90e6db16283af2c6b04f688117a55db47e22630f90e6db16283af2c6", Satoshis: -50000000, Height: 30},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressMempool, qtum.GetAddressMempoolResponse{
+		{TXID: "a3a2941152d33326ab9d8437b4b53f722b747b18d20d309ee31830e5cc2e41d5", Satoshis: -30000000},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	//preparing proxy & executing request
 	proxyEth := ProxyETHTxCount{qtumClient}
 	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
@@ -28,7 +48,18 @@ func TestGetTransactionCountRequest(t *testing.T) {
 		t.Fatal(jsonErr)
 	}
 
-	want := string("0x1") //tx count is hardcoded inside the implement
+	want := string("0x2")
+
+	internal.CheckTestResultEthRequestRPC(*request, want, got, t, false)
+
+	// pending transactions are counted for "pending"
+	request.Params = []byte(`["0x6d358cf96533189dd5a602d0937fddf0888ad3ae", "pending"]`)
+	got, jsonErr = proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	want = string("0x3")
 
 	internal.CheckTestResultEthRequestRPC(*request, want, got, t, false)
 }
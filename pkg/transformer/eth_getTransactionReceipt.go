@@ -58,23 +58,58 @@ func (p *ProxyETHGetTransactionReceipt) request(ctx context.Context, req *qtum.G
 			// https://github.com/openethereum/parity-ethereum/issues/3482
 			return nil, nil
 		}
-		return &eth.GetTransactionReceiptResponse{
-			TransactionHash:  ethTx.Hash,
-			TransactionIndex: ethTx.TransactionIndex,
-			BlockHash:        ethTx.BlockHash,
-			BlockNumber:      ethTx.BlockNumber,
-			// TODO: This is higher than GasUsed in geth but does it matter?
-			CumulativeGasUsed: NonContractVMGasLimit,
-			EffectiveGasPrice: "0x0",
-			GasUsed:           NonContractVMGasLimit,
-			From:              ethTx.From,
-			To:                ethTx.To,
-			Logs:              []eth.Log{},
-			LogsBloom:         eth.EmptyLogsBloom,
-			Status:            STATUS_SUCCESS,
-		}, nil
+		return newNonContractTransactionReceipt(ethTx), nil
 	}
 
+	qtumTx, err := p.Qtum.GetRawTransaction(ctx, qtumReceipt.TransactionHash, false)
+	if err != nil {
+		p.GetDebugLogger().Log("msg", "couldn't get transaction", "err", err)
+		return nil, eth.NewCallbackError("couldn't get transaction")
+	}
+	decodedRawQtumTx, err := p.Qtum.DecodeRawTransaction(ctx, qtumTx.Hex)
+	if err != nil {
+		p.GetDebugLogger().Log("msg", "couldn't decode raw transaction", "err", err)
+		return nil, eth.NewCallbackError("couldn't decode raw transaction")
+	}
+
+	r := qtum.TransactionReceipt(*qtumReceipt)
+	ethReceipt := newContractTransactionReceipt(p.Qtum, &r, decodedRawQtumTx.IsContractCreation())
+
+	// TODO: researching
+	// - The following code reason is unknown (see original comment)
+	// - Code temporary commented, until an error occures
+	// ! Do not remove
+	// // contractAddress : DATA, 20 Bytes - The contract address created, if the transaction was a contract creation, otherwise null.
+	// if status != "0x1" {
+	// 	// if failure, should return null for contractAddress, instead of the zero address.
+	// 	ethTxReceipt.ContractAddress = ""
+	// }
+
+	return ethReceipt, nil
+}
+
+// newNonContractTransactionReceipt returns the receipt of a transaction without EVM receipts, such as a plain QTUM transfer or a reward transaction
+func newNonContractTransactionReceipt(ethTx *eth.GetTransactionByHashResponse) *eth.GetTransactionReceiptResponse {
+	return &eth.GetTransactionReceiptResponse{
+		TransactionHash:  ethTx.Hash,
+		TransactionIndex: ethTx.TransactionIndex,
+		BlockHash:        ethTx.BlockHash,
+		BlockNumber:      ethTx.BlockNumber,
+		// TODO: This is higher than GasUsed in geth but does it matter?
+		CumulativeGasUsed: NonContractVMGasLimit,
+		EffectiveGasPrice: "0x0",
+		GasUsed:           NonContractVMGasLimit,
+		From:              ethTx.From,
+		To:                ethTx.To,
+		Logs:              []eth.Log{},
+		// transactions without contract outputs don't execute contracts, so they have no logs
+		LogsBloom: eth.EmptyLogsBloom,
+		Status:    STATUS_SUCCESS,
+	}
+}
+
+// newContractTransactionReceipt translates the EVM receipt of a transaction
+func newContractTransactionReceipt(p *qtum.Qtum, qtumReceipt *qtum.TransactionReceipt, isContractCreation bool) *eth.GetTransactionReceiptResponse {
 	ethReceipt := &eth.GetTransactionReceiptResponse{
 		TransactionHash:   utils.AddHexPrefix(qtumReceipt.TransactionHash),
 		TransactionIndex:  hexutil.EncodeUint64(qtumReceipt.TransactionIndex),
@@ -86,48 +121,27 @@ func (p *ProxyETHGetTransactionReceipt) request(ctx context.Context, req *qtum.G
 		GasUsed:           hexutil.EncodeUint64(qtumReceipt.GasUsed),
 		From:              utils.AddHexPrefixIfNotEmpty(qtumReceipt.From),
 		To:                utils.AddHexPrefixIfNotEmpty(qtumReceipt.To),
-
-		// TODO: researching
-		// ! Temporary accept this value to be always zero, as it is at eth logs
-		LogsBloom: eth.EmptyLogsBloom,
 	}
 
 	status := STATUS_FAILURE
 	if qtumReceipt.Excepted == "None" {
 		status = STATUS_SUCCESS
 	} else {
-		p.Qtum.GetDebugLogger().Log("transaction", ethReceipt.TransactionHash, "msg", "transaction excepted", "message", qtumReceipt.Excepted)
+		p.GetDebugLogger().Log("transaction", ethReceipt.TransactionHash, "msg", "transaction excepted", "message", qtumReceipt.Excepted)
+		// receipts don't have the revert data, only the reason qtumd decoded from it
+		if qtumReceipt.Excepted == "Revert" && qtumReceipt.ExceptedMessage != "" {
+			ethReceipt.RevertReason = hexutil.Encode(eth.PackRevertReason(qtumReceipt.ExceptedMessage))
+		}
 	}
 	ethReceipt.Status = status
 
-	r := qtum.TransactionReceipt(*qtumReceipt)
-	ethReceipt.Logs = conversion.ExtractETHLogsFromTransactionReceipt(&r, r.Log)
+	ethReceipt.Logs = conversion.ExtractETHLogsFromTransactionReceipt(qtumReceipt, qtumReceipt.Log)
+	ethReceipt.LogsBloom = eth.LogsBloom(ethReceipt.Logs)
 
-	qtumTx, err := p.Qtum.GetRawTransaction(ctx, qtumReceipt.TransactionHash, false)
-	if err != nil {
-		p.GetDebugLogger().Log("msg", "couldn't get transaction", "err", err)
-		return nil, eth.NewCallbackError("couldn't get transaction")
-	}
-	decodedRawQtumTx, err := p.Qtum.DecodeRawTransaction(ctx, qtumTx.Hex)
-	if err != nil {
-		p.GetDebugLogger().Log("msg", "couldn't decode raw transaction", "err", err)
-		return nil, eth.NewCallbackError("couldn't decode raw transaction")
-	}
-	if decodedRawQtumTx.IsContractCreation() {
+	if isContractCreation {
 		ethReceipt.To = ""
 	} else {
 		ethReceipt.ContractAddress = ""
 	}
-
-	// TODO: researching
-	// - The following code reason is unknown (see original comment)
-	// - Code temporary commented, until an error occures
-	// ! Do not remove
-	// // contractAddress : DATA, 20 Bytes - The contract address created, if the transaction was a contract creation, otherwise null.
-	// if status != "0x1" {
-	// 	// if failure, should return null for contractAddress, instead of the zero address.
-	// 	ethTxReceipt.ContractAddress = ""
-	// }
-
-	return ethReceipt, nil
+	return ethReceipt
 }
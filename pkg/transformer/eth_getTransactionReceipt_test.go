@@ -4,6 +4,7 @@ import (
 	"encoding/json"
 	"testing"
 
+	"github.com/ethereum/go-ethereum/common"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/internal"
 	"github.com/qtumproject/janus/pkg/qtum"
@@ -67,3 +68,30 @@ func TestGetTransactionReceiptForNonVMTransaction(t *testing.T) {
 
 	internal.CheckTestResultEthRequestRPC(*request, &want, got, t, false)
 }
+
+func TestContractTransactionReceiptRevertReason(t *testing.T) {
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	qtumReceipt := internal.QtumTransactionReceipt(nil)
+	qtumReceipt.Excepted = "Revert"
+	qtumReceipt.ExceptedMessage = "Ownable: caller is not the owner"
+	receipt := newContractTransactionReceipt(qtumClient, &qtumReceipt, false)
+	if receipt.Status != STATUS_FAILURE {
+		t.Errorf("Expected status %s, got %s", STATUS_FAILURE, receipt.Status)
+	}
+	reason, ok := eth.UnpackRevertReason(common.FromHex(receipt.RevertReason))
+	if !ok || reason != qtumReceipt.ExceptedMessage {
+		t.Errorf("Expected revert reason %q, got %s", qtumReceipt.ExceptedMessage, receipt.RevertReason)
+	}
+
+	// other exceptions don't have a revert reason
+	qtumReceipt.Excepted = "OutOfGas"
+	qtumReceipt.ExceptedMessage = ""
+	if receipt := newContractTransactionReceipt(qtumClient, &qtumReceipt, false); receipt.RevertReason != "" {
+		t.Errorf("Expected no revert reason, got %s", receipt.RevertReason)
+	}
+}
@@ -0,0 +1,51 @@
+package transformer
+
+import (
+	"context"
+	"math/big"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/gasprice"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+// ProxyETHMaxPriorityFeePerGas implements ETHProxy
+type ProxyETHMaxPriorityFeePerGas struct {
+	*qtum.Qtum
+	// the same oracle as eth_gasPrice's, the minimum gas price is used without it
+	oracle *gasprice.Oracle
+}
+
+func (p *ProxyETHMaxPriorityFeePerGas) Method() string {
+	return "eth_maxPriorityFeePerGas"
+}
+
+func (p *ProxyETHMaxPriorityFeePerGas) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
+	return p.request(c.Request().Context())
+}
+
+// request suggests the part of eth_gasPrice's suggestion above the base fee, so that a transaction paying
+// the base fee plus this priority fee pays the gas price eth_gasPrice suggests from the same sample
+func (p *ProxyETHMaxPriorityFeePerGas) request(ctx context.Context) (eth.MaxPriorityFeePerGasResponse, eth.JSONRPCError) {
+	var (
+		gasPrice *big.Int
+		err      error
+	)
+	if p.oracle != nil {
+		gasPrice, err = p.oracle.SuggestGasPrice(ctx)
+	} else {
+		gasPrice, err = p.GetGasPrice(ctx)
+	}
+	if err != nil {
+		return "", eth.NewCallbackError(err.Error())
+	}
+
+	priorityFee := new(big.Int).Sub(convertFromSatoshiToWei(gasPrice), blockBaseFeePerGas())
+	if priorityFee.Sign() < 0 {
+		// the minimum gas price is enough when nobody pays more
+		priorityFee.SetInt64(0)
+	}
+	return eth.MaxPriorityFeePerGasResponse(hexutil.EncodeBig(priorityFee)), nil
+}
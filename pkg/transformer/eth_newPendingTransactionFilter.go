@@ -0,0 +1,36 @@
+package transformer
+
+import (
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/notifier"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+// ProxyETHNewPendingTransactionFilter implements ETHProxy
+type ProxyETHNewPendingTransactionFilter struct {
+	*qtum.Qtum
+	filter *eth.FilterSimulator
+	agent  *notifier.Agent
+}
+
+func (p *ProxyETHNewPendingTransactionFilter) Method() string {
+	return "eth_newPendingTransactionFilter"
+}
+
+func (p *ProxyETHNewPendingTransactionFilter) Request(rawreq *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
+	return p.request()
+}
+
+func (p *ProxyETHNewPendingTransactionFilter) request() (eth.NewPendingTransactionFilterResponse, eth.JSONRPCError) {
+	if p.agent == nil {
+		return "", eth.NewCallbackError("Pending transactions are not available")
+	}
+
+	filter := p.filter.New(eth.NewPendingTransactionFilterTy)
+	// the mempool is watched by the agent, the filter only remembers how far it has read
+	filter.Data.Store("cursor", p.agent.PendingTransactionsCursor())
+
+	return eth.NewPendingTransactionFilterResponse(hexutil.EncodeUint64(filter.ID)), nil
+}
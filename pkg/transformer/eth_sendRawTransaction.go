@@ -2,9 +2,17 @@ package transformer
 
 import (
 	"context"
+	"encoding/hex"
+	"fmt"
+	"math/big"
+
+	"github.com/btcsuite/btcutil"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
 
 	"github.com/labstack/echo"
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/ethtx"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/qtumproject/janus/pkg/utils"
 )
@@ -12,6 +20,8 @@ import (
 // ProxyETHSendRawTransaction implements ETHProxy
 type ProxyETHSendRawTransaction struct {
 	*qtum.Qtum
+	// records the accepted Ethereum transactions when set
+	ethTransactions *ethtx.Store
 }
 
 var _ ETHProxy = (*ProxyETHSendRawTransaction)(nil)
@@ -35,10 +45,72 @@ func (p *ProxyETHSendRawTransaction) Request(req *eth.JSONRPCRequest, c echo.Con
 }
 
 func (p *ProxyETHSendRawTransaction) request(ctx context.Context, params eth.SendRawTransactionRequest) (eth.SendRawTransactionResponse, eth.JSONRPCError) {
-	var (
-		qtumHexedRawTx = utils.RemoveHexPrefix(params[0])
-		req            = qtum.SendRawTransactionRequest([1]string{qtumHexedRawTx})
-	)
+	qtumHexedRawTx := utils.RemoveHexPrefix(params[0])
+	if ethTx := decodeEthereumTransaction(qtumHexedRawTx); ethTx != nil {
+		return p.requestEthereumTransaction(ctx, ethTx)
+	}
+	return p.send(ctx, qtumHexedRawTx)
+}
+
+// requestEthereumTransaction sends the Qtum equivalent of a signed Ethereum transaction, returning the Qtum transaction's hash
+func (p *ProxyETHSendRawTransaction) requestEthereumTransaction(ctx context.Context, ethTx *types.Transaction) (eth.SendRawTransactionResponse, eth.JSONRPCError) {
+	signer := types.LatestSignerForChainID(big.NewInt(int64(p.ChainId())))
+	sender, err := types.Sender(signer, ethTx)
+	if err != nil {
+		return "", eth.NewInvalidParamsError(fmt.Sprintf("invalid Ethereum transaction signature: %s", err))
+	}
+
+	key := p.Accounts.FindByEthereumAddress(sender)
+	if key == nil {
+		return "", eth.NewInvalidParamsError(fmt.Sprintf("Ethereum transactions can only be sent from accounts passed to Janus, %s isn't one of them: sign a Qtum transaction instead", sender.Hex()))
+	}
+	from := (&qtum.Account{WIF: key}).ToHexAddress()
+
+	// the nonce is checked and the transaction sent before the next one of the account is built
+	defer senders.lock(from)()
+
+	if p.ethTransactions != nil {
+		if txid, ok := p.ethTransactions.QtumTransaction(ethTx.Hash()); ok {
+			// the same signed transaction was already sent
+			return eth.SendRawTransactionResponse(utils.AddHexPrefix(txid)), nil
+		}
+	}
+
+	// Qtum transactions have no nonce, the one of the Ethereum transaction must be the number of transactions
+	// the account sent, as eth_getTransactionCount returns it, so that a signed transaction can't be sent twice
+	address, err := convertETHAddress(from, p.Chain())
+	if err != nil {
+		return "", eth.NewCallbackError(err.Error())
+	}
+	count, err := p.GetTransactionCount(ctx, address, nil, true)
+	if err != nil {
+		return "", eth.NewCallbackError(err.Error())
+	}
+	if nonce := ethTx.Nonce(); nonce < count.Uint64() {
+		return "", eth.NewInvalidParamsError(fmt.Sprintf("nonce too low: address %s, tx: %d state: %d", sender.Hex(), nonce, count.Uint64()))
+	} else if nonce > count.Uint64() {
+		return "", eth.NewInvalidParamsError(fmt.Sprintf("nonce too high: address %s, tx: %d state: %d", sender.Hex(), nonce, count.Uint64()))
+	}
+
+	qtumHexedRawTx, jsonErr := p.translateEthereumTransaction(ctx, key, from, ethTx)
+	if jsonErr != nil {
+		return "", jsonErr
+	}
+	resp, jsonErr := p.send(ctx, qtumHexedRawTx)
+	if jsonErr != nil {
+		return "", jsonErr
+	}
+
+	if p.ethTransactions != nil {
+		if err := p.ethTransactions.Put(ethTx.Hash(), string(resp)); err != nil {
+			p.GetErrorLogger().Log("method", p.Method(), "msg", "couldn't record Ethereum transaction", "hash", ethTx.Hash().Hex(), "err", err)
+		}
+	}
+	return resp, nil
+}
+
+func (p *ProxyETHSendRawTransaction) send(ctx context.Context, qtumHexedRawTx string) (eth.SendRawTransactionResponse, eth.JSONRPCError) {
+	req := qtum.SendRawTransactionRequest([1]string{qtumHexedRawTx})
 
 	qtumresp, err := p.Qtum.SendRawTransaction(ctx, &req)
 	if err != nil {
@@ -62,3 +134,46 @@ func (p *ProxyETHSendRawTransaction) request(ctx context.Context, params eth.Sen
 	ethHexedTxHash := utils.AddHexPrefix(resp.Result)
 	return eth.SendRawTransactionResponse(ethHexedTxHash), nil
 }
+
+// decodeEthereumTransaction returns the transaction if the raw transaction is a signed legacy or typed Ethereum transaction, nil if it is a Qtum transaction
+func decodeEthereumTransaction(hexedRawTx string) *types.Transaction {
+	rawTx, err := hex.DecodeString(hexedRawTx)
+	if err != nil {
+		return nil
+	}
+	// Qtum transactions start with their version which can't be decoded as an RLP list, even after a transaction type
+	var tx types.Transaction
+	if err := tx.UnmarshalBinary(rawTx); err != nil {
+		return nil
+	}
+	return &tx
+}
+
+// translateEthereumTransaction builds and signs the Qtum transaction equivalent to an Ethereum transaction signed by one of the keys passed with --accounts
+func (p *ProxyETHSendRawTransaction) translateEthereumTransaction(ctx context.Context, key *btcutil.WIF, from string, ethTx *types.Transaction) (string, eth.JSONRPCError) {
+	p.GetDebugLogger().Log("method", p.Method(), "msg", "translating Ethereum transaction", "hash", ethTx.Hash().Hex(), "from", from)
+
+	req := &eth.SendTransactionRequest{
+		From:  utils.AddHexPrefix(from),
+		Gas:   &eth.ETHInt{Int: new(big.Int).SetUint64(ethTx.Gas())},
+		Value: hexutil.EncodeBig(ethTx.Value()),
+		// Qtum doesn't have a base fee, the most the sender agreed to pay per gas is used as the gas price
+		GasPrice: &eth.ETHInt{Int: ethTx.GasFeeCap()},
+	}
+	if ethTx.To() != nil {
+		req.To = utils.AddHexPrefix(hex.EncodeToString(ethTx.To().Bytes()))
+	}
+	if len(ethTx.Data()) > 0 {
+		req.Data = hexutil.Encode(ethTx.Data())
+	}
+
+	tx, jsonErr := buildLocalTransaction(ctx, p.Qtum, key, req)
+	if jsonErr != nil {
+		return "", jsonErr
+	}
+	rawTx, err := qtum.SerializeTransaction(tx)
+	if err != nil {
+		return "", eth.NewCallbackError(err.Error())
+	}
+	return rawTx, nil
+}
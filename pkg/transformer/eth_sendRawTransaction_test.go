@@ -0,0 +1,333 @@
+package transformer
+
+import (
+	"bytes"
+	"context"
+	"encoding/hex"
+	"encoding/json"
+	"io/ioutil"
+	"math/big"
+	"net/http"
+	"os"
+	"strings"
+	"sync"
+	"testing"
+
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/ethereum/go-ethereum/core/types"
+	"github.com/ethereum/go-ethereum/crypto"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/ethtx"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/shopspring/decimal"
+)
+
+func TestSendRawTransactionEthereumTransaction(t *testing.T) {
+	account, err := btcutil.DecodeWIF("5JK4Gu9nxCvsCxiq9Zf3KdmA9ACza6dUn5BRLVWAYEtQabdnJ89")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// a dynamic fee transaction signed the way ethers or cast would, for the test chain
+	to := common.HexToAddress("0x7e22630f90e6db16283af2c6b04f688117a55db4")
+	ethTx, err := types.SignNewTx(account.PrivKey.ToECDSA(), types.NewLondonSigner(big.NewInt(8889)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(8889),
+		Nonce:     0,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(2e9),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1e18),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawEthTx, err := ethTx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	requestParams := []json.RawMessage{[]byte(`"` + hexutil.Encode(rawEthTx) + `"`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qtumClient.Accounts = append(qtumClient.Accounts, account)
+
+	senderHash, _ := hex.DecodeString("6d358cf96533189dd5a602d0937fddf0888ad3ae")
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressUTXOs, qtum.GetAddressUTXOsResponse{
+		{TXID: "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", OutputIndex: 1, Script: hex.EncodeToString(qtum.PayToPubKeyHashScript(senderHash)), Satoshis: decimal.NewFromInt(2e8), Height: big.NewInt(1000)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressMempool, qtum.GetAddressMempoolResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	// the account didn't send any transaction yet, so the nonce has to be 0
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressDeltas, qtum.GetAddressDeltasResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(2000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodSendRawTx, "11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	dir, err := ioutil.TempDir("", "ethtx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := ethtx.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	proxyEth := ProxyETHSendRawTransaction{Qtum: qtumClient, ethTransactions: store}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr.Error())
+	}
+
+	want := eth.SendRawTransactionResponse("0x11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5")
+
+	internal.CheckTestResultEthRequestRPC(*request, want, got, t, false)
+
+	// the Ethereum hash is recorded, so the transaction can be looked up and sending it again returns the same hash
+	if txid, ok := store.QtumTransaction(ethTx.Hash()); !ok || txid != "11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5" {
+		t.Fatalf("Expected the Ethereum transaction to be recorded, got %q (found: %v)", txid, ok)
+	}
+	err = mockedClientDoer.AddError(qtum.MethodSendRawTx, eth.NewCallbackError("txn-mempool-conflict"))
+	if err != nil {
+		t.Fatal(err)
+	}
+	got, jsonErr = proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr.Error())
+	}
+	internal.CheckTestResultEthRequestRPC(*request, want, got, t, false)
+
+	// a nonce other than the account's transaction count is rejected
+	nextTx, err := types.SignNewTx(account.PrivKey.ToECDSA(), types.NewLondonSigner(big.NewInt(8889)), &types.DynamicFeeTx{
+		ChainID:   big.NewInt(8889),
+		Nonce:     1,
+		GasTipCap: big.NewInt(1e9),
+		GasFeeCap: big.NewInt(2e9),
+		Gas:       21000,
+		To:        &to,
+		Value:     big.NewInt(1e18),
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	rawNextTx, err := nextTx.MarshalBinary()
+	if err != nil {
+		t.Fatal(err)
+	}
+	nextRequest, err := internal.PrepareEthRPCRequest(1, []json.RawMessage{[]byte(`"` + hexutil.Encode(rawNextTx) + `"`)})
+	if err != nil {
+		t.Fatal(err)
+	}
+	if _, jsonErr := proxyEth.Request(nextRequest, internal.NewEchoContext()); jsonErr == nil || !strings.Contains(jsonErr.Message(), "nonce too high") {
+		t.Fatalf("Expected a transaction with a future nonce to be rejected, got %v", jsonErr)
+	}
+
+	// only the keys passed with --accounts can be used
+	qtumClient.Accounts = nil
+	if _, jsonErr := proxyEth.Request(request, internal.NewEchoContext()); jsonErr == nil {
+		t.Fatal("Expected an Ethereum transaction from an unknown sender to be rejected")
+	}
+}
+
+func TestSendRawTransactionEthereumTransactionsInARow(t *testing.T) {
+	account, err := btcutil.DecodeWIF("5JK4Gu9nxCvsCxiq9Zf3KdmA9ACza6dUn5BRLVWAYEtQabdnJ89")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	senderHash, _ := hex.DecodeString("6d358cf96533189dd5a602d0937fddf0888ad3ae")
+	doer := &addressMempoolDoer{
+		Doer:   mockedClientDoer,
+		script: hex.EncodeToString(qtum.PayToPubKeyHashScript(senderHash)),
+		spends: map[string]int64{outpoint("d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", 1): 2e8},
+	}
+	qtumClient, err := internal.CreateMockedClient(doer)
+	if err != nil {
+		t.Fatal(err)
+	}
+	qtumClient.Accounts = append(qtumClient.Accounts, account)
+	doer.address, err = convertETHAddress("6d358cf96533189dd5a602d0937fddf0888ad3ae", qtumClient.Chain())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressUTXOs, qtum.GetAddressUTXOsResponse{
+		{TXID: "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", OutputIndex: 1, Script: doer.script, Satoshis: decimal.NewFromInt(2e8), Height: big.NewInt(1000)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressDeltas, qtum.GetAddressDeltasResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(2000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyTxCount := ProxyETHTxCount{qtumClient}
+	proxySend := ProxyETHSendRawTransaction{Qtum: qtumClient}
+	sender := crypto.PubkeyToAddress(account.PrivKey.ToECDSA().PublicKey)
+	to := common.HexToAddress("0x7e22630f90e6db16283af2c6b04f688117a55db4")
+
+	// each transaction is signed with the nonce eth_getTransactionCount returns for the sender's Ethereum address,
+	// like ethers, viem and cast do
+	for i := 0; i < 2; i++ {
+		count, jsonErr := proxyTxCount.request(context.Background(), &eth.GetTransactionCountRequest{Address: sender.Hex(), Tag: []byte(`"pending"`)})
+		if jsonErr != nil {
+			t.Fatal(jsonErr)
+		}
+		nonce, err := hexutil.DecodeUint64(count.(string))
+		if err != nil {
+			t.Fatal(err)
+		}
+		if nonce != uint64(i) {
+			t.Fatalf("Expected nonce %d for the sender's Ethereum address, got %d", i, nonce)
+		}
+
+		ethTx, err := types.SignNewTx(account.PrivKey.ToECDSA(), types.NewLondonSigner(big.NewInt(8889)), &types.DynamicFeeTx{
+			ChainID:   big.NewInt(8889),
+			Nonce:     nonce,
+			GasTipCap: big.NewInt(1e9),
+			GasFeeCap: big.NewInt(2e9),
+			Gas:       21000,
+			To:        &to,
+			Value:     big.NewInt(1e17),
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+		rawEthTx, err := ethTx.MarshalBinary()
+		if err != nil {
+			t.Fatal(err)
+		}
+		if _, jsonErr := proxySend.request(context.Background(), eth.SendRawTransactionRequest{hexutil.Encode(rawEthTx)}); jsonErr != nil {
+			t.Fatalf("transaction %d: %s", i, jsonErr.Message())
+		}
+	}
+}
+
+// addressMempoolDoer keeps the transactions sent with sendrawtransaction in the mempool of the address they spend from,
+// getaddressmempool is answered for that address only
+type addressMempoolDoer struct {
+	internal.Doer
+	address string
+	script  string
+	// values of the outputs the address can spend, by outpoint
+	spends  map[string]int64
+	mutex   sync.Mutex
+	mempool qtum.GetAddressMempoolResponse
+}
+
+func (d *addressMempoolDoer) Do(request *http.Request) (*http.Response, error) {
+	body, err := ioutil.ReadAll(request.Body)
+	if err != nil {
+		return nil, err
+	}
+	request.Body = ioutil.NopCloser(bytes.NewReader(body))
+
+	var rpcRequest struct {
+		ID     json.RawMessage   `json:"id"`
+		Method string            `json:"method"`
+		Params []json.RawMessage `json:"params"`
+	}
+	if err := json.Unmarshal(body, &rpcRequest); err != nil {
+		return nil, err
+	}
+
+	d.mutex.Lock()
+	defer d.mutex.Unlock()
+	var result interface{}
+	switch rpcRequest.Method {
+	case qtum.MethodGetAddressMempool:
+		var params qtum.GetAddressMempoolRequest
+		if err := json.Unmarshal(rpcRequest.Params[0], &params); err != nil {
+			return nil, err
+		}
+		mempool := qtum.GetAddressMempoolResponse{}
+		if len(params.Addresses) == 1 && params.Addresses[0] == d.address {
+			mempool = d.mempool
+		}
+		result = mempool
+	case qtum.MethodSendRawTx:
+		var rawTx string
+		if err := json.Unmarshal(rpcRequest.Params[0], &rawTx); err != nil {
+			return nil, err
+		}
+		txid, err := d.addToMempool(rawTx)
+		if err != nil {
+			return nil, err
+		}
+		result = txid
+	default:
+		return d.Doer.Do(request)
+	}
+
+	response, err := json.Marshal(map[string]interface{}{"jsonrpc": "2.0", "id": rpcRequest.ID, "result": result})
+	if err != nil {
+		return nil, err
+	}
+	return &http.Response{StatusCode: http.StatusOK, Body: ioutil.NopCloser(bytes.NewReader(response))}, nil
+}
+
+// addToMempool records the outputs a transaction spends and the change it pays to the address
+func (d *addressMempoolDoer) addToMempool(rawTx string) (string, error) {
+	serialized, err := hex.DecodeString(rawTx)
+	if err != nil {
+		return "", err
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(serialized)); err != nil {
+		return "", err
+	}
+	txid := tx.TxHash().String()
+	timestamp := int64(len(d.mempool))
+	for _, in := range tx.TxIn {
+		prevTXID, prevOut := in.PreviousOutPoint.Hash.String(), int64(in.PreviousOutPoint.Index)
+		d.mempool = append(d.mempool, qtum.AddressMempoolDelta{Address: d.address, TXID: txid, Satoshis: -d.spends[outpoint(prevTXID, prevOut)], Timestamp: timestamp, PrevTXID: prevTXID, PrevOut: prevOut})
+	}
+	for i, out := range tx.TxOut {
+		if hex.EncodeToString(out.PkScript) == d.script {
+			d.spends[outpoint(txid, int64(i))] = out.Value
+			d.mempool = append(d.mempool, qtum.AddressMempoolDelta{Address: d.address, TXID: txid, Index: int64(i), Satoshis: out.Value, Timestamp: timestamp})
+		}
+	}
+	return txid, nil
+}
+
+func TestDecodeEthereumTransaction(t *testing.T) {
+	// version 2 Qtum transactions start with the same byte as dynamic fee Ethereum transactions
+	qtumRawTx := "0200000001d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451010000006a47304402200000000000000000000000000000000000000000000000000000000000000001022000000000000000000000000000000000000000000000000000000000000000010121020000000000000000000000000000000000000000000000000000000000000001ffffffff0100e1f505000000001976a9147e22630f90e6db16283af2c6b04f688117a55db488ac00000000"
+	if tx := decodeEthereumTransaction(qtumRawTx); tx != nil {
+		t.Fatalf("Qtum transaction decoded as an Ethereum transaction: %+v", tx)
+	}
+}
@@ -1,6 +1,11 @@
 package transformer
 
 import (
+	"context"
+	"strings"
+	"sync"
+
+	"github.com/btcsuite/btcutil"
 	"github.com/labstack/echo"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
@@ -15,6 +20,33 @@ type ProxyETHSendTransaction struct {
 	*qtum.Qtum
 }
 
+// transactions built by Janus are broadcast one at a time per sender,
+// so that the next one sees the UTXOs spent by the previous one in the mempool
+var senders accountLocks
+
+type accountLocks struct {
+	mutex sync.Mutex
+	locks map[string]*sync.Mutex
+}
+
+// lock waits until no other transaction of the address is being built and broadcast, and returns the function releasing it
+func (l *accountLocks) lock(address string) func() {
+	address = strings.ToLower(utils.RemoveHexPrefix(address))
+	l.mutex.Lock()
+	if l.locks == nil {
+		l.locks = map[string]*sync.Mutex{}
+	}
+	lock, ok := l.locks[address]
+	if !ok {
+		lock = &sync.Mutex{}
+		l.locks[address] = lock
+	}
+	l.mutex.Unlock()
+
+	lock.Lock()
+	return lock.Unlock
+}
+
 func (p *ProxyETHSendTransaction) Method() string {
 	return "eth_sendTransaction"
 }
@@ -31,10 +63,23 @@ func (p *ProxyETHSendTransaction) Request(rawreq *eth.JSONRPCRequest, c echo.Con
 		p.GetLogger().Log("msg", "Gas limit is too low", "gasLimit", req.Gas.String())
 	}
 
+	if req.SendAll && req.To == "" {
+		return nil, eth.NewInvalidParamsError(errSendAllWithoutRecipient)
+	}
+	if _, jsonErr := getCoinSelection(p.Qtum, &req); jsonErr != nil {
+		return nil, jsonErr
+	}
+
 	var result interface{}
 	var jsonErr eth.JSONRPCError
 
-	if req.IsCreateContract() {
+	if key := p.Accounts.FindByHexAddress(strings.ToLower(utils.RemoveHexPrefix(req.From))); key != nil {
+		// sign the transaction ourselves so that qtumd doesn't need a wallet
+		result, jsonErr = p.requestLocallySigned(c.Request().Context(), key, &req)
+	} else if req.SendAll {
+		// qtumd's wallet can't send everything an address can spend, so the transaction is built here and only signed by the wallet
+		result, jsonErr = p.requestSendAllWithWallet(c.Request().Context(), &req)
+	} else if req.IsCreateContract() {
 		result, jsonErr = p.requestCreateContract(&req)
 	} else if req.IsSendEther() {
 		result, jsonErr = p.requestSendToAddress(&req)
@@ -51,6 +96,52 @@ func (p *ProxyETHSendTransaction) Request(rawreq *eth.JSONRPCRequest, c echo.Con
 	return result, jsonErr
 }
 
+func (p *ProxyETHSendTransaction) requestLocallySigned(ctx context.Context, key *btcutil.WIF, req *eth.SendTransactionRequest) (*eth.SendTransactionResponse, eth.JSONRPCError) {
+	defer senders.lock(req.From)()
+
+	tx, jsonErr := buildLocalTransaction(ctx, p.Qtum, key, req)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	rawTx, err := qtum.SerializeTransaction(tx)
+	if err != nil {
+		return nil, eth.NewCallbackError(err.Error())
+	}
+
+	qtumresp, err := p.Qtum.SendRawTransaction(ctx, &qtum.SendRawTransactionRequest{rawTx})
+	if err != nil {
+		return nil, eth.NewCallbackError(err.Error())
+	}
+
+	ethresp := eth.SendTransactionResponse(utils.AddHexPrefix(qtumresp.Result))
+	return &ethresp, nil
+}
+
+func (p *ProxyETHSendTransaction) requestSendAllWithWallet(ctx context.Context, req *eth.SendTransactionRequest) (*eth.SendTransactionResponse, eth.JSONRPCError) {
+	signer := &ProxyETHSignTransaction{p.Qtum}
+	defer senders.lock(req.From)()
+
+	var rawTx string
+	var jsonErr eth.JSONRPCError
+	if req.IsSendEther() {
+		rawTx, jsonErr = signer.requestSendToAddress(ctx, req)
+	} else {
+		rawTx, jsonErr = signer.requestSendToContract(ctx, req)
+	}
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	qtumresp, err := p.Qtum.SendRawTransaction(ctx, &qtum.SendRawTransactionRequest{utils.RemoveHexPrefix(rawTx)})
+	if err != nil {
+		return nil, eth.NewCallbackError(err.Error())
+	}
+
+	ethresp := eth.SendTransactionResponse(utils.AddHexPrefix(qtumresp.Result))
+	return &ethresp, nil
+}
+
 func (p *ProxyETHSendTransaction) requestSendToContract(ethtx *eth.SendTransactionRequest) (*eth.SendTransactionResponse, eth.JSONRPCError) {
 	gasLimit, gasPrice, err := EthGasToQtum(ethtx)
 	if err != nil {
@@ -2,10 +2,14 @@ package transformer
 
 import (
 	"context"
+	"encoding/hex"
 	"fmt"
 	"strings"
 
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
 	"github.com/labstack/echo"
+	"github.com/pkg/errors"
 	"github.com/qtumproject/janus/pkg/eth"
 	"github.com/qtumproject/janus/pkg/qtum"
 	"github.com/qtumproject/janus/pkg/utils"
@@ -28,8 +32,27 @@ func (p *ProxyETHSignTransaction) Request(rawreq *eth.JSONRPCRequest, c echo.Con
 		return nil, eth.NewInvalidParamsError(err.Error())
 	}
 
+	if req.SendAll && req.To == "" {
+		return nil, eth.NewInvalidParamsError(errSendAllWithoutRecipient)
+	}
+	if _, jsonErr := getCoinSelection(p.Qtum, &req); jsonErr != nil {
+		return nil, jsonErr
+	}
+
 	ctx := c.Request().Context()
 
+	if key := p.Accounts.FindByHexAddress(strings.ToLower(utils.RemoveHexPrefix(req.From))); key != nil {
+		tx, jsonErr := buildLocalTransaction(ctx, p.Qtum, key, &req)
+		if jsonErr != nil {
+			return nil, jsonErr
+		}
+		rawTx, err := qtum.SerializeTransaction(tx)
+		if err != nil {
+			return nil, eth.NewCallbackError(err.Error())
+		}
+		return utils.AddHexPrefix(rawTx), nil
+	}
+
 	if req.IsCreateContract() {
 		p.GetDebugLogger().Log("method", p.Method(), "msg", "transaction is a create contract request")
 		return p.requestCreateContract(ctx, &req)
@@ -46,33 +69,223 @@ func (p *ProxyETHSignTransaction) Request(rawreq *eth.JSONRPCRequest, c echo.Con
 	return nil, eth.NewInvalidParamsError("Unknown operation")
 }
 
-func (p *ProxyETHSignTransaction) getRequiredUtxos(ctx context.Context, from string, neededAmount decimal.Decimal) ([]qtum.RawTxInputs, decimal.Decimal, error) {
-	//convert address to qtum address
-	addr := utils.RemoveHexPrefix(from)
-	base58Addr, err := p.FromHexAddress(addr)
+func (p *ProxyETHSignTransaction) getRequiredUtxos(ctx context.Context, req *eth.SendTransactionRequest, neededAmount decimal.Decimal) ([]qtum.RawTxInputs, decimal.Decimal, error) {
+	coinSelection, jsonErr := getCoinSelection(p.Qtum, req)
+	if jsonErr != nil {
+		return nil, decimal.Decimal{}, errors.New(jsonErr.Message())
+	}
+
+	utxos, err := getSpendableUtxos(ctx, p.Qtum, utils.RemoveHexPrefix(req.From))
 	if err != nil {
 		return nil, decimal.Decimal{}, err
 	}
-	// need to get utxos with txid and vouts. In order to do this we get a list of unspent transactions and begin summing them up
-	var getaddressutxos *qtum.GetAddressUTXOsRequest = &qtum.GetAddressUTXOsRequest{Addresses: []string{base58Addr}}
-	qtumresp, err := p.GetAddressUTXOs(ctx, getaddressutxos)
+
+	// qtumd's wallet is left to deal with the fee for the transaction's size
+	selected, err := coinSelection(utxos, qtum.CoinSelectionTarget{Amount: convertFromQtumToSatoshis(neededAmount).IntPart()})
 	if err != nil {
 		return nil, decimal.Decimal{}, err
 	}
 
-	//Convert minSumAmount to Satoshis
-	minimumSum := convertFromQtumToSatoshis(neededAmount)
-	var utxos []qtum.RawTxInputs
+	var inputs []qtum.RawTxInputs
 	var minUTXOsSum decimal.Decimal
-	for _, utxo := range *qtumresp {
+	for _, utxo := range selected {
 		minUTXOsSum = minUTXOsSum.Add(utxo.Satoshis)
-		utxos = append(utxos, qtum.RawTxInputs{TxID: utxo.TXID, Vout: utxo.OutputIndex})
-		if minUTXOsSum.GreaterThanOrEqual(minimumSum) {
-			return utxos, minUTXOsSum, nil
+		inputs = append(inputs, qtum.RawTxInputs{TxID: utxo.TXID, Vout: utxo.OutputIndex})
+	}
+
+	return inputs, convertFromSatoshisToQtum(minUTXOsSum), nil
+}
+
+// getCoinSelection returns the coin selection strategy the request asks for, or the one configured with --coin-selection
+func getCoinSelection(q *qtum.Qtum, req *eth.SendTransactionRequest) (qtum.CoinSelector, eth.JSONRPCError) {
+	name := req.CoinSelection
+	if name == "" {
+		name = q.GetCoinSelection()
+	}
+	coinSelection, ok := qtum.CoinSelectors[name]
+	if !ok {
+		return nil, eth.NewInvalidParamsError(fmt.Sprintf("Unknown coin selection strategy: %s", name))
+	}
+	return coinSelection, nil
+}
+
+// buildLocalTransaction builds and signs a transaction with one of the keys passed with --accounts, without going through qtumd's wallet
+func buildLocalTransaction(ctx context.Context, q *qtum.Qtum, key *btcutil.WIF, req *eth.SendTransactionRequest) (*wire.MsgTx, eth.JSONRPCError) {
+	output := &wire.TxOut{}
+	var gasFee int64
+
+	if req.IsCreateContract() || req.IsCallContract() {
+		gasLimit, gasPrice, err := EthGasToQtum(req)
+		if err != nil {
+			return nil, eth.NewInvalidParamsError(err.Error())
+		}
+		gasPriceDecimal, err := decimal.NewFromString(gasPrice)
+		if err != nil {
+			return nil, eth.NewInvalidParamsError(err.Error())
+		}
+		gasPriceSatoshis := convertFromQtumToSatoshis(gasPriceDecimal).IntPart()
+		gasFee = gasLimit.Int64() * gasPriceSatoshis
+
+		data, err := hex.DecodeString(utils.RemoveHexPrefix(req.Data))
+		if err != nil {
+			return nil, eth.NewInvalidParamsError(fmt.Sprintf("Invalid data: %s", err))
+		}
+
+		if req.IsCreateContract() {
+			output.PkScript = qtum.ContractCreateScript(gasLimit, gasPriceSatoshis, data)
+		} else {
+			contract, err := hex.DecodeString(utils.RemoveHexPrefix(req.To))
+			if err != nil || len(contract) != 20 {
+				return nil, eth.NewInvalidParamsError(fmt.Sprintf("Invalid contract address: %s", req.To))
+			}
+			output.PkScript = qtum.ContractCallScript(gasLimit, gasPriceSatoshis, data, contract)
+		}
+	} else if req.IsSendEther() {
+		if utils.IsEthHexAddress(req.To) {
+			pubKeyHash, err := hex.DecodeString(utils.RemoveHexPrefix(req.To))
+			if err != nil {
+				return nil, eth.NewInvalidParamsError(err.Error())
+			}
+			output.PkScript = qtum.PayToPubKeyHashScript(pubKeyHash)
+		} else {
+			script, err := qtum.PayToAddressScript(req.To)
+			if err != nil {
+				return nil, eth.NewInvalidParamsError(err.Error())
+			}
+			output.PkScript = script
+		}
+	} else {
+		return nil, eth.NewInvalidParamsError("Unknown operation")
+	}
+
+	// coins sent with the creation of a contract are lost, so they aren't sent
+	if !req.IsCreateContract() && !req.SendAll {
+		amount, err := EthValueToQtumAmount(req.Value, ZeroSatoshi)
+		if err != nil {
+			return nil, eth.NewInvalidParamsError(err.Error())
 		}
+		output.Value = convertFromQtumToSatoshis(amount).IntPart()
+	}
+
+	coinSelection, jsonErr := getCoinSelection(q, req)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	utxos, err := getSpendableUtxos(ctx, q, utils.RemoveHexPrefix(req.From))
+	if err != nil {
+		return nil, eth.NewCallbackError(err.Error())
+	}
+
+	tx, err := qtum.BuildLocalTransaction(&qtum.LocalTransactionRequest{
+		Key:           key,
+		UTXOs:         utxos,
+		CoinSelection: coinSelection,
+		Outputs:       []*wire.TxOut{output},
+		GasFee:        gasFee,
+		FeeRate:       qtum.DefaultFeeRate,
+		SendAll:       req.SendAll,
+	})
+	if err != nil {
+		return nil, eth.NewCallbackError(err.Error())
 	}
 
-	return nil, decimal.Decimal{}, fmt.Errorf("Insufficient UTXO value attempted to be sent")
+	return tx, nil
+}
+
+// getSpendableUtxos returns the UTXOs of an address that aren't spent by a transaction in the mempool,
+// including the change of its unconfirmed transactions, and leaving out immature coinbase and coinstake outputs
+func getSpendableUtxos(ctx context.Context, q *qtum.Qtum, hexAddress string) ([]qtum.UTXO, error) {
+	address, err := convertETHAddress(hexAddress, q.Chain())
+	if err != nil {
+		return nil, err
+	}
+
+	resp, err := q.GetAddressUTXOs(ctx, &qtum.GetAddressUTXOsRequest{Addresses: []string{address}})
+	if err != nil {
+		return nil, err
+	}
+
+	mempool, err := q.GetAddressMempool(ctx, &qtum.GetAddressMempoolRequest{Addresses: []string{address}})
+	if err != nil {
+		return nil, err
+	}
+	spent := map[string]bool{}
+	sending := map[string]bool{}
+	for _, delta := range mempool {
+		if delta.Satoshis < 0 {
+			spent[outpoint(delta.PrevTXID, delta.PrevOut)] = true
+			sending[delta.TXID] = true
+		}
+	}
+
+	blockCount, err := q.GetBlockCount(ctx)
+	if err != nil {
+		return nil, err
+	}
+	matureBlockHeight := int64(q.GetMatureBlockHeight())
+
+	utxos := make([]qtum.UTXO, 0, len(*resp))
+	for _, utxo := range *resp {
+		if utxo.IsStake && blockCount.Int64() <= utxo.Height.Int64()+matureBlockHeight {
+			continue
+		}
+		if spent[outpoint(utxo.TXID, int64(utxo.OutputIndex))] {
+			continue
+		}
+		utxos = append(utxos, utxo)
+	}
+
+	// only the outputs of transactions the address sent are used, those are its change which always pays to its public key hash
+	pubKeyHash, err := hex.DecodeString(utils.RemoveHexPrefix(hexAddress))
+	if err != nil {
+		return nil, err
+	}
+	script := hex.EncodeToString(qtum.PayToPubKeyHashScript(pubKeyHash))
+	for _, delta := range mempool {
+		if delta.Satoshis <= 0 || !sending[delta.TXID] || spent[outpoint(delta.TXID, delta.Index)] {
+			continue
+		}
+		utxos = append(utxos, qtum.UTXO{
+			Address:     address,
+			TXID:        delta.TXID,
+			OutputIndex: uint(delta.Index),
+			Script:      script,
+			Satoshis:    decimal.NewFromInt(delta.Satoshis),
+		})
+	}
+
+	return utxos, nil
+}
+
+func outpoint(txid string, index int64) string {
+	return fmt.Sprintf("%s:%d", txid, index)
+}
+
+// coins sent with the creation of a contract are lost
+const errSendAllWithoutRecipient = "sendAll needs a recipient, it can't be used to create a contract"
+
+// getSendAllUtxos returns every spendable UTXO of an address, and the amount left to send with the output once the transaction spending them pays its fees
+func (p *ProxyETHSignTransaction) getSendAllUtxos(ctx context.Context, from string, outputScript []byte, gasFee int64) ([]qtum.RawTxInputs, decimal.Decimal, error) {
+	utxos, err := getSpendableUtxos(ctx, p.Qtum, utils.RemoveHexPrefix(from))
+	if err != nil {
+		return nil, decimal.Decimal{}, err
+	}
+
+	inputs := make([]qtum.RawTxInputs, 0, len(utxos))
+	balance := decimal.Zero
+	for _, utxo := range utxos {
+		inputs = append(inputs, qtum.RawTxInputs{TxID: utxo.TXID, Vout: utxo.OutputIndex})
+		balance = balance.Add(utxo.Satoshis)
+	}
+
+	fee := qtum.EstimateFee(len(inputs), []*wire.TxOut{wire.NewTxOut(0, outputScript)}, qtum.DefaultFeeRate) + gasFee
+	amount := balance.Sub(decimal.NewFromInt(fee))
+	if !amount.IsPositive() {
+		return nil, decimal.Decimal{}, qtum.ErrInsufficientFunds
+	}
+
+	return inputs, convertFromSatoshisToQtum(amount), nil
 }
 
 func calculateChange(balance, neededAmount decimal.Decimal) (decimal.Decimal, error) {
@@ -105,16 +318,38 @@ func (p *ProxyETHSignTransaction) requestSendToContract(ctx context.Context, eth
 	if err != nil {
 		return "", eth.NewInvalidParamsError(err.Error())
 	}
-	neededAmount := calculateNeededAmount(amount, decimal.NewFromBigInt(gasLimit, 0), newGasPrice)
 
-	inputs, balance, err := p.getRequiredUtxos(ctx, ethtx.From, neededAmount)
-	if err != nil {
-		return "", eth.NewCallbackError(err.Error())
-	}
+	var inputs []qtum.RawTxInputs
+	var change decimal.Decimal
+	if ethtx.SendAll {
+		contract, err := hex.DecodeString(utils.RemoveHexPrefix(ethtx.To))
+		if err != nil {
+			return "", eth.NewInvalidParamsError(err.Error())
+		}
+		data, err := hex.DecodeString(utils.RemoveHexPrefix(ethtx.Data))
+		if err != nil {
+			return "", eth.NewInvalidParamsError(err.Error())
+		}
+		gasPriceSatoshis := convertFromQtumToSatoshis(newGasPrice).IntPart()
+		script := qtum.ContractCallScript(gasLimit, gasPriceSatoshis, data, contract)
 
-	change, err := calculateChange(balance, neededAmount)
-	if err != nil {
-		return "", eth.NewCallbackError(err.Error())
+		inputs, amount, err = p.getSendAllUtxos(ctx, ethtx.From, script, gasLimit.Int64()*gasPriceSatoshis)
+		if err != nil {
+			return "", eth.NewCallbackError(err.Error())
+		}
+	} else {
+		neededAmount := calculateNeededAmount(amount, decimal.NewFromBigInt(gasLimit, 0), newGasPrice)
+
+		var balance decimal.Decimal
+		inputs, balance, err = p.getRequiredUtxos(ctx, ethtx, neededAmount)
+		if err != nil {
+			return "", eth.NewCallbackError(err.Error())
+		}
+
+		change, err = calculateChange(balance, neededAmount)
+		if err != nil {
+			return "", eth.NewCallbackError(err.Error())
+		}
 	}
 
 	contractInteractTx := &qtum.SendToContractRawRequest{
@@ -133,14 +368,11 @@ func (p *ProxyETHSignTransaction) requestSendToContract(ctx context.Context, eth
 		contractInteractTx.SenderAddress = from
 	}
 
-	fromAddr := utils.RemoveHexPrefix(ethtx.From)
-
-	acc := p.Qtum.Accounts.FindByHexAddress(strings.ToLower(fromAddr))
-	if acc == nil {
-		return "", eth.NewInvalidParamsError(fmt.Sprintf("No such account: %s", fromAddr))
+	outputs := []interface{}{map[string]*qtum.SendToContractRawRequest{"contract": contractInteractTx}}
+	if !ethtx.SendAll {
+		outputs = append(outputs, map[string]decimal.Decimal{contractInteractTx.SenderAddress: change})
 	}
-
-	rawtxreq := []interface{}{inputs, []interface{}{map[string]*qtum.SendToContractRawRequest{"contract": contractInteractTx}, map[string]decimal.Decimal{contractInteractTx.SenderAddress: change}}}
+	rawtxreq := []interface{}{inputs, outputs}
 	var rawTx string
 	if err := p.Qtum.Request(qtum.MethodCreateRawTx, rawtxreq, &rawTx); err != nil {
 		return "", eth.NewCallbackError(err.Error())
@@ -174,22 +406,39 @@ func (p *ProxyETHSignTransaction) requestSendToAddress(ctx context.Context, req
 		return "", eth.NewCallbackError(err.Error())
 	}
 
-	amount, err := EthValueToQtumAmount(req.Value, ZeroSatoshi)
-	if err != nil {
-		return "", eth.NewInvalidParamsError(err.Error())
-	}
+	var addressValMap map[string]decimal.Decimal
+	var inputs []qtum.RawTxInputs
+	if req.SendAll {
+		script, err := qtum.PayToAddressScript(to)
+		if err != nil {
+			return "", eth.NewInvalidParamsError(err.Error())
+		}
 
-	inputs, balance, err := p.getRequiredUtxos(ctx, req.From, amount)
-	if err != nil {
-		return "", eth.NewCallbackError(err.Error())
-	}
+		var amount decimal.Decimal
+		inputs, amount, err = p.getSendAllUtxos(ctx, req.From, script, 0)
+		if err != nil {
+			return "", eth.NewCallbackError(err.Error())
+		}
+		addressValMap = map[string]decimal.Decimal{to: amount}
+	} else {
+		amount, err := EthValueToQtumAmount(req.Value, ZeroSatoshi)
+		if err != nil {
+			return "", eth.NewInvalidParamsError(err.Error())
+		}
 
-	change, err := calculateChange(balance, amount)
-	if err != nil {
-		return "", eth.NewCallbackError(err.Error())
+		var balance decimal.Decimal
+		inputs, balance, err = p.getRequiredUtxos(ctx, req, amount)
+		if err != nil {
+			return "", eth.NewCallbackError(err.Error())
+		}
+
+		change, err := calculateChange(balance, amount)
+		if err != nil {
+			return "", eth.NewCallbackError(err.Error())
+		}
+		addressValMap = map[string]decimal.Decimal{to: amount, from: change}
 	}
 
-	var addressValMap = map[string]decimal.Decimal{to: amount, from: change}
 	rawtxreq := []interface{}{inputs, addressValMap}
 	var rawTx string
 	if err := p.Qtum.Request(qtum.MethodCreateRawTx, rawtxreq, &rawTx); err != nil {
@@ -234,7 +483,7 @@ func (p *ProxyETHSignTransaction) requestCreateContract(ctx context.Context, req
 	}
 	neededAmount := calculateNeededAmount(decimal.NewFromFloat(0.0), decimal.NewFromBigInt(gasLimit, 0), newGasPrice)
 
-	inputs, balance, err := p.getRequiredUtxos(ctx, req.From, neededAmount)
+	inputs, balance, err := p.getRequiredUtxos(ctx, req, neededAmount)
 	if err != nil {
 		return "", eth.NewCallbackError(err.Error())
 	}
@@ -0,0 +1,169 @@
+package transformer
+
+import (
+	"bytes"
+	"encoding/hex"
+	"encoding/json"
+	"math/big"
+	"testing"
+
+	"github.com/btcsuite/btcd/txscript"
+	"github.com/btcsuite/btcd/wire"
+	"github.com/btcsuite/btcutil"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+	"github.com/shopspring/decimal"
+)
+
+func TestSignTransactionLocally(t *testing.T) {
+	// the qtumd wallet isn't used when the sender's key was passed with --accounts
+	requestParams := []json.RawMessage{[]byte(`{
+		"from": "0x6d358cf96533189dd5a602d0937fddf0888ad3ae",
+		"to": "0x7e22630f90e6db16283af2c6b04f688117a55db4",
+		"value": "0xde0b6b3a7640000"
+	}`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := btcutil.DecodeWIF("5JK4Gu9nxCvsCxiq9Zf3KdmA9ACza6dUn5BRLVWAYEtQabdnJ89")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qtumClient.Accounts = append(qtumClient.Accounts, account)
+
+	senderHash, _ := hex.DecodeString("6d358cf96533189dd5a602d0937fddf0888ad3ae")
+	senderScript := qtum.PayToPubKeyHashScript(senderHash)
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressUTXOs, qtum.GetAddressUTXOsResponse{
+		// immature coinbase output
+		{TXID: "11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5", Script: hex.EncodeToString(senderScript), Satoshis: decimal.NewFromInt(20e8), Height: big.NewInt(1990), IsStake: true},
+		{TXID: "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451", OutputIndex: 1, Script: hex.EncodeToString(senderScript), Satoshis: decimal.NewFromInt(2e8), Height: big.NewInt(1000)},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressMempool, qtum.GetAddressMempoolResponse{})
+	if err != nil {
+		t.Fatal(err)
+	}
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(2000)})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	proxyEth := ProxyETHSignTransaction{qtumClient}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr.Error())
+	}
+
+	rawTx, err := hex.DecodeString(got.(string)[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tx.TxIn) != 1 || tx.TxIn[0].PreviousOutPoint.Hash.String() != "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451" {
+		t.Fatalf("Expected only the mature UTXO to be spent, got %+v", tx.TxIn)
+	}
+	receiverHash, _ := hex.DecodeString("7e22630f90e6db16283af2c6b04f688117a55db4")
+	if len(tx.TxOut) != 2 || tx.TxOut[0].Value != 1e8 || !bytes.Equal(tx.TxOut[0].PkScript, qtum.PayToPubKeyHashScript(receiverHash)) {
+		t.Fatalf("Expected 1 QTUM to be sent to the receiver, got %+v", tx.TxOut)
+	}
+	if !bytes.Equal(tx.TxOut[1].PkScript, senderScript) {
+		t.Fatalf("Expected change to be sent back to the sender, got %+v", tx.TxOut[1])
+	}
+
+	engine, err := txscript.NewEngine(senderScript, &tx, 0, txscript.StandardVerifyFlags, nil, nil, 2e8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Execute(); err != nil {
+		t.Fatalf("Invalid signature: %s", err)
+	}
+}
+
+func TestSignTransactionLocallyAfterUnconfirmedTransaction(t *testing.T) {
+	// the confirmed UTXO was spent by a transaction still in the mempool, so its change is spent instead
+	requestParams := []json.RawMessage{[]byte(`{
+		"from": "0x6d358cf96533189dd5a602d0937fddf0888ad3ae",
+		"to": "0x7e22630f90e6db16283af2c6b04f688117a55db4",
+		"value": "0xde0b6b3a7640000"
+	}`)}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	account, err := btcutil.DecodeWIF("5JK4Gu9nxCvsCxiq9Zf3KdmA9ACza6dUn5BRLVWAYEtQabdnJ89")
+	if err != nil {
+		t.Fatal(err)
+	}
+	qtumClient.Accounts = append(qtumClient.Accounts, account)
+
+	senderHash, _ := hex.DecodeString("6d358cf96533189dd5a602d0937fddf0888ad3ae")
+	senderScript := qtum.PayToPubKeyHashScript(senderHash)
+	confirmedTxID := "d20c5c31536e60decf175caf2cbfba980c3678c0f4b201c9b9fa1440102e6451"
+	unconfirmedTxID := "11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5"
+	responses := []struct {
+		method   string
+		response interface{}
+	}{
+		{qtum.MethodGetAddressUTXOs, qtum.GetAddressUTXOsResponse{
+			{TXID: confirmedTxID, OutputIndex: 1, Script: hex.EncodeToString(senderScript), Satoshis: decimal.NewFromInt(5e8), Height: big.NewInt(1000)},
+		}},
+		{qtum.MethodGetAddressMempool, qtum.GetAddressMempoolResponse{
+			{TXID: unconfirmedTxID, Index: 0, Satoshis: -5e8, PrevTXID: confirmedTxID, PrevOut: 1},
+			{TXID: unconfirmedTxID, Index: 1, Satoshis: 3e8},
+		}},
+		{qtum.MethodGetBlockCount, qtum.GetBlockCountResponse{Int: big.NewInt(2000)}},
+	}
+	for _, r := range responses {
+		if err := mockedClientDoer.AddResponse(r.method, r.response); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	proxyEth := ProxyETHSignTransaction{qtumClient}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr.Error())
+	}
+
+	rawTx, err := hex.DecodeString(got.(string)[2:])
+	if err != nil {
+		t.Fatal(err)
+	}
+	var tx wire.MsgTx
+	if err := tx.Deserialize(bytes.NewReader(rawTx)); err != nil {
+		t.Fatal(err)
+	}
+
+	if len(tx.TxIn) != 1 || tx.TxIn[0].PreviousOutPoint.Hash.String() != unconfirmedTxID || tx.TxIn[0].PreviousOutPoint.Index != 1 {
+		t.Fatalf("Expected the unconfirmed change to be spent, got %+v", tx.TxIn)
+	}
+
+	engine, err := txscript.NewEngine(senderScript, &tx, 0, txscript.StandardVerifyFlags, nil, nil, 3e8)
+	if err != nil {
+		t.Fatal(err)
+	}
+	if err := engine.Execute(); err != nil {
+		t.Fatalf("Invalid signature: %s", err)
+	}
+}
@@ -0,0 +1,53 @@
+package transformer
+
+import (
+	"context"
+	"sync"
+
+	"github.com/ethereum/go-ethereum/common/hexutil"
+	"github.com/labstack/echo"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+// ProxyETHSyncing implements ETHProxy
+type ProxyETHSyncing struct {
+	*qtum.Qtum
+	mutex sync.Mutex
+	// block the node was at when it was first seen syncing
+	startingBlock *int64
+}
+
+func (p *ProxyETHSyncing) Method() string {
+	return "eth_syncing"
+}
+
+func (p *ProxyETHSyncing) Request(_ *eth.JSONRPCRequest, c echo.Context) (interface{}, eth.JSONRPCError) {
+	return p.request(c.Request().Context())
+}
+
+func (p *ProxyETHSyncing) request(ctx context.Context) (interface{}, eth.JSONRPCError) {
+	blockchainInfo, err := p.GetBlockChainInfo(ctx)
+	if err != nil {
+		return nil, eth.NewCallbackError(err.Error())
+	}
+
+	p.mutex.Lock()
+	defer p.mutex.Unlock()
+
+	if !blockchainInfo.IsSyncing() {
+		p.startingBlock = nil
+		return false, nil
+	}
+
+	if p.startingBlock == nil {
+		startingBlock := blockchainInfo.Blocks
+		p.startingBlock = &startingBlock
+	}
+
+	return &eth.SyncingResponse{
+		StartingBlock: hexutil.EncodeUint64(uint64(*p.startingBlock)),
+		CurrentBlock:  hexutil.EncodeUint64(uint64(blockchainInfo.Blocks)),
+		HighestBlock:  hexutil.EncodeUint64(uint64(blockchainInfo.Headers)),
+	}, nil
+}
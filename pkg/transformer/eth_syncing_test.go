@@ -0,0 +1,91 @@
+package transformer
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/internal"
+	"github.com/qtumproject/janus/pkg/qtum"
+)
+
+func TestSyncingRequestSynced(t *testing.T) {
+	//preparing request
+	requestParams := []json.RawMessage{}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//preparing client response
+	err = mockedClientDoer.AddResponse(qtum.MethodGetBlockChainInfo, qtum.GetBlockChainInfoResponse{
+		Blocks:               1000,
+		Headers:              1000,
+		Verificationprogress: 0.99999,
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//preparing proxy & executing request
+	proxyEth := ProxyETHSyncing{Qtum: qtumClient}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	internal.CheckTestResultEthRequestRPC(*request, false, got, t, false)
+}
+
+func TestSyncingRequestSyncing(t *testing.T) {
+	//preparing request
+	requestParams := []json.RawMessage{}
+	request, err := internal.PrepareEthRPCRequest(1, requestParams)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	//preparing client responses
+	for _, blocks := range []int64{1000, 1500} {
+		err = mockedClientDoer.AddResponse(qtum.MethodGetBlockChainInfo, qtum.GetBlockChainInfoResponse{
+			Blocks:               blocks,
+			Headers:              2000,
+			InitialBlockDownload: true,
+			Verificationprogress: 0.5,
+		})
+		if err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	//preparing proxy & executing requests
+	proxyEth := ProxyETHSyncing{Qtum: qtumClient}
+	if _, jsonErr := proxyEth.Request(request, internal.NewEchoContext()); jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+	got, jsonErr := proxyEth.Request(request, internal.NewEchoContext())
+	if jsonErr != nil {
+		t.Fatal(jsonErr)
+	}
+
+	// the starting block stays the one the node was first seen syncing from
+	want := &eth.SyncingResponse{
+		StartingBlock: "0x3e8",
+		CurrentBlock:  "0x5dc",
+		HighestBlock:  "0x7d0",
+	}
+
+	internal.CheckTestResultEthRequestRPC(*request, want, got, t, false)
+}
@@ -11,6 +11,12 @@ import (
 type ETHProxyInitializer = func(*qtum.Qtum) ETHProxy
 
 func testETHProxyRequest(t *testing.T, initializer ETHProxyInitializer, requestParams []json.RawMessage, want interface{}) {
+	testETHProxyRequestWithSetup(t, initializer, requestParams, want, internal.SetupGetBlockByHashResponses)
+}
+
+func testETHProxyRequestWithSetup(t *testing.T, initializer ETHProxyInitializer, requestParams []json.RawMessage, want interface{}, setup func(*testing.T, internal.Doer)) {
+	resetCaches()
+
 	request, err := internal.PrepareEthRPCRequest(1, requestParams)
 	if err != nil {
 		t.Fatal(err)
@@ -19,7 +25,7 @@ func testETHProxyRequest(t *testing.T, initializer ETHProxyInitializer, requestP
 	mockedClientDoer := internal.NewDoerMappedMock()
 	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
 
-	internal.SetupGetBlockByHashResponses(t, mockedClientDoer)
+	setup(t, mockedClientDoer)
 
 	//preparing proxy & executing request
 	proxyEth := initializer(qtumClient)
@@ -30,3 +36,11 @@ func testETHProxyRequest(t *testing.T, initializer ETHProxyInitializer, requestP
 
 	internal.CheckTestResultEthRequestRPC(*request, want, got, t, false)
 }
+
+// resetCaches empties the caches of mined block data, tests mock different data for the same blocks
+func resetCaches() {
+	sentTransactions.reset()
+	minedBlockDetails.reset()
+	minedBlockReceipts.reset()
+	minedBlockFees.reset()
+}
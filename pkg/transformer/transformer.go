@@ -1,10 +1,16 @@
 package transformer
 
 import (
+	"context"
+
 	"github.com/go-kit/kit/log"
 	"github.com/labstack/echo"
 	"github.com/pkg/errors"
+	"github.com/qtumproject/janus/pkg/blockhash"
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/ethtx"
+	"github.com/qtumproject/janus/pkg/gasprice"
+	"github.com/qtumproject/janus/pkg/logindex"
 	"github.com/qtumproject/janus/pkg/notifier"
 	"github.com/qtumproject/janus/pkg/qtum"
 )
@@ -14,6 +20,10 @@ type Transformer struct {
 	debugMode    bool
 	logger       log.Logger
 	transformers map[string]ETHProxy
+	// when set, Ethereum block hashes are returned instead of Qtum block hashes
+	blockHashes *blockhash.Store
+	// when set, the Ethereum transactions accepted by eth_sendRawTransaction can be looked up by their hash
+	ethTransactions *ethtx.Store
 }
 
 // New creates a new Transformer
@@ -65,11 +75,26 @@ func (t *Transformer) Transform(req *eth.JSONRPCRequest, c echo.Context) (interf
 	if err != nil {
 		return nil, err
 	}
-	resp, err := proxy.Request(req, c)
+	if t.ethTransactions != nil {
+		req = translateRequestTransactionHashes(t.ethTransactions, req)
+	}
+	if t.blockHashes == nil {
+		resp, err := proxy.Request(req, c)
+		if err != nil {
+			return nil, err
+		}
+		return resp, nil
+	}
+
+	ctx := context.Background()
+	if c != nil {
+		ctx = c.Request().Context()
+	}
+	resp, err := proxy.Request(translateRequestBlockHashes(t.blockHashes.Translate(ctx), req), c)
 	if err != nil {
 		return nil, err
 	}
-	return resp, nil
+	return translateResultBlockHashes(t.blockHashes.Translate(ctx), resp), nil
 }
 
 func (t *Transformer) getProxy(method string) (ETHProxy, eth.JSONRPCError) {
@@ -85,9 +110,11 @@ func (t *Transformer) IsDebugEnabled() bool {
 }
 
 // DefaultProxies are the default proxy methods made available
-func DefaultProxies(qtumRPCClient *qtum.Qtum, agent *notifier.Agent) []ETHProxy {
+//
+// logIndex is optional, when set logs are looked up in it instead of using qtumd's searchlogs
+func DefaultProxies(qtumRPCClient *qtum.Qtum, agent *notifier.Agent, logIndex *logindex.Index, gasPriceOracle *gasprice.Oracle, ethTransactions *ethtx.Store) []ETHProxy {
 	filter := eth.NewFilterSimulator()
-	getFilterChanges := &ProxyETHGetFilterChanges{Qtum: qtumRPCClient, filter: filter}
+	getFilterChanges := &ProxyETHGetFilterChanges{Qtum: qtumRPCClient, filter: filter, logIndex: logIndex, agent: agent}
 	ethCall := &ProxyETHCall{Qtum: qtumRPCClient}
 
 	ethProxies := []ETHProxy{
@@ -96,24 +123,28 @@ func DefaultProxies(qtumRPCClient *qtum.Qtum, agent *notifier.Agent) []ETHProxy
 		&ProxyETHPersonalUnlockAccount{},
 		&ProxyETHChainId{Qtum: qtumRPCClient},
 		&ProxyETHBlockNumber{Qtum: qtumRPCClient},
+		&ProxyETHSyncing{Qtum: qtumRPCClient},
 		&ProxyETHHashrate{Qtum: qtumRPCClient},
 		&ProxyETHMining{Qtum: qtumRPCClient},
 		&ProxyETHNetVersion{Qtum: qtumRPCClient},
 		&ProxyETHGetTransactionByHash{Qtum: qtumRPCClient},
 		&ProxyETHGetTransactionByBlockNumberAndIndex{Qtum: qtumRPCClient},
-		&ProxyETHGetLogs{Qtum: qtumRPCClient},
+		&ProxyETHGetLogs{Qtum: qtumRPCClient, logIndex: logIndex},
 		&ProxyETHGetTransactionReceipt{Qtum: qtumRPCClient},
+		&ProxyETHGetBlockReceipts{Qtum: qtumRPCClient},
 		&ProxyETHSendTransaction{Qtum: qtumRPCClient},
 		&ProxyETHAccounts{Qtum: qtumRPCClient},
 		&ProxyETHGetCode{Qtum: qtumRPCClient},
 
 		&ProxyETHNewFilter{Qtum: qtumRPCClient, filter: filter},
 		&ProxyETHNewBlockFilter{Qtum: qtumRPCClient, filter: filter},
+		&ProxyETHNewPendingTransactionFilter{Qtum: qtumRPCClient, filter: filter, agent: agent},
 		getFilterChanges,
 		&ProxyETHGetFilterLogs{ProxyETHGetFilterChanges: getFilterChanges},
 		&ProxyETHUninstallFilter{Qtum: qtumRPCClient, filter: filter},
 
 		&ProxyETHEstimateGas{ProxyETHCall: ethCall},
+		&ProxyETHCreateAccessList{ProxyETHCall: ethCall},
 		&ProxyETHGetBlockByNumber{Qtum: qtumRPCClient},
 		&ProxyETHGetBlockByHash{Qtum: qtumRPCClient},
 		&ProxyETHGetBalance{Qtum: qtumRPCClient},
@@ -126,10 +157,12 @@ func DefaultProxies(qtumRPCClient *qtum.Qtum, agent *notifier.Agent) []ETHProxy
 		&Web3ClientVersion{},
 		&Web3Sha3{},
 		&ProxyETHSign{Qtum: qtumRPCClient},
-		&ProxyETHGasPrice{Qtum: qtumRPCClient},
+		&ProxyETHGasPrice{Qtum: qtumRPCClient, oracle: gasPriceOracle},
+		&ProxyETHFeeHistory{Qtum: qtumRPCClient},
+		&ProxyETHMaxPriorityFeePerGas{Qtum: qtumRPCClient, oracle: gasPriceOracle},
 		&ProxyETHTxCount{Qtum: qtumRPCClient},
 		&ProxyETHSignTransaction{Qtum: qtumRPCClient},
-		&ProxyETHSendRawTransaction{Qtum: qtumRPCClient},
+		&ProxyETHSendRawTransaction{Qtum: qtumRPCClient, ethTransactions: ethTransactions},
 
 		&ETHSubscribe{Qtum: qtumRPCClient, Agent: agent},
 		&ETHUnsubscribe{Qtum: qtumRPCClient, Agent: agent},
@@ -138,6 +171,9 @@ func DefaultProxies(qtumRPCClient *qtum.Qtum, agent *notifier.Agent) []ETHProxy
 		&ProxyQTUMGenerateToAddress{Qtum: qtumRPCClient},
 
 		&ProxyNetPeerCount{Qtum: qtumRPCClient},
+
+		&ProxyDebugTraceTransaction{Qtum: qtumRPCClient},
+		&ProxyDebugTraceCall{ProxyETHCall: ethCall},
 	}
 
 	permittedQtumCalls := []string{
@@ -166,6 +202,22 @@ func SetDebug(debug bool) func(*Transformer) error {
 	}
 }
 
+// SetEthereumTransactions makes the hashes of the Ethereum transactions kept in store usable to look up the Qtum transactions they were sent as
+func SetEthereumTransactions(store *ethtx.Store) func(*Transformer) error {
+	return func(t *Transformer) error {
+		t.ethTransactions = store
+		return nil
+	}
+}
+
+// SetEthereumBlockHashes makes every block hash in requests and responses the hash of the equivalent Ethereum header, as kept in store
+func SetEthereumBlockHashes(store *blockhash.Store) func(*Transformer) error {
+	return func(t *Transformer) error {
+		t.blockHashes = store
+		return nil
+	}
+}
+
 func SetLogger(l log.Logger) func(*Transformer) error {
 	return func(t *Transformer) error {
 		t.logger = log.WithPrefix(l, "component", "transformer")
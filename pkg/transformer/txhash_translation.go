@@ -0,0 +1,53 @@
+package transformer
+
+import (
+	"encoding/json"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/ethtx"
+	"github.com/qtumproject/janus/pkg/utils"
+)
+
+// positional parameters that can be transaction hashes, by method
+var transactionHashParams = map[string]int{
+	"eth_getTransactionByHash":  0,
+	"eth_getTransactionReceipt": 0,
+	"debug_traceTransaction":    0,
+}
+
+// translateRequestTransactionHashes replaces the hash of a signed Ethereum transaction accepted by eth_sendRawTransaction
+// with the hash of the Qtum transaction it was sent as
+func translateRequestTransactionHashes(store *ethtx.Store, req *eth.JSONRPCRequest) *eth.JSONRPCRequest {
+	hashParam, ok := transactionHashParams[req.Method]
+	if !ok {
+		return req
+	}
+
+	var params []json.RawMessage
+	if err := json.Unmarshal(req.Params, &params); err != nil || len(params) <= hashParam {
+		// let the proxy report invalid parameters
+		return req
+	}
+	var hash string
+	if err := json.Unmarshal(params[hashParam], &hash); err != nil || len(utils.RemoveHexPrefix(hash)) != 64 {
+		return req
+	}
+	txid, ok := store.QtumTransaction(common.HexToHash(hash))
+	if !ok {
+		return req
+	}
+
+	translatedHash, err := json.Marshal(utils.AddHexPrefix(txid))
+	if err != nil {
+		return req
+	}
+	params[hashParam] = translatedHash
+	raw, err := json.Marshal(params)
+	if err != nil {
+		return req
+	}
+	translatedReq := *req
+	translatedReq.Params = raw
+	return &translatedReq
+}
@@ -0,0 +1,49 @@
+package transformer
+
+import (
+	"io/ioutil"
+	"os"
+	"testing"
+
+	"github.com/ethereum/go-ethereum/common"
+	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/ethtx"
+)
+
+func TestEthereumTransactionHashesTranslateParams(t *testing.T) {
+	dir, err := ioutil.TempDir("", "ethtx")
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer os.RemoveAll(dir)
+	store, err := ethtx.NewStore(dir)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer store.Close()
+
+	ethereumHash := "0x6d7d56af09383301e1bb32a97d4a5c0661d62302c06a778487d919b7115543be"
+	qtumHash := "0x11e97fa5877c5df349934bafc02da6218038a427e8ed081f048626fa6eb523f5"
+	if err := store.Put(common.HexToHash(ethereumHash), qtumHash); err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		method string
+		params string
+		want   string
+	}{
+		{"eth_getTransactionByHash", `["` + ethereumHash + `"]`, `["` + qtumHash + `"]`},
+		{"eth_getTransactionReceipt", `["` + ethereumHash + `"]`, `["` + qtumHash + `"]`},
+		{"debug_traceTransaction", `["` + ethereumHash + `",{"tracer":"callTracer"}]`, `["` + qtumHash + `",{"tracer":"callTracer"}]`},
+		{"eth_getTransactionReceipt", `["` + qtumHash + `"]`, `["` + qtumHash + `"]`},
+		{"eth_getBlockByHash", `["` + ethereumHash + `",false]`, `["` + ethereumHash + `",false]`},
+	}
+
+	for _, test := range tests {
+		req := &eth.JSONRPCRequest{Method: test.method, Params: []byte(test.params)}
+		if got := translateRequestTransactionHashes(store, req); string(got.Params) != test.want {
+			t.Errorf("%s: expected params %s, got %s", test.method, test.want, got.Params)
+		}
+	}
+}
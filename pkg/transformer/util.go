@@ -7,11 +7,15 @@ import (
 	"encoding/json"
 	"fmt"
 	"math/big"
+	"sort"
 	"strconv"
 	"strings"
+	"sync"
 
 	"github.com/btcsuite/btcutil/base58"
+	"github.com/qtumproject/janus/pkg/conversion"
 	"github.com/qtumproject/janus/pkg/eth"
+	"github.com/qtumproject/janus/pkg/logindex"
 	"github.com/qtumproject/janus/pkg/qtum"
 
 	"github.com/ethereum/go-ethereum/common/hexutil"
@@ -128,6 +132,19 @@ func formatQtumAmount(amount decimal.Decimal) (string, error) {
 	return hexutil.EncodeBig(result), nil
 }
 
+// searchLogs looks up logs in the local log index when it covers the requested block range, otherwise falls back to qtumd's searchlogs
+func searchLogs(ctx context.Context, q *qtum.Qtum, logIndex *logindex.Index, req *qtum.SearchLogsRequest) (qtum.SearchLogsResponse, eth.JSONRPCError) {
+	if logIndex != nil {
+		receipts, err := logIndex.SearchLogs(req)
+		if err == nil {
+			return receipts, nil
+		}
+		q.GetDebugLogger().Log("msg", "falling back to searchlogs", "err", err)
+	}
+
+	return conversion.SearchLogsAndFilterExtraTopics(ctx, q, req)
+}
+
 func unmarshalRequest(data []byte, v interface{}) error {
 	if err := json.Unmarshal(data, v); err != nil {
 		return errors.Wrap(err, "Invalid RPC input")
@@ -140,7 +157,7 @@ func unmarshalRequest(data []byte, v interface{}) error {
 //
 // TODO: Investigate if limitations on Qtum RPC command GetRawTransaction can cause issues here
 // Brief explanation: A default config Qtum node can only serve this command for transactions in the mempool, so it will likely break for SOME setup at SOME point.
-// However the same info can be found with getblock verbosity = 2, so maybe use that instead?
+// Blocks read the spent outputs from getblock verbosity 3 instead, see getBlockTransactionSender
 func getNonContractTxSenderAddress(ctx context.Context, p *qtum.Qtum, tx *qtum.DecodedRawTransactionResponse) (string, error) {
 	// Fetch raw Tx struct, which contains address data for Vins
 	rawTx, err := p.GetRawTransaction(ctx, tx.ID, false)
@@ -284,15 +301,208 @@ func getTransactionIndexInBlock(ctx context.Context, p *qtum.Qtum, txHash string
 	return -1, errors.New("not found")
 }
 
+// formatQtumNonce formats a transaction's nonce as a quantity, which can't have leading zeros
 func formatQtumNonce(nonce int) string {
-	var (
-		hexedNonce     = strconv.FormatInt(int64(nonce), 16)
-		missedCharsNum = 16 - len(hexedNonce)
-	)
-	for i := 0; i < missedCharsNum; i++ {
-		hexedNonce = "0" + hexedNonce
+	return hexutil.EncodeUint64(uint64(nonce))
+}
+
+// getTransactionNonce returns the nonce of a transaction sent by hexAddress, which is the number of transactions the address sent before it.
+// blockNumber and blockHash identify the block the transaction is mined in, both are empty for a pending transaction
+func getTransactionNonce(ctx context.Context, p *qtum.Qtum, hexAddress string, txid string, blockNumber string, blockHash string) (int, error) {
+	txid = utils.RemoveHexPrefix(txid)
+	sent, err := getSentTransactions(ctx, p, hexAddress, blockNumber, blockHash)
+	if err != nil {
+		return 0, err
+	}
+	for i, sentTxid := range sent {
+		if sentTxid == txid {
+			return i, nil
+		}
+	}
+	if blockNumber != "" {
+		return len(sent), nil
+	}
+
+	// pending transactions come after every mined one, in the order they entered the mempool
+	pending, err := getPendingSentTransactions(ctx, p, hexAddress)
+	if err != nil {
+		return 0, err
+	}
+	for i, pendingTxid := range pending {
+		if pendingTxid == txid {
+			return len(sent) + i, nil
+		}
+	}
+	return len(sent) + len(pending), nil
+}
+
+// sentTransactions caches the transactions addresses sent up to a block, which can't change once the block is mined
+var sentTransactions = newBoundedCache(1024)
+
+// boundedCache holds values that don't change once computed, such as data of mined blocks, evicting the oldest past its size
+type boundedCache struct {
+	size   int
+	mutex  sync.Mutex
+	values map[string]interface{}
+	// keys in the order they were added, the oldest is evicted first
+	keys []string
+}
+
+func newBoundedCache(size int) *boundedCache {
+	return &boundedCache{
+		size:   size,
+		values: map[string]interface{}{},
 	}
-	return "0x" + hexedNonce
+}
+
+func (c *boundedCache) get(key string) (interface{}, bool) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	value, ok := c.values[key]
+	return value, ok
+}
+
+func (c *boundedCache) add(key string, value interface{}) {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	if _, ok := c.values[key]; ok {
+		return
+	}
+	if len(c.keys) >= c.size {
+		delete(c.values, c.keys[0])
+		c.keys = c.keys[1:]
+	}
+	c.values[key] = value
+	c.keys = append(c.keys, key)
+}
+
+// reset empties the cache
+func (c *boundedCache) reset() {
+	c.mutex.Lock()
+	defer c.mutex.Unlock()
+	c.values = map[string]interface{}{}
+	c.keys = nil
+}
+
+// getSentTransactions returns the ids of the transactions an address sent up to a block, or up to the tip when blockNumber is empty, in order
+func getSentTransactions(ctx context.Context, p *qtum.Qtum, hexAddress string, blockNumber string, blockHash string) ([]string, error) {
+	sent, err := getSentTransactionsOfAddresses(ctx, p, []string{hexAddress}, blockNumber, blockHash)
+	if err != nil {
+		return nil, err
+	}
+	return sent[hexAddress], nil
+}
+
+// getSentTransactionsOfAddresses returns the ids of the transactions each address sent up to a block, or up to the tip when blockNumber is empty,
+// by the hex addresses passed. The addresses missing from the cache are looked up with a single getaddressdeltas call.
+// Lists up to a mined block are cached by the block's hash, so a reorganized block isn't served from the cache
+func getSentTransactionsOfAddresses(ctx context.Context, p *qtum.Qtum, hexAddresses []string, blockNumber string, blockHash string) (map[string][]string, error) {
+	var height uint64
+	if blockNumber != "" {
+		var err error
+		height, err = hexutil.DecodeUint64(blockNumber)
+		if err != nil {
+			return nil, errors.Wrapf(err, "invalid block number %s", blockNumber)
+		}
+		if height == 0 {
+			// the genesis block's output can't be spent, and getaddressdeltas can't be given an empty range,
+			// without one it would search the whole chain
+			sent := map[string][]string{}
+			for _, hexAddress := range hexAddresses {
+				sent[hexAddress] = []string{}
+			}
+			return sent, nil
+		}
+	}
+	cacheKey := func(address string) string {
+		if blockNumber == "" || blockHash == "" {
+			return ""
+		}
+		return address + "/" + strings.ToLower(utils.RemoveHexPrefix(blockHash))
+	}
+
+	sent := map[string][]string{}
+	// the hex addresses by their base58 address, for the addresses to look up
+	missing := map[string][]string{}
+	for _, hexAddress := range hexAddresses {
+		address, err := convertETHAddress(utils.RemoveHexPrefix(hexAddress), p.Chain())
+		if err != nil {
+			return nil, err
+		}
+		if key := cacheKey(address); key != "" {
+			if cached, ok := sentTransactions.get(key); ok {
+				sent[hexAddress] = cached.([]string)
+				continue
+			}
+		}
+		missing[address] = append(missing[address], hexAddress)
+	}
+	if len(missing) == 0 {
+		return sent, nil
+	}
+
+	req := &qtum.GetAddressDeltasRequest{}
+	for address := range missing {
+		req.Addresses = append(req.Addresses, address)
+	}
+	// the request doesn't depend on the map's order
+	sort.Strings(req.Addresses)
+	if blockNumber != "" {
+		req.Start = 1
+		req.End = int64(height)
+	}
+	deltas, err := p.GetAddressDeltas(ctx, req)
+	if err != nil {
+		return nil, err
+	}
+
+	deltasByAddress := map[string]qtum.GetAddressDeltasResponse{}
+	for _, delta := range deltas {
+		deltasByAddress[delta.Address] = append(deltasByAddress[delta.Address], delta)
+	}
+	for address, addressHexAddresses := range missing {
+		addressSent := deltasByAddress[address].SentTransactions()
+		if key := cacheKey(address); key != "" {
+			sentTransactions.add(key, addressSent)
+		}
+		for _, hexAddress := range addressHexAddresses {
+			sent[hexAddress] = addressSent
+		}
+	}
+	return sent, nil
+}
+
+// getPendingSentTransactions returns the ids of the mempool transactions an address sent, in the order they entered the mempool
+func getPendingSentTransactions(ctx context.Context, p *qtum.Qtum, hexAddress string) ([]string, error) {
+	address, err := convertETHAddress(utils.RemoveHexPrefix(hexAddress), p.Chain())
+	if err != nil {
+		return nil, err
+	}
+	mempool, err := p.GetAddressMempool(ctx, &qtum.GetAddressMempoolRequest{Addresses: []string{address}})
+	if err != nil {
+		return nil, err
+	}
+
+	spends := []qtum.AddressMempoolDelta{}
+	for _, delta := range mempool {
+		if delta.Satoshis < 0 {
+			spends = append(spends, delta)
+		}
+	}
+	sort.SliceStable(spends, func(i, j int) bool {
+		return spends[i].Timestamp < spends[j].Timestamp
+	})
+
+	txids := []string{}
+	seen := map[string]bool{}
+	for _, spend := range spends {
+		// a transaction spending several outputs has a delta for each of them
+		if !seen[spend.TXID] {
+			seen[spend.TXID] = true
+			txids = append(txids, spend.TXID)
+		}
+	}
+	return txids, nil
 }
 
 // Returns Qtum block number. Result depends on a passed raw param. Raw param's slice of bytes should
@@ -366,6 +576,76 @@ func getBlockNumberByParam(ctx context.Context, p *qtum.Qtum, param string, defa
 	}
 }
 
+// getStateBlockNumber resolves the block parameter of methods reading state (eth_getBalance, eth_call, eth_getCode),
+// which is a tag, a hex block number or an EIP-1898 object with a block number or hash.
+// nil is returned for the state of the latest block, which qtumd reads without being given a height
+func getStateBlockNumber(ctx context.Context, p *qtum.Qtum, rawParam json.RawMessage) (*big.Int, eth.JSONRPCError) {
+	if len(rawParam) == 0 || string(rawParam) == "null" {
+		return nil, nil
+	}
+
+	var param string
+	if isBytesOfString(rawParam) {
+		param = string(rawParam[1 : len(rawParam)-1]) // trim \" runes
+	} else if bytes.HasPrefix(bytes.TrimSpace(rawParam), []byte{'{'}) {
+		var blockNumberOrHash eth.BlockNumberOrHash
+		if err := json.Unmarshal(rawParam, &blockNumberOrHash); err != nil {
+			return nil, eth.NewInvalidParamsError("invalid block parameter: " + err.Error())
+		}
+		if blockNumberOrHash.BlockHash != "" {
+			if blockNumberOrHash.BlockNumber != "" {
+				return nil, eth.NewInvalidParamsError("invalid block parameter: cannot specify both blockHash and blockNumber")
+			}
+			return getStateBlockNumberByHash(ctx, p, blockNumberOrHash.BlockHash)
+		}
+		if blockNumberOrHash.BlockNumber == "" {
+			return nil, eth.NewInvalidParamsError("invalid block parameter: blockHash or blockNumber is expected")
+		}
+		param = blockNumberOrHash.BlockNumber
+	} else {
+		return nil, eth.NewInvalidParamsError("invalid block parameter: string or object is expected")
+	}
+
+	switch param {
+	case "", "latest", "pending":
+		// qtumd's state only includes mined transactions, the pending state is the latest one
+		return nil, nil
+	}
+
+	blockNumber, jsonErr := getBlockNumberByParam(ctx, p, param, false)
+	if jsonErr != nil {
+		return nil, jsonErr
+	}
+
+	latestBlockNumber, err := p.GetBlockCount(ctx)
+	if err != nil {
+		return nil, eth.NewCallbackError(err.Error())
+	}
+	if blockNumber.Cmp(latestBlockNumber.Int) > 0 {
+		return nil, eth.NewCallbackError("header not found")
+	}
+	if blockNumber.Cmp(latestBlockNumber.Int) == 0 {
+		return nil, nil
+	}
+	return blockNumber, nil
+}
+
+func getStateBlockNumberByHash(ctx context.Context, p *qtum.Qtum, blockHash string) (*big.Int, eth.JSONRPCError) {
+	header, err := p.GetBlockHeader(ctx, utils.RemoveHexPrefix(blockHash))
+	if err != nil {
+		p.GetDebugLogger().Log("function", "getStateBlockNumberByHash", "msg", "couldn't get block header", "hash", blockHash, "error", err)
+		return nil, eth.NewCallbackError(fmt.Sprintf("header for hash %s not found", blockHash))
+	}
+	// qtumd reads state by height, which only identifies blocks of the main chain
+	if header.Confirmations < 0 {
+		return nil, eth.NewCallbackError(fmt.Sprintf("hash %s is not currently canonical", blockHash))
+	}
+	if header.Confirmations == 1 {
+		return nil, nil
+	}
+	return big.NewInt(int64(header.Height)), nil
+}
+
 func isBytesOfString(v json.RawMessage) bool {
 	dQuote := []byte{'"'}
 	if !bytes.HasPrefix(v, dQuote) && !bytes.HasSuffix(v, dQuote) {
@@ -450,3 +730,18 @@ func convertFromQtumToSatoshis(inQtum decimal.Decimal) decimal.Decimal {
 func convertFromSatoshiToWei(inSatoshis *big.Int) *big.Int {
 	return inSatoshis.Mul(inSatoshis, big.NewInt(1e10))
 }
+
+// blockBaseFeePerGas returns the EIP-1559 base fee of every block in wei.
+// Qtum has no fee market, transactions have to pay at least the minimum gas price of 40 satoshi
+func blockBaseFeePerGas() *big.Int {
+	return convertFromSatoshiToWei(convertFromQtumToSatoshis(MinimumGas).BigInt())
+}
+
+// contractGasPriceInWei converts the gas price of an OP_CALL or OP_CREATE script to wei
+func contractGasPriceInWei(info qtum.ContractInfo) (*big.Int, error) {
+	gasPriceInSatoshis, err := info.GasPriceInSatoshis()
+	if err != nil {
+		return nil, err
+	}
+	return convertFromSatoshiToWei(gasPriceInSatoshis), nil
+}
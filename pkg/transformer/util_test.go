@@ -171,3 +171,57 @@ func TestSendTransactionRequestHasDefaultGasPriceAndAmount(t *testing.T) {
 		t.Fatalf("Default gas amount does not match expected default, got: %s want: %s", req.Gas.Int.String(), eth.DefaultGasAmountForQtum.String())
 	}
 }
+
+func TestGetTransactionNonce(t *testing.T) {
+	resetCaches()
+	mockedClientDoer := internal.NewDoerMappedMock()
+	qtumClient, err := internal.CreateMockedClient(mockedClientDoer)
+	require.NoError(t, err)
+
+	address := "0x6d358cf96533189dd5a602d0937fddf0888ad3ae"
+	qtumAddress, err := convertETHAddress(utils.RemoveHexPrefix(address), qtumClient.Chain())
+	require.NoError(t, err)
+
+	// deltas aren't necessarily sorted the way the transactions were mined
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressDeltas, qtum.GetAddressDeltasResponse{
+		{TXID: "c", Satoshis: -1, Height: 20, BlockIndex: 1, Address: qtumAddress},
+		{TXID: "a", Satoshis: -1, Height: 10, BlockIndex: 2, Address: qtumAddress},
+		{TXID: "b", Satoshis: -1, Height: 20, BlockIndex: 0, Address: qtumAddress},
+		{TXID: "a", Satoshis: 1, Height: 10, BlockIndex: 2, Address: qtumAddress},
+	})
+	require.NoError(t, err)
+
+	ctx := internal.NewEchoContext().Request().Context()
+	for txid, want := range map[string]int{"a": 0, "b": 1, "c": 2} {
+		nonce, err := getTransactionNonce(ctx, qtumClient, address, txid, "0x14", "0xnonceblock20")
+		require.NoError(t, err)
+		require.Equal(t, want, nonce, "nonce of %s", txid)
+	}
+
+	// the transactions sent up to a mined block are cached by the block's hash
+	sent, ok := sentTransactions.get(qtumAddress + "/nonceblock20")
+	require.True(t, ok)
+	require.Equal(t, []string{"a", "b", "c"}, sent)
+
+	// pending transactions are numbered in the order they entered the mempool
+	err = mockedClientDoer.AddResponse(qtum.MethodGetAddressMempool, qtum.GetAddressMempoolResponse{
+		{TXID: "g", Satoshis: -1, Timestamp: 300},
+		{TXID: "e", Satoshis: -1, Timestamp: 100},
+		{TXID: "f", Satoshis: -1, Timestamp: 200},
+		{TXID: "f", Satoshis: -1, Timestamp: 200, Index: 1},
+		{TXID: "e", Satoshis: 1, Timestamp: 100},
+	})
+	require.NoError(t, err)
+	for txid, want := range map[string]int{"e": 3, "f": 4, "g": 5} {
+		nonce, err := getTransactionNonce(ctx, qtumClient, address, txid, "", "")
+		require.NoError(t, err)
+		require.Equal(t, want, nonce, "nonce of %s", txid)
+	}
+	require.Equal(t, "0x3", formatQtumNonce(3))
+
+	// nothing is sent up to the genesis block, getaddressdeltas would search the whole chain without a range
+	delete(mockedClientDoer.Responses, qtum.MethodGetAddressDeltas)
+	sentAtGenesis, err := getSentTransactions(ctx, qtumClient, address, "0x0", "0xgenesis")
+	require.NoError(t, err)
+	require.Empty(t, sentAtGenesis)
+}